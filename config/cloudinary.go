@@ -3,12 +3,14 @@ package config
 import (
 	"bytes"
 	"crypto/sha1"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"sort"
 	"strconv"
@@ -31,6 +33,69 @@ type CloudinaryErrorResponse struct {
 	} `json:"error"`
 }
 
+// Tipe pengiriman Cloudinary: "upload" dapat diakses siapa pun yang tahu URL-nya,
+// "authenticated" hanya lewat URL bertanda tangan
+const (
+	cloudinaryPublicType = "upload"
+	cloudinaryAuthType   = "authenticated"
+)
+
+// cloudinaryDeliveryType — hanya foto profil yang tetap publik; file dokumen disimpan
+// sebagai aset authenticated agar wajib diunduh lewat endpoint yang memeriksa izin
+func cloudinaryDeliveryType(publicID string) string {
+	if strings.HasPrefix(publicID, PublicStorageFolder+"/") {
+		return cloudinaryPublicType
+	}
+	return cloudinaryAuthType
+}
+
+// cloudinarySign — tanda tangan API: parameter diurutkan, digabung "&", ditambah secret lalu SHA1
+func cloudinarySign(params map[string]string, apiSecret string) string {
+	pairs := make([]string, 0, len(params))
+	for key, value := range params {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+
+	h := sha1.New()
+	h.Write([]byte(strings.Join(pairs, "&") + apiSecret))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cloudinaryEscapePath — escape tiap segmen public_id tanpa mengubah pemisah folder
+func cloudinaryEscapePath(publicID string) string {
+	parts := strings.Split(publicID, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// CloudinaryDeliveryURL — URL unduhan aset. Aset authenticated diberi komponen
+// tanda tangan "s--xxxxxxxx--" (SHA1 public_id + secret) sehingga URL tidak bisa ditebak
+func CloudinaryDeliveryURL(publicID, resourceType string) (string, error) {
+	cloudName := os.Getenv("CLOUDINARY_CLOUD_NAME")
+	apiSecret := os.Getenv("CLOUDINARY_API_SECRET")
+	if cloudName == "" || apiSecret == "" {
+		return "", fmt.Errorf("cloudinary credentials tidak lengkap")
+	}
+
+	if resourceType == "" {
+		resourceType = "raw"
+	}
+
+	deliveryType := cloudinaryDeliveryType(publicID)
+	if deliveryType == cloudinaryPublicType {
+		return fmt.Sprintf("https://res.cloudinary.com/%s/%s/%s/%s", cloudName, resourceType, deliveryType, cloudinaryEscapePath(publicID)), nil
+	}
+
+	h := sha1.New()
+	h.Write([]byte(publicID + apiSecret))
+	signature := base64.URLEncoding.EncodeToString(h.Sum(nil))[:8]
+
+	return fmt.Sprintf("https://res.cloudinary.com/%s/%s/%s/s--%s--/%s", cloudName, resourceType, deliveryType, signature, cloudinaryEscapePath(publicID)), nil
+}
+
 // UploadToCloudinary — upload file ke Cloudinary menggunakan Signed Upload
 func UploadToCloudinary(file io.Reader, fileName, folder, resourceType string) (CloudinaryResponse, error) {
 	cloudName := os.Getenv("CLOUDINARY_CLOUD_NAME")
@@ -46,6 +111,7 @@ func UploadToCloudinary(file io.Reader, fileName, folder, resourceType string) (
 	url := fmt.Sprintf("https://api.cloudinary.com/v1_1/%s/%s/upload", cloudName, resourceType)
 
 	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	deliveryType := cloudinaryDeliveryType(folder + "/")
 
 	var signatureString string
 	params := []string{}
@@ -55,6 +121,7 @@ func UploadToCloudinary(file io.Reader, fileName, folder, resourceType string) (
 	}
 
 	params = append(params, "timestamp="+timestamp)
+	params = append(params, "type="+deliveryType)
 	params = append(params, "unique_filename=false")
 	params = append(params, "use_filename=true")
 
@@ -80,6 +147,7 @@ func UploadToCloudinary(file io.Reader, fileName, folder, resourceType string) (
 	writer.WriteField("api_key", apiKey)
 	writer.WriteField("timestamp", timestamp)
 	writer.WriteField("signature", signature)
+	writer.WriteField("type", deliveryType)
 
 	writer.WriteField("use_filename", "true")
 	writer.WriteField("unique_filename", "false")
@@ -129,11 +197,16 @@ func UploadToCloudinary(file io.Reader, fileName, folder, resourceType string) (
 }
 
 func CloudinaryFileExists(publicID, resourceType string) bool {
+	return cloudinaryResourceExists(publicID, resourceType, cloudinaryDeliveryType(publicID))
+}
+
+// cloudinaryResourceExists — cek lewat Admin API untuk tipe pengiriman tertentu
+func cloudinaryResourceExists(publicID, resourceType, deliveryType string) bool {
 	cloudName := os.Getenv("CLOUDINARY_CLOUD_NAME")
 	apiKey := os.Getenv("CLOUDINARY_API_KEY")
 	apiSecret := os.Getenv("CLOUDINARY_API_SECRET")
 
-	url := fmt.Sprintf("https://api.cloudinary.com/v1_1/%s/%s/upload/%s", cloudName, resourceType, publicID)
+	url := fmt.Sprintf("https://api.cloudinary.com/v1_1/%s/resources/%s/%s/%s", cloudName, resourceType, deliveryType, cloudinaryEscapePath(publicID))
 
 	req, _ := http.NewRequest("GET", url, nil)
 	req.SetBasicAuth(apiKey, apiSecret)
@@ -190,7 +263,9 @@ func DeleteFromCloudinary(publicID string, resourceType string) error {
 
 	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
 
-	signatureString := fmt.Sprintf("public_id=%s&timestamp=%s%s", publicID, timestamp, apiSecret)
+	deliveryType := cloudinaryDeliveryType(publicID)
+
+	signatureString := fmt.Sprintf("public_id=%s&timestamp=%s&type=%s%s", publicID, timestamp, deliveryType, apiSecret)
 	h := sha1.New()
 	h.Write([]byte(signatureString))
 	signature := hex.EncodeToString(h.Sum(nil))
@@ -198,6 +273,7 @@ func DeleteFromCloudinary(publicID string, resourceType string) error {
 	var body bytes.Buffer
 	writer := multipart.NewWriter(&body)
 	writer.WriteField("public_id", publicID)
+	writer.WriteField("type", deliveryType)
 	writer.WriteField("api_key", apiKey)
 	writer.WriteField("timestamp", timestamp)
 	writer.WriteField("signature", signature)
@@ -243,3 +319,136 @@ func DeleteFromCloudinary(publicID string, resourceType string) error {
 
 	return fmt.Errorf("delete failed with result: %s", result.Result)
 }
+
+// CloudinaryStorage — implementasi Storage di atas fungsi Cloudinary yang sudah ada
+type CloudinaryStorage struct{}
+
+func (s *CloudinaryStorage) Upload(file io.Reader, fileName, folder, resourceType string) (UploadResult, error) {
+	res, err := UploadToCloudinary(file, fileName, folder, resourceType)
+	if err != nil {
+		return UploadResult{}, err
+	}
+
+	return UploadResult{
+		PublicID:     res.PublicID,
+		URL:          res.SecureURL,
+		ResourceType: res.ResourceType,
+	}, nil
+}
+
+func (s *CloudinaryStorage) Delete(publicID, resourceType string) error {
+	return DeleteFromCloudinary(publicID, resourceType)
+}
+
+func (s *CloudinaryStorage) Exists(publicID, resourceType string) bool {
+	return CloudinaryFileExists(publicID, resourceType)
+}
+
+func (s *CloudinaryStorage) Open(publicID, resourceType string) (io.ReadCloser, error) {
+	resp, err := cloudinaryFetch(publicID, resourceType, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != 200 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("cloudinary fetch failed with status %d", resp.StatusCode)
+	}
+
+	return resp.Body, nil
+}
+
+func (s *CloudinaryStorage) OpenRange(publicID, resourceType, rangeHeader string) (RangeResult, error) {
+	resp, err := cloudinaryFetch(publicID, resourceType, rangeHeader)
+	if err != nil {
+		return RangeResult{}, err
+	}
+	return rangeResponse(resp)
+}
+
+// cloudinaryFetch — GET lewat URL pengiriman (bertanda tangan bila authenticated)
+func cloudinaryFetch(publicID, resourceType, rangeHeader string) (*http.Response, error) {
+	url, err := CloudinaryDeliveryURL(publicID, resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	if rangeHeader != "" {
+		req.Header.Set("Range", rangeHeader)
+	}
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to Cloudinary failed: %v", err)
+	}
+	return resp, nil
+}
+
+// RestrictLegacyAsset — pindahkan aset dokumen lama dari tipe "upload" (publik) ke
+// "authenticated" lewat Rename API. invalidate=true membersihkan cache CDN sehingga URL
+// publik lama berhenti berfungsi. Aset yang sudah dipindah sebelumnya dianggap berhasil.
+func (s *CloudinaryStorage) RestrictLegacyAsset(publicID, resourceType string) (string, error) {
+	cloudName := os.Getenv("CLOUDINARY_CLOUD_NAME")
+	apiKey := os.Getenv("CLOUDINARY_API_KEY")
+	apiSecret := os.Getenv("CLOUDINARY_API_SECRET")
+
+	if cloudName == "" || apiKey == "" || apiSecret == "" {
+		return "", fmt.Errorf("cloudinary credentials tidak lengkap")
+	}
+
+	if resourceType == "" {
+		resourceType = "raw"
+	}
+
+	if cloudinaryDeliveryType(publicID) == cloudinaryPublicType {
+		return "", fmt.Errorf("aset %s berada di folder publik", publicID)
+	}
+
+	params := map[string]string{
+		"from_public_id": publicID,
+		"to_public_id":   publicID,
+		"type":           cloudinaryPublicType,
+		"to_type":        cloudinaryAuthType,
+		"invalidate":     "true",
+		"timestamp":      strconv.FormatInt(time.Now().Unix(), 10),
+	}
+	signature := cloudinarySign(params, apiSecret)
+
+	form := url.Values{}
+	for key, value := range params {
+		form.Set(key, value)
+	}
+	form.Set("api_key", apiKey)
+	form.Set("signature", signature)
+
+	endpoint := fmt.Sprintf("https://api.cloudinary.com/v1_1/%s/%s/rename", cloudName, resourceType)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.PostForm(endpoint, form)
+	if err != nil {
+		return "", fmt.Errorf("request to Cloudinary rename failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != 200 {
+		// migrasi sebelumnya bisa terhenti setelah rename namun sebelum URL di database diganti
+		if resp.StatusCode == http.StatusNotFound && cloudinaryResourceExists(publicID, resourceType, cloudinaryAuthType) {
+			return CloudinaryDeliveryURL(publicID, resourceType)
+		}
+
+		var errResp CloudinaryErrorResponse
+		if json.Unmarshal(respBody, &errResp) == nil && errResp.Error.Message != "" {
+			return "", fmt.Errorf("cloudinary rename error: %s", errResp.Error.Message)
+		}
+		return "", fmt.Errorf("rename failed: %s", string(respBody))
+	}
+
+	return CloudinaryDeliveryURL(publicID, resourceType)
+}
@@ -22,6 +22,20 @@ var forbiddenPatterns = []*regexp.Regexp{
 	regexp.MustCompile(`(?i)<script>`),              // XSS dalam query
 }
 
+// key setting gorm untuk menandai sesi migrasi skema (AutoMigrate) yang tepercaya;
+// DDL seperti ALTER TABLE dan ON DELETE CASCADE sah di sini
+const trustedMigrationKey = "security:trusted_migration"
+
+// Migrate — jalankan AutoMigrate tanpa diblokir proteksi raw SQL
+func Migrate(models ...interface{}) error {
+	return DB.Set(trustedMigrationKey, true).AutoMigrate(models...)
+}
+
+func isTrustedMigration(db *gorm.DB) bool {
+	trusted, ok := db.Get(trustedMigrationKey)
+	return ok && trusted == true
+}
+
 // cek jika query raw mengandung pola terlarang
 func isQueryDangerous(sql string) bool {
 	clean := strings.TrimSpace(sql)
@@ -39,7 +53,7 @@ func registerQueryProtector(db *gorm.DB) {
 
 	// semua Raw SQL dan Exec SQL akan lewat sini
 	callback.Raw().Before("gorm:raw").Register("security:check_raw_sql", func(db *gorm.DB) {
-		if db.Statement.SQL.String() != "" {
+		if db.Statement.SQL.String() != "" && !isTrustedMigration(db) {
 			sql := db.Statement.SQL.String()
 
 			if isQueryDangerous(sql) {
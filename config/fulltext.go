@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// FullTextIndex — indeks FULLTEXT MySQL yang dibutuhkan fitur pencarian.
+// Kolom harus sama persis dengan kolom pada MATCH(...) di services/search_service.go.
+type FullTextIndex struct {
+	Table   string
+	Name    string
+	Columns []string
+}
+
+var FullTextIndexes = []FullTextIndex{
+	{
+		Table:   "documents",
+		Name:    "ft_documents_search",
+		Columns: []string{"subject", "sender", "file_name", "letter_number", "recipient", "extracted_text"},
+	},
+	{
+		Table:   "document_staffs",
+		Name:    "ft_document_staffs_search",
+		Columns: []string{"subject", "file_name", "extracted_text"},
+	},
+}
+
+// EnsureFullTextIndexes — buat indeks FULLTEXT yang belum ada (AutoMigrate tidak mengurusnya).
+// Indeks yang kolomnya sudah berubah dibuat ulang.
+func EnsureFullTextIndexes() error {
+	tx := DB.Set(trustedMigrationKey, true)
+	migrator := tx.Migrator()
+
+	for _, idx := range FullTextIndexes {
+		if migrator.HasIndex(idx.Table, idx.Name) {
+			current, err := indexColumns(tx, idx.Table, idx.Name)
+			if err != nil {
+				return err
+			}
+			if strings.Join(current, ",") == strings.Join(idx.Columns, ",") {
+				continue
+			}
+
+			if err := migrator.DropIndex(idx.Table, idx.Name); err != nil {
+				return fmt.Errorf("gagal menghapus indeks lama %s: %v", idx.Name, err)
+			}
+		}
+
+		sql := fmt.Sprintf(
+			"CREATE FULLTEXT INDEX `%s` ON `%s` (`%s`)",
+			idx.Name, idx.Table, strings.Join(idx.Columns, "`, `"),
+		)
+		if err := tx.Exec(sql).Error; err != nil {
+			return fmt.Errorf("gagal membuat indeks %s: %v", idx.Name, err)
+		}
+
+		log.Println("✅ Indeks FULLTEXT dibuat:", idx.Name)
+	}
+
+	return nil
+}
+
+func indexColumns(tx *gorm.DB, table, name string) ([]string, error) {
+	indexes, err := tx.Migrator().GetIndexes(table)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca indeks %s: %v", table, err)
+	}
+	for _, index := range indexes {
+		if index.Name() == name {
+			return index.Columns(), nil
+		}
+	}
+	return nil, nil
+}
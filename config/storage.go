@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UploadResult — hasil upload yang seragam untuk semua backend penyimpanan
+type UploadResult struct {
+	PublicID     string `json:"public_id"`
+	URL          string `json:"url"`
+	ResourceType string `json:"resource_type"`
+}
+
+// Storage — abstraksi penyimpanan file (Cloudinary, lokal, S3/MinIO)
+type Storage interface {
+	Upload(file io.Reader, fileName, folder, resourceType string) (UploadResult, error)
+	Delete(publicID, resourceType string) error
+	Exists(publicID, resourceType string) bool
+	Open(publicID, resourceType string) (io.ReadCloser, error)
+}
+
+// RangeResult — potongan file dari backend remote untuk request ber-Range
+type RangeResult struct {
+	Body          io.ReadCloser
+	Status        int // 206, 416, atau 200 bila backend mengabaikan Range
+	ContentRange  string
+	ContentLength int64 // -1 bila tidak diketahui
+}
+
+// RangeOpener — backend remote yang dapat meneruskan header Range klien ke sumbernya,
+// sehingga seek di PDF besar tidak perlu mengambil seluruh file
+type RangeOpener interface {
+	OpenRange(publicID, resourceType, rangeHeader string) (RangeResult, error)
+}
+
+// rangeResponse — terima 200/206/416 dari GET ber-Range; status lain dianggap gagal
+func rangeResponse(resp *http.Response) (RangeResult, error) {
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent, http.StatusRequestedRangeNotSatisfiable:
+		return RangeResult{
+			Body:          resp.Body,
+			Status:        resp.StatusCode,
+			ContentRange:  resp.Header.Get("Content-Range"),
+			ContentLength: resp.ContentLength,
+		}, nil
+	}
+	resp.Body.Close()
+	return RangeResult{}, fmt.Errorf("range fetch failed with status %d", resp.StatusCode)
+}
+
+// LegacyAssetRestrictor — backend yang dulu menyimpan file dokumen sebagai aset publik
+// dan dapat memindahkannya ke akses terbatas. Mengembalikan URL baru aset tersebut.
+type LegacyAssetRestrictor interface {
+	RestrictLegacyAsset(publicID, resourceType string) (string, error)
+}
+
+// PublicStorageFolder — satu-satunya folder yang isinya boleh diakses publik (foto profil)
+const PublicStorageFolder = "users"
+
+var FileStorage Storage
+
+// InitStorage — pilih backend berdasarkan STORAGE_DRIVER (cloudinary | local | s3)
+func InitStorage() {
+	driver := strings.ToLower(os.Getenv("STORAGE_DRIVER"))
+
+	switch driver {
+	case "local":
+		FileStorage = NewLocalStorage()
+	case "s3", "minio":
+		s3, err := NewS3Storage()
+		if err != nil {
+			log.Fatal("❌ Konfigurasi S3 tidak valid: ", err)
+		}
+		FileStorage = s3
+	case "", "cloudinary":
+		driver = "cloudinary"
+		FileStorage = &CloudinaryStorage{}
+	default:
+		log.Fatal("❌ STORAGE_DRIVER tidak dikenal: ", driver)
+	}
+
+	log.Println("✅ Storage driver:", driver)
+}
+
+// buildObjectName — nama file unik di dalam folder, dipakai backend non-Cloudinary
+func buildObjectName(folder, originalName string) string {
+	ext := ""
+	name := originalName
+
+	if dot := strings.LastIndex(originalName, "."); dot != -1 {
+		ext = originalName[dot:]
+		name = originalName[:dot]
+	}
+
+	name = sanitizeObjectPart(name)
+	ext = "." + sanitizeObjectPart(strings.TrimPrefix(ext, "."))
+	if ext == "." {
+		ext = ""
+	}
+
+	uniqueName := fmt.Sprintf("%s_%d_%s%s", name, time.Now().UnixNano(), uuid.New().String()[:8], ext)
+	if folder == "" {
+		return uniqueName
+	}
+	return folder + "/" + uniqueName
+}
+
+// sanitizeObjectPart — hanya huruf, angka, '-' dan '_' yang boleh masuk ke key
+func sanitizeObjectPart(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage — menyimpan file di filesystem server sendiri
+type LocalStorage struct {
+	BaseDir string
+	BaseURL string
+	Route   string // path URL tempat server menyajikan file, diturunkan dari BaseURL
+}
+
+// NewLocalStorage — LOCAL_STORAGE_PATH (default ./uploads) dan LOCAL_STORAGE_URL (default /files).
+// LOCAL_STORAGE_URL boleh berupa URL absolut (mis. di belakang reverse proxy); route statis
+// memakai bagian path-nya sehingga URL yang disimpan selalu dapat diakses.
+func NewLocalStorage() *LocalStorage {
+	baseDir := os.Getenv("LOCAL_STORAGE_PATH")
+	if baseDir == "" {
+		baseDir = "./uploads"
+	}
+
+	baseURL := os.Getenv("LOCAL_STORAGE_URL")
+	if baseURL == "" {
+		baseURL = LocalStorageRoute
+	}
+
+	baseURL, route := localStorageRoute(strings.TrimRight(baseURL, "/"))
+
+	return &LocalStorage{
+		BaseDir: baseDir,
+		BaseURL: baseURL,
+		Route:   route,
+	}
+}
+
+// LocalStorageRoute — prefix route default tempat file lokal disajikan
+const LocalStorageRoute = "/files"
+
+// localStorageRoute — path route dari base URL. Base URL tanpa path (mis. "https://files.example.com")
+// diberi prefix default agar file tidak bertabrakan dengan route API.
+func localStorageRoute(baseURL string) (string, string) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return LocalStorageRoute, LocalStorageRoute
+	}
+
+	route := "/" + strings.Trim(parsed.Path, "/")
+	if route == "/" {
+		return baseURL + LocalStorageRoute, LocalStorageRoute
+	}
+	return baseURL, route
+}
+
+// path — ubah publicID menjadi path absolut, tolak path traversal
+func (s *LocalStorage) path(publicID string) (string, error) {
+	clean := filepath.Clean("/" + publicID)
+	if clean == "/" {
+		return "", fmt.Errorf("public id kosong")
+	}
+
+	return filepath.Join(s.BaseDir, filepath.FromSlash(clean)), nil
+}
+
+func (s *LocalStorage) Upload(file io.Reader, fileName, folder, resourceType string) (UploadResult, error) {
+	publicID := buildObjectName(folder, fileName)
+
+	fullPath, err := s.path(publicID)
+	if err != nil {
+		return UploadResult{}, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return UploadResult{}, fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	dst, err := os.OpenFile(fullPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return UploadResult{}, fmt.Errorf("failed to create file: %v", err)
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, file); err != nil {
+		os.Remove(fullPath)
+		return UploadResult{}, fmt.Errorf("failed to copy file: %v", err)
+	}
+
+	fmt.Printf("✅ Local upload success: %s\n", fullPath)
+
+	return UploadResult{
+		PublicID:     publicID,
+		URL:          s.BaseURL + "/" + publicID,
+		ResourceType: resourceType,
+	}, nil
+}
+
+func (s *LocalStorage) Delete(publicID, resourceType string) error {
+	fullPath, err := s.path(publicID)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("delete failed: %v", err)
+	}
+
+	fmt.Printf("✅ Local delete success (or file not found): %s\n", publicID)
+	return nil
+}
+
+func (s *LocalStorage) Exists(publicID, resourceType string) bool {
+	fullPath, err := s.path(publicID)
+	if err != nil {
+		return false
+	}
+
+	info, err := os.Stat(fullPath)
+	return err == nil && !info.IsDir()
+}
+
+func (s *LocalStorage) Open(publicID, resourceType string) (io.ReadCloser, error) {
+	fullPath, err := s.path(publicID)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(fullPath)
+}
@@ -0,0 +1,298 @@
+package config
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Storage — backend S3-compatible (AWS S3 / MinIO) dengan path-style URL
+// dan signature AWS V4, tanpa SDK tambahan
+type S3Storage struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PublicURL string
+
+	client *http.Client
+}
+
+// NewS3Storage — baca konfigurasi S3_ENDPOINT, S3_REGION, S3_BUCKET,
+// S3_ACCESS_KEY, S3_SECRET_KEY dan S3_PUBLIC_URL (opsional)
+func NewS3Storage() (*S3Storage, error) {
+	s := &S3Storage{
+		Endpoint:  strings.TrimRight(os.Getenv("S3_ENDPOINT"), "/"),
+		Region:    os.Getenv("S3_REGION"),
+		Bucket:    os.Getenv("S3_BUCKET"),
+		AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_SECRET_KEY"),
+		PublicURL: strings.TrimRight(os.Getenv("S3_PUBLIC_URL"), "/"),
+		client:    &http.Client{Timeout: 60 * time.Second},
+	}
+
+	if s.Endpoint == "" || s.Bucket == "" || s.AccessKey == "" || s.SecretKey == "" {
+		return nil, fmt.Errorf("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY dan S3_SECRET_KEY wajib diisi")
+	}
+
+	if _, err := url.Parse(s.Endpoint); err != nil {
+		return nil, fmt.Errorf("S3_ENDPOINT tidak valid: %v", err)
+	}
+
+	if s.Region == "" {
+		s.Region = "us-east-1"
+	}
+
+	if s.PublicURL == "" {
+		s.PublicURL = s.Endpoint + "/" + s.Bucket
+	}
+
+	return s, nil
+}
+
+func (s *S3Storage) objectURL(key string) string {
+	return s.Endpoint + "/" + s.Bucket + "/" + s3EscapePath(key)
+}
+
+// s3UnsignedPayload — isi PUT tidak ikut di-hash agar file dapat dialirkan tanpa ditampung di memori
+const s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// uploadBody — ukuran isi yang tersisa dari reader. S3 membutuhkan Content-Length, jadi
+// reader yang tidak bisa di-seek ditampung dulu ke file sementara (disk, bukan memori).
+// cleanup wajib dipanggil setelah request selesai.
+func uploadBody(file io.Reader) (body io.Reader, size int64, cleanup func(), err error) {
+	cleanup = func() {}
+
+	if seeker, ok := file.(io.Seeker); ok {
+		current, err := seeker.Seek(0, io.SeekCurrent)
+		if err == nil {
+			end, err := seeker.Seek(0, io.SeekEnd)
+			if err == nil {
+				if _, err := seeker.Seek(current, io.SeekStart); err == nil {
+					return file, end - current, cleanup, nil
+				}
+			}
+		}
+	}
+
+	spool, err := os.CreateTemp("", "s3-upload-*")
+	if err != nil {
+		return nil, 0, cleanup, err
+	}
+	cleanup = func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}
+
+	if size, err = io.Copy(spool, file); err != nil {
+		cleanup()
+		return nil, 0, func() {}, err
+	}
+	if _, err = spool.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, 0, func() {}, err
+	}
+	return spool, size, cleanup, nil
+}
+
+func (s *S3Storage) Upload(file io.Reader, fileName, folder, resourceType string) (UploadResult, error) {
+	body, size, cleanup, err := uploadBody(file)
+	if err != nil {
+		return UploadResult{}, fmt.Errorf("failed to read file: %v", err)
+	}
+	defer cleanup()
+
+	key := buildObjectName(folder, fileName)
+
+	req, err := http.NewRequest("PUT", s.objectURL(key), body)
+	if err != nil {
+		return UploadResult{}, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+
+	contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(fileName)))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.do(req, s3UnsignedPayload)
+	if err != nil {
+		return UploadResult{}, fmt.Errorf("request to S3 failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return UploadResult{}, fmt.Errorf("upload failed: %s", string(respBody))
+	}
+
+	fmt.Printf("✅ S3 upload success: %s\n", key)
+
+	return UploadResult{
+		PublicID:     key,
+		URL:          s.PublicURL + "/" + s3EscapePath(key),
+		ResourceType: resourceType,
+	}, nil
+}
+
+func (s *S3Storage) Delete(publicID, resourceType string) error {
+	req, err := http.NewRequest("DELETE", s.objectURL(publicID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create delete request: %v", err)
+	}
+
+	resp, err := s.do(req, sha256Hex(nil))
+	if err != nil {
+		return fmt.Errorf("request to S3 delete failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 204 && resp.StatusCode != 200 && resp.StatusCode != 404 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("delete failed: %s", string(respBody))
+	}
+
+	fmt.Printf("✅ S3 delete success (or file not found): %s\n", publicID)
+	return nil
+}
+
+func (s *S3Storage) Exists(publicID, resourceType string) bool {
+	req, err := http.NewRequest("HEAD", s.objectURL(publicID), nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := s.do(req, sha256Hex(nil))
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode == 200
+}
+
+func (s *S3Storage) Open(publicID, resourceType string) (io.ReadCloser, error) {
+	req, err := http.NewRequest("GET", s.objectURL(publicID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := s.do(req, sha256Hex(nil))
+	if err != nil {
+		return nil, fmt.Errorf("request to S3 failed: %v", err)
+	}
+
+	if resp.StatusCode != 200 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("s3 fetch failed with status %d", resp.StatusCode)
+	}
+
+	return resp.Body, nil
+}
+
+func (s *S3Storage) OpenRange(publicID, resourceType, rangeHeader string) (RangeResult, error) {
+	req, err := http.NewRequest("GET", s.objectURL(publicID), nil)
+	if err != nil {
+		return RangeResult{}, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Range", rangeHeader)
+
+	resp, err := s.do(req, sha256Hex(nil))
+	if err != nil {
+		return RangeResult{}, fmt.Errorf("request to S3 failed: %v", err)
+	}
+	return rangeResponse(resp)
+}
+
+// do — tandatangani request dengan AWS Signature V4 lalu kirim. payloadHash berisi
+// SHA-256 isi request, atau UNSIGNED-PAYLOAD untuk isi yang dialirkan.
+func (s *S3Storage) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaderNames := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if req.Header.Get("Content-Type") != "" {
+		signedHeaderNames = append(signedHeaderNames, "content-type")
+	}
+	sort.Strings(signedHeaderNames)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaderNames {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	signedHeaders := strings.Join(signedHeaderNames, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := dateStamp + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.SecretKey), dateStamp)
+	signingKey = hmacSHA256(signingKey, s.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signedHeaders, signature,
+	))
+
+	return s.client.Do(req)
+}
+
+func sha256Hex(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// s3EscapePath — encode tiap segmen key sesuai aturan URI S3 (RFC 3986)
+func s3EscapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, seg := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(seg), "+", "%2B")
+	}
+	return strings.Join(segments, "/")
+}
@@ -3,44 +3,48 @@ package controllers
 import (
 	"dinsos_kuburaya/config"
 	"dinsos_kuburaya/models"
-	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
+var activityLogListSpec = listSpec{
+	Table: "activity_logs",
+	Sorts: map[string]listSort{
+		"created_at": {Column: "created_at"},
+		"action":     {Column: "action"},
+		"user_name":  {Column: "user_name"},
+	},
+	DefaultSort:  "created_at",
+	DefaultOrder: "desc",
+	DateColumn:   "created_at",
+}
+
 func GetAllActivityLogs(c *gin.Context) {
-	var logs []models.ActivityLog
+	list, ok := parseListQuery(c, activityLogListSpec)
+	if !ok {
+		return
+	}
 
-	page := 1
-	limit := 20
+	query := config.DB.Model(&models.ActivityLog{})
 
-	if p := c.Query("page"); p != "" {
-		fmt.Sscanf(p, "%d", &page)
+	if action := c.Query("action"); action != "" && action != "all" {
+		query = query.Where("action = ?", action)
 	}
-	if l := c.Query("limit"); l != "" {
-		fmt.Sscanf(l, "%d", &limit)
+	if userID := c.Query("user_id"); userID != "" && userID != "all" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if search := c.Query("search"); search != "" {
+		s := "%" + search + "%"
+		query = query.Where("message LIKE ? OR user_name LIKE ?", s, s)
 	}
 
-	offset := (page - 1) * limit
-
-	if err := config.DB.
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&logs).Error; err != nil {
-
+	var logs []models.ActivityLog
+	meta, err := list.find(query, &logs)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil log aktivitas"})
 		return
 	}
 
-	var total int64
-	config.DB.Model(&models.ActivityLog{}).Count(&total)
-
-	c.JSON(http.StatusOK, gin.H{
-		"page":  page,
-		"limit": limit,
-		"total": total,
-		"data":  logs,
-	})
+	c.JSON(http.StatusOK, listResponse(logs, meta))
 }
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reportMaxYears — rentang terpanjang date_from..date_to untuk laporan dan statistik
+const reportMaxYears = 10
+
+// reportPeriod — periode laporan: date_from & date_to, atau year (default tahun berjalan) dengan month opsional
+func reportPeriod(c *gin.Context) (time.Time, time.Time, bool) {
+	if c.Query("date_from") != "" || c.Query("date_to") != "" {
+		from, err := parseLetterDate(c.Query("date_from"))
+		if err != nil || from == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date_from wajib diisi dengan format YYYY-MM-DD"})
+			return time.Time{}, time.Time{}, false
+		}
+		to, err := parseLetterDate(c.Query("date_to"))
+		if err != nil || to == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date_to wajib diisi dengan format YYYY-MM-DD"})
+			return time.Time{}, time.Time{}, false
+		}
+		if to.Before(*from) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date_to tidak boleh sebelum date_from"})
+			return time.Time{}, time.Time{}, false
+		}
+		if !to.Before(from.AddDate(reportMaxYears, 0, 0)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "periode laporan maksimal " + strconv.Itoa(reportMaxYears) + " tahun"})
+			return time.Time{}, time.Time{}, false
+		}
+		return *from, *to, true
+	}
+
+	year := time.Now().Year()
+	if v := c.Query("year"); v != "" {
+		y, err := strconv.Atoi(v)
+		if err != nil || y < 1900 || y > 9999 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "year tidak valid"})
+			return time.Time{}, time.Time{}, false
+		}
+		year = y
+	}
+
+	if v := c.Query("month"); v != "" {
+		month, err := strconv.Atoi(v)
+		if err != nil || month < 1 || month > 12 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "month harus 1 sampai 12"})
+			return time.Time{}, time.Time{}, false
+		}
+		from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+		return from, from.AddDate(0, 1, -1), true
+	}
+
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+	return from, from.AddDate(1, 0, -1), true
+}
+
+// =======================
+// BUKU AGENDA (XLSX / PDF)
+// =======================
+func ExportAgendaBook(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	format := c.DefaultQuery("format", services.AgendaBookPDF)
+	if format != services.AgendaBookPDF && format != services.AgendaBookXLSX {
+		c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrAgendaBookFormat.Error()})
+		return
+	}
+
+	from, to, ok := reportPeriod(c)
+	if !ok {
+		return
+	}
+
+	query := services.DocumentAccess(user).ScopeDocuments(config.DB.Model(&models.Document{}))
+	book, err := services.BuildAgendaBook(query, c.Query("letter_type"), from, to, user.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAgendaBookLetterType),
+			errors.Is(err, services.ErrAgendaBookPeriod),
+			errors.Is(err, services.ErrAgendaBookTooLarge):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyusun buku agenda"})
+		}
+		return
+	}
+
+	// disusun di memori dulu agar kegagalan masih bisa dijawab sebagai JSON
+	var out bytes.Buffer
+	contentType := "application/pdf"
+	if format == services.AgendaBookXLSX {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		err = book.WriteXLSX(&out)
+	} else {
+		err = book.WritePDF(&out)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat file buku agenda"})
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "download", "Mengekspor "+book.Title()+" ("+book.PeriodLabel()+", "+format+")")
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": book.FileName(format)}))
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, contentType, out.Bytes())
+}
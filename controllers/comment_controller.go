@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CommentRequest — parent_id diisi untuk membalas komentar lain
+type CommentRequest struct {
+	Body     string  `json:"body"`
+	ParentID *string `json:"parent_id"`
+}
+
+func commentError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCommentNotAuthor),
+		errors.Is(err, services.ErrCommentDeleteForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCommentEditWindow):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCommentEmpty),
+		errors.Is(err, services.ErrCommentTooLong),
+		errors.Is(err, services.ErrCommentParent):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// commentDocument — komentar boleh dibaca & ditulis siapa pun yang boleh melihat dokumen
+func commentDocument(c *gin.Context, docType string) (services.DocumentRef, bool) {
+	document, ok := versionedDocument(c, docType, c.Param("id"), services.DocActionView)
+	if !ok {
+		return services.DocumentRef{}, false
+	}
+	return versionedRef(document), true
+}
+
+// listComments — membuka komentar sekaligus mendaftarkan user sebagai penonton event real-time
+func listComments(c *gin.Context, docType string) {
+	user := c.MustGet("user").(models.User)
+
+	ref, ok := commentDocument(c, docType)
+	if !ok {
+		return
+	}
+
+	comments, err := services.DocumentComments(ref)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil komentar"})
+		return
+	}
+	services.WatchComments(ref, user.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"comments":            comments,
+		"edit_window_seconds": int(services.CommentEditWindow().Seconds()),
+	})
+}
+
+func createComment(c *gin.Context, docType string) {
+	user := c.MustGet("user").(models.User)
+
+	ref, ok := commentDocument(c, docType)
+	if !ok {
+		return
+	}
+
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := services.CreateDocumentComment(ref, user, req.ParentID, req.Body)
+	if err != nil {
+		commentError(c, err, "Gagal menyimpan komentar")
+		return
+	}
+	services.WatchComments(ref, user.ID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Komentar berhasil ditambahkan",
+		"comment": comment,
+	})
+}
+
+func updateComment(c *gin.Context, docType string) {
+	user := c.MustGet("user").(models.User)
+
+	ref, ok := commentDocument(c, docType)
+	if !ok {
+		return
+	}
+
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := services.FindDocumentComment(ref, c.Param("comment_id"))
+	if err != nil {
+		commentError(c, err, "Gagal mengambil komentar")
+		return
+	}
+
+	comment, err = services.UpdateDocumentComment(ref, comment, user, req.Body)
+	if err != nil {
+		commentError(c, err, "Gagal memperbarui komentar")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Komentar berhasil diperbarui",
+		"comment": comment,
+	})
+}
+
+func deleteComment(c *gin.Context, docType string) {
+	user := c.MustGet("user").(models.User)
+
+	ref, ok := commentDocument(c, docType)
+	if !ok {
+		return
+	}
+
+	comment, err := services.FindDocumentComment(ref, c.Param("comment_id"))
+	if err != nil {
+		commentError(c, err, "Gagal mengambil komentar")
+		return
+	}
+
+	if err := services.DeleteDocumentComment(ref, comment, user); err != nil {
+		commentError(c, err, "Gagal menghapus komentar")
+		return
+	}
+
+	// moderasi komentar milik orang lain dicatat di log aktivitas
+	if comment.UserID == nil || *comment.UserID != user.ID {
+		author := "user terhapus"
+		if comment.User != nil {
+			author = comment.User.Name
+		}
+		services.CreateActivity(user.ID, user.Name, "delete", "Menghapus komentar "+author+" pada "+ref.Name)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Komentar berhasil dihapus"})
+}
+
+// heartbeat penonton — dipanggil berkala selama dokumen dibuka agar tetap menerima event komentar
+func watchComments(c *gin.Context, docType string) {
+	user := c.MustGet("user").(models.User)
+
+	ref, ok := commentDocument(c, docType)
+	if !ok {
+		return
+	}
+	services.WatchComments(ref, user.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Berlangganan komentar"})
+}
+
+func unwatchComments(c *gin.Context, docType string) {
+	user := c.MustGet("user").(models.User)
+
+	services.UnwatchComments(services.DocumentRef{Type: docType, ID: c.Param("id")}, user.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Berhenti berlangganan komentar"})
+}
+
+// =======================
+// KOMENTAR SURAT
+// =======================
+func GetDocumentComments(c *gin.Context) {
+	listComments(c, services.VersionTypeDocument)
+}
+
+func CreateDocumentComment(c *gin.Context) {
+	createComment(c, services.VersionTypeDocument)
+}
+
+func UpdateDocumentComment(c *gin.Context) {
+	updateComment(c, services.VersionTypeDocument)
+}
+
+func DeleteDocumentComment(c *gin.Context) {
+	deleteComment(c, services.VersionTypeDocument)
+}
+
+func WatchDocumentComments(c *gin.Context) {
+	watchComments(c, services.VersionTypeDocument)
+}
+
+func UnwatchDocumentComments(c *gin.Context) {
+	unwatchComments(c, services.VersionTypeDocument)
+}
+
+// =======================
+// KOMENTAR DOKUMEN STAF
+// =======================
+func GetDocumentStaffComments(c *gin.Context) {
+	listComments(c, services.VersionTypeDocumentStaff)
+}
+
+func CreateDocumentStaffComment(c *gin.Context) {
+	createComment(c, services.VersionTypeDocumentStaff)
+}
+
+func UpdateDocumentStaffComment(c *gin.Context) {
+	updateComment(c, services.VersionTypeDocumentStaff)
+}
+
+func DeleteDocumentStaffComment(c *gin.Context) {
+	deleteComment(c, services.VersionTypeDocumentStaff)
+}
+
+func WatchDocumentStaffComments(c *gin.Context) {
+	watchComments(c, services.VersionTypeDocumentStaff)
+}
+
+func UnwatchDocumentStaffComments(c *gin.Context) {
+	unwatchComments(c, services.VersionTypeDocumentStaff)
+}
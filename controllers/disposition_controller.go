@@ -0,0 +1,461 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var allowedDispositionPriorities = map[string]bool{
+	"rendah": true,
+	"sedang": true,
+	"tinggi": true,
+}
+
+type DispositionRequest struct {
+	UserIDs     []string `json:"user_ids" binding:"required"`
+	Instruction string   `json:"instruction" binding:"required"`
+	DueDate     string   `json:"due_date"`
+	Priority    string   `json:"priority"`
+}
+
+type DispositionNoteRequest struct {
+	Note string `json:"note" binding:"required"`
+}
+
+// hanya kolom aman dari user yang ikut dikirim bersama disposisi
+func selectUserSummary(db *gorm.DB) *gorm.DB {
+	return db.Select("id", "name", "username", "role", "photo_url")
+}
+
+func dispositionLink(documentID string) string {
+	return "/documents/" + documentID
+}
+
+// createDispositions — buat satu disposisi per penerima lalu kirim notifikasi
+func createDispositions(c *gin.Context, document models.Document, parentID *string, sender models.User, req DispositionRequest) ([]models.Disposition, bool) {
+	priority := req.Priority
+	if priority == "" {
+		priority = "sedang"
+	}
+	if !allowedDispositionPriorities[priority] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Prioritas harus rendah, sedang atau tinggi"})
+		return nil, false
+	}
+
+	dueDate, err := parseLetterDate(req.DueDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format batas waktu harus YYYY-MM-DD"})
+		return nil, false
+	}
+
+	instruction := strings.TrimSpace(req.Instruction)
+	if instruction == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Instruksi wajib diisi"})
+		return nil, false
+	}
+
+	// hilangkan duplikat & pengirim sendiri
+	seen := map[string]bool{sender.ID: true}
+	var userIDs []string
+	for _, id := range req.UserIDs {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			userIDs = append(userIDs, id)
+		}
+	}
+	if len(userIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Minimal satu penerima disposisi"})
+		return nil, false
+	}
+
+	var recipients []models.User
+	if err := config.DB.Select("id", "name").Where("id IN ?", userIDs).Find(&recipients).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa penerima"})
+		return nil, false
+	}
+	if len(recipients) != len(userIDs) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Sebagian penerima tidak ditemukan"})
+		return nil, false
+	}
+
+	dispositions := make([]models.Disposition, 0, len(recipients))
+	for _, r := range recipients {
+		dispositions = append(dispositions, models.Disposition{
+			DocumentID:  document.ID,
+			ParentID:    parentID,
+			FromUserID:  sender.ID,
+			ToUserID:    r.ID,
+			Instruction: instruction,
+			DueDate:     dueDate,
+			Priority:    priority,
+			Status:      "pending",
+		})
+	}
+
+	if err := config.DB.Create(&dispositions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan disposisi"})
+		return nil, false
+	}
+
+	for _, r := range recipients {
+		services.NotifySpecificUser(
+			r.ID,
+			"Disposisi dari "+sender.Name+": "+document.Subject,
+			dispositionLink(document.ID),
+		)
+		services.CreateActivity(
+			sender.ID,
+			sender.Name,
+			"disposition",
+			"Mendisposisikan surat "+document.FileName+" kepada "+r.Name,
+		)
+	}
+
+	return dispositions, true
+}
+
+// loadDispositionForAction — ambil disposisi & pastikan user adalah penerimanya
+func loadDispositionForAction(c *gin.Context, user models.User) (models.Disposition, bool) {
+	var disposition models.Disposition
+	if err := config.DB.Preload("Document").First(&disposition, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Disposisi tidak ditemukan"})
+		return disposition, false
+	}
+
+	if disposition.Document == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen disposisi sudah dihapus"})
+		return disposition, false
+	}
+
+	if disposition.ToUserID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Disposisi ini bukan untuk Anda"})
+		return disposition, false
+	}
+
+	if disposition.Status == "done" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Disposisi sudah selesai"})
+		return disposition, false
+	}
+
+	return disposition, true
+}
+
+// =======================
+// CREATE DISPOSITION (admin)
+// =======================
+func CreateDisposition(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	document, ok := findDocument(c, config.DB, services.DocActionView)
+	if !ok {
+		return
+	}
+
+	if document.LetterType != "masuk" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Disposisi hanya untuk surat masuk"})
+		return
+	}
+
+	var req DispositionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dispositions, ok := createDispositions(c, document, nil, user, req)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Disposisi berhasil dibuat",
+		"dispositions": dispositions,
+	})
+}
+
+// =======================
+// GET DISPOSITION CHAIN OF A DOCUMENT
+// =======================
+func GetDocumentDispositions(c *gin.Context) {
+	documentID := c.Param("id")
+
+	if _, ok := findDocument(c, config.DB, services.DocActionView); !ok {
+		return
+	}
+
+	var dispositions []models.Disposition
+	if err := config.DB.
+		Preload("FromUser", selectUserSummary).
+		Preload("ToUser", selectUserSummary).
+		Preload("Notes", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Notes.User", selectUserSummary).
+		Where("document_id = ?", documentID).
+		Order("created_at ASC").
+		Find(&dispositions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil disposisi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"document_id":  documentID,
+		"dispositions": dispositions,
+		"total":        len(dispositions),
+	})
+}
+
+// =======================
+// GET MY DISPOSITIONS (inbox)
+// =======================
+var dispositionListSpec = listSpec{
+	Table: "dispositions",
+	Sorts: map[string]listSort{
+		"created_at": {Column: "created_at"},
+		"due_date":   {Column: "due_date", Nullable: true},
+		"priority":   {Column: "priority"},
+		"status":     {Column: "status"},
+	},
+	DefaultSort:  "created_at",
+	DefaultOrder: "desc",
+	DateColumn:   "created_at",
+}
+
+func GetMyDispositions(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	list, ok := parseListQuery(c, dispositionListSpec)
+	if !ok {
+		return
+	}
+
+	// disposisi dari surat yang sudah di-trash tidak ditampilkan
+	query := config.DB.Model(&models.Disposition{}).
+		Joins("JOIN documents ON documents.id = dispositions.document_id AND documents.deleted_at IS NULL").
+		Where("dispositions.to_user_id = ?", user.ID)
+
+	if status := c.Query("status"); status != "" && status != "all" {
+		query = query.Where("dispositions.status = ?", status)
+	}
+
+	var dispositions []models.Disposition
+	meta, err := list.find(query, &dispositions,
+		selectColumns("dispositions.*"),
+		preload("Document"),
+		preload("FromUser", selectUserSummary),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil disposisi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, listResponse(dispositions, meta))
+}
+
+// =======================
+// ACKNOWLEDGE
+// =======================
+func AcknowledgeDisposition(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	disposition, ok := loadDispositionForAction(c, user)
+	if !ok {
+		return
+	}
+
+	if disposition.AcknowledgedAt == nil {
+		now := time.Now()
+		updates := map[string]interface{}{"acknowledged_at": now}
+		if disposition.Status == "pending" {
+			updates["status"] = "acknowledged"
+		}
+
+		if err := config.DB.Model(&disposition).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui disposisi"})
+			return
+		}
+
+		services.NotifySpecificUser(
+			disposition.FromUserID,
+			user.Name+" telah menerima disposisi: "+disposition.Document.Subject,
+			dispositionLink(disposition.DocumentID),
+		)
+		services.CreateActivity(user.ID, user.Name, "disposition", "Menerima disposisi surat "+disposition.Document.FileName)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Disposisi diterima",
+		"disposition": disposition,
+	})
+}
+
+// =======================
+// ADD PROGRESS NOTE
+// =======================
+func AddDispositionNote(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	disposition, ok := loadDispositionForAction(c, user)
+	if !ok {
+		return
+	}
+
+	var req DispositionNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Note) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Catatan wajib diisi"})
+		return
+	}
+
+	note := models.DispositionNote{
+		DispositionID: disposition.ID,
+		UserID:        user.ID,
+		Note:          strings.TrimSpace(req.Note),
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&note).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"status": "in_progress"}
+		if disposition.AcknowledgedAt == nil {
+			updates["acknowledged_at"] = time.Now()
+		}
+		return tx.Model(&disposition).Updates(updates).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan catatan"})
+		return
+	}
+
+	services.NotifySpecificUser(
+		disposition.FromUserID,
+		"Progres disposisi dari "+user.Name+": "+note.Note,
+		dispositionLink(disposition.DocumentID),
+	)
+	services.CreateActivity(user.ID, user.Name, "disposition", "Menambahkan catatan disposisi surat "+disposition.Document.FileName)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Catatan berhasil ditambahkan",
+		"note":    note,
+	})
+}
+
+// =======================
+// FORWARD
+// =======================
+func ForwardDisposition(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	disposition, ok := loadDispositionForAction(c, user)
+	if !ok {
+		return
+	}
+
+	var req DispositionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	parentID := disposition.ID
+	children, ok := createDispositions(c, *disposition.Document, &parentID, user, req)
+	if !ok {
+		return
+	}
+
+	updates := map[string]interface{}{"status": "forwarded"}
+	if disposition.AcknowledgedAt == nil {
+		updates["acknowledged_at"] = time.Now()
+	}
+	if err := config.DB.Model(&disposition).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal meneruskan disposisi"})
+		return
+	}
+
+	recipientIDs := make([]string, 0, len(children))
+	for _, child := range children {
+		recipientIDs = append(recipientIDs, child.ToUserID)
+	}
+	var recipientNames []string
+	config.DB.Model(&models.User{}).Where("id IN ?", recipientIDs).Pluck("name", &recipientNames)
+
+	services.NotifySpecificUser(
+		disposition.FromUserID,
+		user.Name+" meneruskan disposisi: "+disposition.Document.Subject,
+		dispositionLink(disposition.DocumentID),
+	)
+	services.CreateActivity(
+		user.ID,
+		user.Name,
+		"disposition",
+		"Meneruskan disposisi surat "+disposition.Document.FileName+" kepada "+strings.Join(recipientNames, ", ")+
+			" dengan instruksi: "+strings.TrimSpace(req.Instruction),
+	)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Disposisi berhasil diteruskan",
+		"dispositions": children,
+	})
+}
+
+// =======================
+// MARK DONE
+// =======================
+func CompleteDisposition(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	disposition, ok := loadDispositionForAction(c, user)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Note string `json:"note"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	now := time.Now()
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if note := strings.TrimSpace(req.Note); note != "" {
+			if err := tx.Create(&models.DispositionNote{
+				DispositionID: disposition.ID,
+				UserID:        user.ID,
+				Note:          note,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		updates := map[string]interface{}{
+			"status":       "done",
+			"completed_at": now,
+		}
+		if disposition.AcknowledgedAt == nil {
+			updates["acknowledged_at"] = now
+		}
+		return tx.Model(&disposition).Updates(updates).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyelesaikan disposisi"})
+		return
+	}
+
+	services.NotifySpecificUser(
+		disposition.FromUserID,
+		user.Name+" menyelesaikan disposisi: "+disposition.Document.Subject,
+		dispositionLink(disposition.DocumentID),
+	)
+	services.CreateActivity(user.ID, user.Name, "disposition", "Menyelesaikan disposisi surat "+disposition.Document.FileName)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Disposisi selesai",
+		"disposition": disposition,
+	})
+}
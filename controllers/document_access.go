@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// authorizeDocument — periksa aksi lewat services.AuthorizeDocument. Setiap penolakan
+// dicatat di log aktivitas; dokumen yang sama sekali tidak boleh dilihat dijawab 404
+// agar keberadaannya tidak bocor.
+func authorizeDocument(c *gin.Context, ref services.DocumentRef, action string) bool {
+	user := c.MustGet("user").(models.User)
+	if services.AuthorizeDocument(user, ref, action) {
+		return true
+	}
+
+	services.RecordAccessDenied(user, ref, action, c.Request.Method+" "+c.Request.URL.Path+" dari "+c.ClientIP())
+
+	if action != services.DocActionView && services.AuthorizeDocument(user, ref, services.DocActionView) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak memiliki akses " + action + " untuk dokumen ini"})
+	} else {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen tidak ditemukan"})
+	}
+	return false
+}
+
+// findDocument — ambil surat berdasarkan :id dan pastikan user boleh melakukan aksi
+func findDocument(c *gin.Context, db *gorm.DB, action string) (models.Document, bool) {
+	var document models.Document
+	if err := db.First(&document, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen tidak ditemukan"})
+		return document, false
+	}
+	return document, authorizeDocument(c, services.DocumentRefOf(document), action)
+}
+
+// findDocumentStaff — ambil dokumen staf berdasarkan :id dan pastikan user boleh melakukan aksi
+func findDocumentStaff(c *gin.Context, db *gorm.DB, action string) (models.DocumentStaff, bool) {
+	var document models.DocumentStaff
+	if err := db.First(&document, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen tidak ditemukan"})
+		return document, false
+	}
+	return document, authorizeDocument(c, services.DocumentStaffRefOf(document), action)
+}
+
+// resolveUnitID — validasi unit_id dari request. Kosong = fallback;
+// unit harus ada dan berada dalam cakupan akses user.
+func resolveUnitID(c *gin.Context, raw string, fallback *string, access services.UnitAccess) (*string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return fallback, true
+	}
+
+	if _, err := services.FindUnit(raw); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if !access.CanUseUnit(&raw) {
+		c.JSON(http.StatusForbidden, gin.H{"error": services.ErrUnitOutOfScope.Error()})
+		return nil, false
+	}
+	return &raw, true
+}
+
+// selectUnitSummary — kolom unit yang disertakan pada dokumen
+func selectUnitSummary(db *gorm.DB) *gorm.DB {
+	return db.Select("id", "name", "code")
+}
@@ -4,9 +4,7 @@ import (
 	"bytes"
 	"io"
 	"net/http"
-	"path/filepath"
 	"strconv"
-	"strings"
 	"time"
 
 	"dinsos_kuburaya/config"
@@ -14,6 +12,7 @@ import (
 	"dinsos_kuburaya/services"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // =======================
@@ -22,7 +21,27 @@ import (
 func CreateDocument(c *gin.Context) {
 	sender := c.PostForm("sender")
 	subject := c.PostForm("subject")
-	letterType := c.PostForm("letter_type")
+
+	metadata := letterMetadata{
+		LetterType:         c.PostForm("letter_type"),
+		LetterNumber:       c.PostForm("letter_number"),
+		LetterDate:         c.PostForm("letter_date"),
+		ReceivedDate:       c.PostForm("received_date"),
+		Recipient:          c.PostForm("recipient"),
+		ClassificationCode: c.PostForm("classification_code"),
+		Urgency:            c.PostForm("urgency"),
+		Confidentiality:    c.PostForm("confidentiality"),
+	}
+
+	var document models.Document
+	if err := metadata.applyTo(&document); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.CheckAgendaRequirements(document); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	userRaw, exists := c.Get("user")
 	if !exists {
@@ -31,6 +50,12 @@ func CreateDocument(c *gin.Context) {
 	}
 	user := userRaw.(models.User)
 
+	// unit pemilik surat, default unit pengunggah
+	unitID, ok := resolveUnitID(c, c.PostForm("unit_id"), user.UnitID, services.DocumentAccess(user))
+	if !ok {
+		return
+	}
+
 	fileHeader, err := c.FormFile("file")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "File tidak ditemukan"})
@@ -50,97 +75,175 @@ func CreateDocument(c *gin.Context) {
 		return
 	}
 
-	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
-	var resourceType, folder string
-
-	switch ext {
-	case ".jpg", ".jpeg", ".png", ".gif", ".webp":
-		resourceType = "image"
-		folder = "gambar"
-	case ".pdf":
-		resourceType = "raw"
-		folder = "arsip"
-	default:
-		resourceType = "raw"
-		folder = "arsip"
-	}
+	resourceType, folder := documentUploadTarget(fileHeader.Filename)
 
 	reader := bytes.NewReader(fileBytes)
-	uploadResult, err := config.UploadToCloudinary(reader, fileHeader.Filename, folder, resourceType)
+	uploadResult, err := config.FileStorage.Upload(reader, fileHeader.Filename, folder, resourceType)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cloudinary upload gagal: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Upload file gagal: " + err.Error()})
 		return
 	}
 
 	userID := user.ID
-	document := models.Document{
-		Sender:       sender,
-		FileName:     fileHeader.Filename,
-		FileURL:      uploadResult.SecureURL,
-		Subject:      subject,
-		LetterType:   letterType,
-		UserID:       &userID,
-		PublicID:     uploadResult.PublicID,
-		ResourceType: uploadResult.ResourceType,
-	}
-
-	if err := config.DB.Create(&document).Error; err != nil {
+	document.Sender = sender
+	document.FileName = fileHeader.Filename
+	document.FileURL = uploadResult.URL
+	document.Subject = subject
+	document.UserID = &userID
+	document.UnitID = unitID
+	document.PublicID = uploadResult.PublicID
+	document.ResourceType = uploadResult.ResourceType
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.AssignAgendaNumber(tx, &document); err != nil {
+			return err
+		}
+		return tx.Create(&document).Error
+	})
+	if err != nil {
+		config.FileStorage.Delete(uploadResult.PublicID, uploadResult.ResourceType)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan dokumen di database"})
 		return
 	}
 
-	services.CreateActivity(user.ID, user.Name, "create", "Mengunggah dokumen: "+document.FileName)
+	if err := services.QueueExtraction(services.VersionTypeDocument, document.ID); err == nil {
+		document.ExtractionStatus = services.ExtractionPending
+	}
 
-	services.NotifyAllUsers(
-		"Dokumen baru diunggah: "+document.FileName,
-		document.FileURL,
-	)
+	services.CreateActivity(user.ID, user.Name, "create", "Mengunggah dokumen: "+document.FileName+" (agenda "+document.AgendaNumber+")")
+
+	// surat milik unit hanya diberitahukan ke user yang dapat melihatnya
+	if document.UnitID == nil {
+		services.NotifyAllUsers(
+			"Dokumen baru diunggah: "+document.FileName,
+			"/documents/"+document.ID,
+		)
+	} else {
+		for _, id := range services.UnitAudience(*document.UnitID, services.PermDocumentViewAll) {
+			services.NotifySpecificUser(id, "Dokumen baru diunggah: "+document.FileName, "/documents/"+document.ID)
+		}
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":  "Dokumen berhasil diupload",
-		"document": document,
-		"file_url": uploadResult.SecureURL,
+		"message":      "Dokumen berhasil diupload",
+		"document":     document,
+		"download_url": document.DownloadURL,
 	})
 }
 
 // =======================
 // GET ALL DOCUMENTS
 // =======================
+var documentListSpec = listSpec{
+	Table: "documents",
+	Sorts: map[string]listSort{
+		"created_at":    {Column: "created_at"},
+		"updated_at":    {Column: "updated_at"},
+		"subject":       {Column: "subject"},
+		"sender":        {Column: "sender"},
+		"agenda_number": {Column: "agenda_number"},
+		"letter_date":   {Column: "letter_date", Nullable: true},
+		"received_date": {Column: "received_date", Nullable: true},
+	},
+	DefaultSort:  "created_at",
+	DefaultOrder: "desc",
+	DateColumn:   "created_at",
+}
+
 func GetDocuments(c *gin.Context) {
+	list, ok := parseListQuery(c, documentListSpec)
+	if !ok {
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+	query, ok := filterDocuments(c, services.DocumentAccess(user).ScopeDocuments(config.DB.Model(&models.Document{})))
+	if !ok {
+		return
+	}
+
 	var documents []models.Document
+	meta, err := list.find(query, &documents, preload("User"), preload("Unit", selectUnitSummary))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data dokumen"})
+		return
+	}
 
+	c.JSON(http.StatusOK, listResponse(documents, meta))
+}
+
+// filterDocuments — filter query string daftar surat (dipakai juga oleh ekspor ZIP).
+// Mengirim 400 dan mengembalikan false bila parameter tidak valid.
+func filterDocuments(c *gin.Context, query *gorm.DB) (*gorm.DB, bool) {
 	search := c.Query("search")
 	letterType := c.Query("letter_type")
 
-	query := config.DB.Preload("User")
 	if letterType != "" && letterType != "all" {
 		query = query.Where("letter_type = ?", letterType)
 	}
 	if search != "" {
 		s := "%" + search + "%"
-		query = query.Where("sender LIKE ? OR subject LIKE ? OR file_name LIKE ?", s, s, s)
+		query = query.Where(
+			"sender LIKE ? OR subject LIKE ? OR file_name LIKE ? OR letter_number LIKE ? OR recipient LIKE ? OR classification_code LIKE ? OR agenda_number LIKE ?",
+			s, s, s, s, s, s, s,
+		)
 	}
 
-	if err := query.Order("created_at DESC").Find(&documents).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data dokumen"})
-		return
+	if unitID := c.Query("unit_id"); unitID != "" && unitID != "all" {
+		if c.Query("include_subunits") == "true" {
+			query = query.Where("documents.unit_id IN ?", services.SubtreeUnitIDs(unitID))
+		} else {
+			query = query.Where("documents.unit_id = ?", unitID)
+		}
+	}
+	if agendaYear := c.Query("agenda_year"); agendaYear != "" {
+		query = query.Where("agenda_year = ?", agendaYear)
+	}
+	if letterNumber := c.Query("letter_number"); letterNumber != "" {
+		query = query.Where("letter_number = ?", letterNumber)
+	}
+	if classification := c.Query("classification_code"); classification != "" {
+		// kode induk ikut mencakup turunannya, mis. 400 -> 400.9.1
+		query = query.Where("classification_code = ? OR classification_code LIKE ?", classification, classification+".%")
+	}
+	if urgency := c.Query("urgency"); urgency != "" && urgency != "all" {
+		query = query.Where("urgency = ?", urgency)
+	}
+	if confidentiality := c.Query("confidentiality"); confidentiality != "" && confidentiality != "all" {
+		query = query.Where("confidentiality = ?", confidentiality)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"documents": documents,
-		"total":     len(documents),
-	})
+	dateFilters := []struct {
+		param  string
+		clause string
+	}{
+		{"letter_date_from", "letter_date >= ?"},
+		{"letter_date_to", "letter_date <= ?"},
+		{"received_date_from", "received_date >= ?"},
+		{"received_date_to", "received_date <= ?"},
+	}
+	for _, f := range dateFilters {
+		value := c.Query(f.param)
+		if value == "" {
+			continue
+		}
+		date, err := parseLetterDate(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format " + f.param + " harus YYYY-MM-DD"})
+			return query, false
+		}
+		query = query.Where(f.clause, date.Format(letterDateLayout))
+	}
+
+	return query, true
 }
 
 // =======================
 // GET DOCUMENT BY ID
 // =======================
 func GetDocumentByID(c *gin.Context) {
-	id := c.Param("id")
-	var document models.Document
-
-	if err := config.DB.Preload("User").First(&document, "id = ?", id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen tidak ditemukan"})
+	document, ok := findDocument(c, config.DB.Preload("User").Preload("Unit", selectUnitSummary), services.DocActionView)
+	if !ok {
 		return
 	}
 
@@ -151,38 +254,143 @@ func GetDocumentByID(c *gin.Context) {
 // UPDATE DOCUMENT
 // =======================
 func UpdateDocument(c *gin.Context) {
-	id := c.Param("id")
-	var document models.Document
+	user := c.MustGet("user").(models.User)
 
-	if err := config.DB.First(&document, "id = ?", id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen tidak ditemukan"})
+	document, ok := findDocument(c, config.DB, services.DocActionEdit)
+	if !ok {
 		return
 	}
 
+	// JSON untuk perubahan metadata saja, multipart bila sekaligus mengganti file
 	var payload struct {
-		Sender     string `json:"sender"`
-		Subject    string `json:"subject"`
-		LetterType string `json:"letter_type"`
+		Sender             string  `json:"sender" form:"sender"`
+		Subject            string  `json:"subject" form:"subject"`
+		LetterType         string  `json:"letter_type" form:"letter_type"`
+		LetterNumber       *string `json:"letter_number" form:"letter_number"`
+		LetterDate         *string `json:"letter_date" form:"letter_date"`
+		ReceivedDate       *string `json:"received_date" form:"received_date"`
+		Recipient          *string `json:"recipient" form:"recipient"`
+		ClassificationCode *string `json:"classification_code" form:"classification_code"`
+		Urgency            *string `json:"urgency" form:"urgency"`
+		Confidentiality    *string `json:"confidentiality" form:"confidentiality"`
+		UnitID             *string `json:"unit_id" form:"unit_id"`
+		ChangeNote         string  `json:"change_note" form:"change_note"`
 	}
-	if err := c.ShouldBindJSON(&payload); err != nil {
+	if err := c.ShouldBind(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// nomor agenda terikat pada jenis surat, jadi jenisnya tidak boleh diubah lagi
+	if document.AgendaSequence != nil && payload.LetterType != document.LetterType {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jenis surat tidak dapat diubah setelah nomor agenda diterbitkan"})
+		return
+	}
+
+	// field metadata yang tidak dikirim tetap memakai nilai lama
+	metadata := letterMetadataFromDocument(document)
+	metadata.LetterType = payload.LetterType
+	setIfProvided(&metadata.LetterNumber, payload.LetterNumber)
+	setIfProvided(&metadata.LetterDate, payload.LetterDate)
+	setIfProvided(&metadata.ReceivedDate, payload.ReceivedDate)
+	setIfProvided(&metadata.Recipient, payload.Recipient)
+	setIfProvided(&metadata.ClassificationCode, payload.ClassificationCode)
+	setIfProvided(&metadata.Urgency, payload.Urgency)
+	setIfProvided(&metadata.Confidentiality, payload.Confidentiality)
+
+	if err := metadata.applyTo(&document); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	document.Sender = payload.Sender
 	document.Subject = payload.Subject
-	document.LetterType = payload.LetterType
 
-	if err := config.DB.Save(&document).Error; err != nil {
+	// unit_id kosong = surat untuk seluruh kantor
+	if payload.UnitID != nil {
+		access := services.DocumentAccess(user)
+		if *payload.UnitID == "" {
+			if !access.All {
+				c.JSON(http.StatusForbidden, gin.H{"error": services.ErrUnitOutOfScope.Error()})
+				return
+			}
+			document.UnitID = nil
+			document.Unit = nil
+		} else {
+			unitID, ok := resolveUnitID(c, *payload.UnitID, nil, access)
+			if !ok {
+				return
+			}
+			document.UnitID = unitID
+			document.Unit = nil
+		}
+	}
+
+	current := services.VersionFile{
+		FileName:     document.FileName,
+		FileURL:      document.FileURL,
+		PublicID:     document.PublicID,
+		ResourceType: document.ResourceType,
+	}
+
+	// ganti file (opsional) — file lama disimpan sebagai riwayat versi
+	var newFile *services.VersionFile
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		src, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuka file"})
+			return
+		}
+		defer src.Close()
+
+		resourceType, folder := documentUploadTarget(fileHeader.Filename)
+		uploadResult, err := config.FileStorage.Upload(src, fileHeader.Filename, folder, resourceType)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Upload file gagal: " + err.Error()})
+			return
+		}
+
+		newFile = &services.VersionFile{
+			FileName:     fileHeader.Filename,
+			FileURL:      uploadResult.URL,
+			PublicID:     uploadResult.PublicID,
+			ResourceType: uploadResult.ResourceType,
+		}
+
+		document.FileName = newFile.FileName
+		document.FileURL = newFile.FileURL
+		document.PublicID = newFile.PublicID
+		document.ResourceType = newFile.ResourceType
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if newFile != nil {
+			if _, err := services.RecordNewVersion(
+				tx, services.VersionTypeDocument, document.ID,
+				current, document.UserID, *newFile, user.ID, payload.ChangeNote,
+			); err != nil {
+				return err
+			}
+		}
+		return tx.Omit(services.ExtractionColumns...).Save(&document).Error
+	})
+	if err != nil {
+		if newFile != nil {
+			config.FileStorage.Delete(newFile.PublicID, newFile.ResourceType)
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui dokumen"})
 		return
 	}
 
-	if userRaw, exists := c.Get("user"); exists {
-		user := userRaw.(models.User)
-		services.CreateActivity(user.ID, user.Name, "update", "Memperbarui dokumen: "+document.FileName)
+	// file baru → isi teks diekstrak ulang
+	if newFile != nil {
+		if err := services.QueueExtraction(services.VersionTypeDocument, document.ID); err == nil {
+			document.ExtractionStatus = services.ExtractionPending
+		}
 	}
 
+	services.CreateActivity(user.ID, user.Name, "update", "Memperbarui dokumen: "+document.FileName)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":  "Dokumen berhasil diperbarui",
 		"document": document,
@@ -193,6 +401,7 @@ func UpdateDocument(c *gin.Context) {
 // GET DOCUMENT SUMMARY (PER BULAN, PER MINGGU)
 // =======================
 func GetDocumentSummary(c *gin.Context) {
+	access := services.DocumentAccess(c.MustGet("user").(models.User))
 
 	yearStr := c.DefaultQuery("year", "")
 	monthStr := c.DefaultQuery("month", "")
@@ -236,6 +445,21 @@ func GetDocumentSummary(c *gin.Context) {
 		{22, lastDay},
 	}
 
+	// satu kueri berkelompok per tanggal & jenis surat, lalu dijumlahkan per minggu
+	var rows []struct {
+		Day        int
+		LetterType string
+		Total      int
+	}
+	if err := access.ScopeDocuments(config.DB.Model(&models.Document{})).
+		Select("DAY(documents.created_at) AS day, documents.letter_type, COUNT(*) AS total").
+		Where("documents.created_at BETWEEN ? AND ?", startOfMonth, endOfMonth).
+		Group("day, documents.letter_type").
+		Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung ringkasan surat"})
+		return
+	}
+
 	for i, weekRange := range weekRanges {
 		if weekRange.start > lastDay {
 			weeks = append(weeks, WeekSummary{
@@ -256,20 +480,23 @@ func GetDocumentSummary(c *gin.Context) {
 		startDate := time.Date(year, time.Month(month), weekRange.start, 0, 0, 0, 0, time.Local)
 		endDate := time.Date(year, time.Month(month), endDay, 23, 59, 59, 0, time.Local)
 
-		startStr := startDate.Format("2006-01-02 15:04:05.000")
-		endStr := endDate.Format("2006-01-02 15:04:05.000")
-
-		var masuk, keluar int64
-		config.DB.Model(&models.Document{}).Where("created_at BETWEEN ? AND ? AND letter_type = ?", startDate, endDate, "masuk").Count(&masuk)
-		config.DB.Model(&models.Document{}).Where("created_at BETWEEN ? AND ? AND letter_type = ?", startDate, endDate, "keluar").Count(&keluar)
+		week := WeekSummary{
+			Week:  i + 1,
+			Start: startDate.Format("2006-01-02 15:04:05.000"),
+			End:   endDate.Format("2006-01-02 15:04:05.000"),
+		}
+		for _, row := range rows {
+			if row.Day < weekRange.start || row.Day > endDay {
+				continue
+			}
+			if row.LetterType == "masuk" {
+				week.Masuk += row.Total
+			} else if row.LetterType == "keluar" {
+				week.Keluar += row.Total
+			}
+		}
 
-		weeks = append(weeks, WeekSummary{
-			Week:   i + 1,
-			Start:  startStr,
-			End:    endStr,
-			Masuk:  int(masuk),
-			Keluar: int(keluar),
-		})
+		weeks = append(weeks, week)
 	}
 
 	monthName := startOfMonth.Month().String()
@@ -286,47 +513,32 @@ func GetDocumentSummary(c *gin.Context) {
 // DELETE DOCUMENT
 // =======================
 func DeleteDocument(c *gin.Context) {
-	id := c.Param("id")
-	var document models.Document
+	user := c.MustGet("user").(models.User)
 
-	if err := config.DB.First(&document, "id = ?", id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen tidak ditemukan"})
+	document, ok := findDocument(c, config.DB, services.DocActionDelete)
+	if !ok {
 		return
 	}
 
-	if document.PublicID != "" && document.ResourceType != "" {
-		_ = config.DeleteFromCloudinary(document.PublicID, document.ResourceType)
-	}
-
-	if err := config.DB.Delete(&document).Error; err != nil {
+	// soft delete — file baru dihapus permanen saat trash di-purge
+	if err := softDelete(&document, user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus dokumen"})
 		return
 	}
 
-	if userRaw, exists := c.Get("user"); exists {
-		user := userRaw.(models.User)
-		services.CreateActivity(user.ID, user.Name, "delete", "Menghapus dokumen: "+document.FileName)
-	}
+	services.CreateActivity(user.ID, user.Name, "delete", "Memindahkan dokumen ke trash: "+document.FileName)
 
-	c.JSON(http.StatusOK, gin.H{"message": "Dokumen berhasil dihapus"})
+	c.JSON(http.StatusOK, gin.H{"message": "Dokumen dipindahkan ke trash"})
 }
 
 // =======================
 // DOWNLOAD DOCUMENT
 // =======================
 func DownloadDocument(c *gin.Context) {
-	id := c.Param("id")
-	var document models.Document
-
-	if err := config.DB.First(&document, "id = ?", id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen tidak ditemukan"})
-		return
-	}
-
-	if document.FileURL == "" {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Link file tidak tersedia"})
+	document, ok := findDocument(c, config.DB, services.DocActionDownload)
+	if !ok {
 		return
 	}
 
-	c.Redirect(http.StatusTemporaryRedirect, document.FileURL)
+	sendDocumentFile(c, services.DocumentRefOf(document), nil, documentFile(document), services.DownloadViaDirect)
 }
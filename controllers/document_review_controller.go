@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewRequest struct {
+	Status  string `json:"status" binding:"required"`
+	Comment string `json:"comment"`
+}
+
+// reviewStatusFilter — filter ?review_status= untuk daftar dokumen staf
+func reviewStatusFilter(c *gin.Context) (string, bool) {
+	status := c.Query("review_status")
+	if status == "" || status == "all" {
+		return "", true
+	}
+	if !services.ValidReviewStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "review_status harus submitted, under_review, revision_requested, approved atau rejected"})
+		return "", false
+	}
+	return status, true
+}
+
+// =======================
+// REVIEW DOKUMEN STAF
+// =======================
+func ReviewDocumentStaff(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	document, ok := findDocumentStaff(c, config.DB, services.DocActionReview)
+	if !ok {
+		return
+	}
+
+	review, err := services.ReviewDocumentStaff(document, user, req.Status, req.Comment)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrReviewTransition):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error() + " dari status " + document.ReviewStatus})
+		case errors.Is(err, services.ErrReviewStatusInvalid),
+			errors.Is(err, services.ErrReviewCommentRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan review"})
+		}
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "review", "Review dokumen staff "+document.FileName+": "+review.FromStatus+" → "+review.ToStatus)
+
+	config.DB.Preload("User", selectUserSummary).First(&document, "id = ?", document.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Status review diperbarui",
+		"review":   review,
+		"document": document,
+	})
+}
+
+func GetDocumentStaffReviews(c *gin.Context) {
+	document, ok := findDocumentStaff(c, config.DB, services.DocActionView)
+	if !ok {
+		return
+	}
+
+	reviews, err := services.DocumentStaffReviews(document.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil riwayat review"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"review_status": document.ReviewStatus,
+		"reviews":       reviews,
+		"total":         len(reviews),
+	})
+}
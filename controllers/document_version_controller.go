@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// versionedDocument — ambil dokumen (surat atau dokumen staff) yang riwayatnya diminta
+// dan pastikan user boleh melakukan aksi. Mengembalikan pointer model agar bisa dipakai untuk Updates.
+func versionedDocument(c *gin.Context, docType, id, action string) (interface{}, bool) {
+	switch docType {
+	case services.VersionTypeDocument:
+		var d models.Document
+		if err := config.DB.First(&d, "id = ?", id).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen tidak ditemukan"})
+			return nil, false
+		}
+		return &d, authorizeDocument(c, services.DocumentRefOf(d), action)
+	default:
+		var d models.DocumentStaff
+		if err := config.DB.First(&d, "id = ?", id).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen tidak ditemukan"})
+			return nil, false
+		}
+		return &d, authorizeDocument(c, services.DocumentStaffRefOf(d), action)
+	}
+}
+
+func versionedRef(document interface{}) services.DocumentRef {
+	if d, ok := document.(*models.Document); ok {
+		return services.DocumentRefOf(*d)
+	}
+	return services.DocumentStaffRefOf(*document.(*models.DocumentStaff))
+}
+
+func findVersion(c *gin.Context, docType string) (models.DocumentVersion, bool) {
+	return findVersionNumber(c, docType, c.Param("id"), c.Param("version"))
+}
+
+func findVersionNumber(c *gin.Context, docType, documentID, raw string) (models.DocumentVersion, bool) {
+	var version models.DocumentVersion
+
+	number, err := strconv.Atoi(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nomor versi tidak valid"})
+		return version, false
+	}
+
+	if err := config.DB.
+		Where("document_type = ? AND document_id = ? AND version = ?", docType, documentID, number).
+		First(&version).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Versi tidak ditemukan"})
+		return version, false
+	}
+
+	return version, true
+}
+
+func listVersions(c *gin.Context, docType string) {
+	id := c.Param("id")
+
+	if _, ok := versionedDocument(c, docType, id, services.DocActionView); !ok {
+		return
+	}
+
+	var versions []models.DocumentVersion
+	if err := config.DB.
+		Preload("UploadedBy", selectUserSummary).
+		Where("document_type = ? AND document_id = ?", docType, id).
+		Order("version DESC").
+		Find(&versions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil riwayat versi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"versions": versions,
+		"total":    len(versions),
+	})
+}
+
+func downloadVersion(c *gin.Context, docType string) {
+	document, ok := versionedDocument(c, docType, c.Param("id"), services.DocActionDownload)
+	if !ok {
+		return
+	}
+
+	version, ok := findVersion(c, docType)
+	if !ok {
+		return
+	}
+
+	sendDocumentFile(c, versionedRef(document), &version.Version, versionFile(version), services.DownloadViaDirect)
+}
+
+func restoreVersion(c *gin.Context, docType string) {
+	user := c.MustGet("user").(models.User)
+
+	old, ok := findVersion(c, docType)
+	if !ok {
+		return
+	}
+
+	document, ok := versionedDocument(c, docType, old.DocumentID, services.DocActionEdit)
+	if !ok {
+		return
+	}
+
+	// dokumen staf yang sudah diputuskan kembali ke review karena file berganti
+	staff, isStaff := document.(*models.DocumentStaff)
+	resubmitted := false
+
+	var restored models.DocumentVersion
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		restored, err = services.RestoreVersion(tx, old, user.ID)
+		if err != nil {
+			return err
+		}
+
+		if isStaff {
+			note := "Memulihkan versi " + strconv.Itoa(old.Version)
+			if resubmitted, err = services.ResubmitForReview(tx, *staff, user, note); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(document).Updates(map[string]interface{}{
+			"file_name":     old.FileName,
+			"file_url":      old.FileURL,
+			"public_id":     old.PublicID,
+			"resource_type": old.ResourceType,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memulihkan versi"})
+		return
+	}
+
+	services.QueueExtraction(docType, old.DocumentID)
+	if resubmitted {
+		staff.ReviewStatus = services.ReviewUnderReview
+		services.NotifyRevisionUploaded(*staff, user)
+	}
+
+	services.CreateActivity(
+		user.ID,
+		user.Name,
+		"update",
+		"Memulihkan versi "+strconv.Itoa(old.Version)+" dokumen: "+old.FileName,
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Versi berhasil dipulihkan",
+		"version":  restored,
+		"document": document,
+	})
+}
+
+// =======================
+// DOCUMENT (SURAT) VERSIONS
+// =======================
+func GetDocumentVersions(c *gin.Context) {
+	listVersions(c, services.VersionTypeDocument)
+}
+
+func DownloadDocumentVersion(c *gin.Context) {
+	downloadVersion(c, services.VersionTypeDocument)
+}
+
+func RestoreDocumentVersion(c *gin.Context) {
+	restoreVersion(c, services.VersionTypeDocument)
+}
+
+// =======================
+// DOCUMENT STAFF VERSIONS
+// =======================
+func GetDocumentStaffVersions(c *gin.Context) {
+	listVersions(c, services.VersionTypeDocumentStaff)
+}
+
+func DownloadDocumentStaffVersion(c *gin.Context) {
+	downloadVersion(c, services.VersionTypeDocumentStaff)
+}
+
+func RestoreDocumentStaffVersion(c *gin.Context) {
+	restoreVersion(c, services.VersionTypeDocumentStaff)
+}
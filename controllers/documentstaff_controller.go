@@ -3,18 +3,59 @@ package controllers
 import (
 	"bytes"
 	"io"
+	"mime/multipart"
 	"net/http"
-	"path/filepath"
-	"strconv"
-	"strings"
 
 	"dinsos_kuburaya/config"
 	"dinsos_kuburaya/models"
 	"dinsos_kuburaya/services"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// uploadStaffFile — unggah file dokumen staf ke storage; respons error sudah dikirim bila gagal
+func uploadStaffFile(c *gin.Context, fileHeader *multipart.FileHeader) (services.VersionFile, bool) {
+	var stored services.VersionFile
+
+	// 1. BUKA FILE
+	src, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Tidak dapat membuka file"})
+		return stored, false
+	}
+	defer src.Close()
+
+	// 2. BACA KE BUFFER
+	fileBytes, err := io.ReadAll(src)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membaca file buffer"})
+		return stored, false
+	}
+	reader := bytes.NewReader(fileBytes)
+
+	resourceType, folder, ok := staffUploadTarget(fileHeader.Filename)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format file tidak didukung"})
+		return stored, false
+	}
+
+	// 3. UPLOAD KE STORAGE
+	uploadResult, err := config.FileStorage.Upload(reader, fileHeader.Filename, folder, resourceType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Upload gagal: " + err.Error()})
+		return stored, false
+	}
+
+	stored = services.VersionFile{
+		FileName:     fileHeader.Filename,
+		FileURL:      uploadResult.URL,
+		PublicID:     uploadResult.PublicID,
+		ResourceType: resourceType,
+	}
+	return stored, true
+}
+
 // ======================================================
 // CREATE STAFF DOCUMENT
 // ======================================================
@@ -39,61 +80,45 @@ func CreateDocumentStaff(c *gin.Context) {
 		return
 	}
 
-	// 1. BUKA FILE
-	src, err := fileHeader.Open()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Tidak dapat membuka file"})
-		return
-	}
-	defer src.Close()
-
-	// 2. BACA KE BUFFER
-	fileBytes, err := io.ReadAll(src)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membaca file buffer"})
-		return
-	}
-	reader := bytes.NewReader(fileBytes)
-
-	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
-	var resourceType string
-	var folder string
-
-	switch ext {
-	case ".jpg", ".jpeg", ".png", ".gif", ".webp":
-		resourceType = "image"
-		folder = "gambar"
-	case ".pdf", ".doc", ".docx", ".xls", ".xlsx", ".ppt", ".pptx":
-		resourceType = "raw"
-		folder = "document_staff"
-	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Format file tidak didukung"})
-		return
+	// unggahan untuk permintaan pengumpulan (opsional)
+	var submission *models.SubmissionRequest
+	if requestID := c.PostForm("submission_request_id"); requestID != "" {
+		request, err := services.CheckSubmissionUpload(requestID, user)
+		if err != nil {
+			submissionError(c, err, "Gagal memeriksa permintaan pengumpulan")
+			return
+		}
+		submission = &request
 	}
 
-	// 3. UPLOAD KE CLOUDINARY
-	uploadResult, err := config.UploadToCloudinary(reader, fileHeader.Filename, folder, resourceType)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Upload gagal: " + err.Error()})
+	stored, ok := uploadStaffFile(c, fileHeader)
+	if !ok {
 		return
 	}
 
-	// 4. SIMPAN DB
+	// 4. SIMPAN DB — dokumen menjadi milik unit pengunggah
 	document := models.DocumentStaff{
 		UserID:       user.ID,
+		UnitID:       user.UnitID,
 		Subject:      subject,
-		FileName:     fileHeader.Filename,
-		FileURL:      uploadResult.SecureURL,
-		PublicID:     uploadResult.PublicID,
-		ResourceType: resourceType,
+		FileName:     stored.FileName,
+		FileURL:      stored.FileURL,
+		PublicID:     stored.PublicID,
+		ResourceType: stored.ResourceType,
+		ReviewStatus: services.ReviewSubmitted,
+	}
+	if submission != nil {
+		document.SubmissionRequestID = &submission.ID
 	}
 
 	if err := config.DB.Create(&document).Error; err != nil {
-		config.DeleteFromCloudinary(uploadResult.PublicID, resourceType)
+		config.FileStorage.Delete(stored.PublicID, stored.ResourceType)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
 		return
 	}
 
+	services.QueueExtraction(services.VersionTypeDocumentStaff, document.ID)
+
 	config.DB.Preload("User").Find(&document)
 
 	services.CreateActivity(
@@ -103,10 +128,15 @@ func CreateDocumentStaff(c *gin.Context) {
 		"Mengunggah dokumen staff: "+document.FileName,
 	)
 
-	services.NotifyAdmins(
-		"Dokumen baru dari "+user.Name,
-		document.FileURL,
-	)
+	if submission != nil {
+		services.NotifySubmissionReceived(*submission, user)
+	} else {
+		services.NotifyAdmins(
+			services.PermStaffDocReview,
+			"Dokumen baru dari "+user.Name,
+			"/document_staff/"+document.ID,
+		)
+	}
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message":  "Dokumen berhasil diupload",
@@ -115,19 +145,38 @@ func CreateDocumentStaff(c *gin.Context) {
 }
 
 // ======================================================
-// GET ALL STAFF DOCUMENTS - DIPERBAIKI
+// GET ALL STAFF DOCUMENTS
 // ======================================================
+var documentStaffListSpec = listSpec{
+	Table: "document_staffs",
+	Sorts: map[string]listSort{
+		"created_at": {Column: "created_at"},
+		"updated_at": {Column: "updated_at"},
+		"subject":    {Column: "subject"},
+		"file_name":  {Column: "file_name"},
+	},
+	DefaultSort:    "created_at",
+	DefaultOrder:   "desc",
+	DateColumn:     "created_at",
+	DefaultPerPage: 10,
+}
+
 func GetDocumentStaffs(c *gin.Context) {
-	var documents []models.DocumentStaff
+	list, ok := parseListQuery(c, documentStaffListSpec)
+	if !ok {
+		return
+	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
 	search := c.Query("search")
 	userFilter := c.Query("user_id")
 
 	query := config.DB.Model(&models.DocumentStaff{}).
 		Joins("LEFT JOIN users AS User ON document_staffs.user_id = User.id")
 
+	// tanpa izin staffdoc.view_all_units hanya dokumen milik sendiri dan unit yang terlihat
+	user := c.MustGet("user").(models.User)
+	query = services.DocumentStaffAccess(user).ScopeDocumentStaffs(query)
+
 	if search != "" {
 		searchQuery := "%" + search + "%"
 		query = query.Where(
@@ -140,69 +189,42 @@ func GetDocumentStaffs(c *gin.Context) {
 		query = query.Where("document_staffs.user_id = ?", userFilter)
 	}
 
-	var total int64
-	countQuery := config.DB.Model(&models.DocumentStaff{}).
-		Joins("LEFT JOIN users AS User ON document_staffs.user_id = User.id")
-
-	if search != "" {
-		searchQuery := "%" + search + "%"
-		countQuery = countQuery.Where(
-			"document_staffs.subject LIKE ? OR document_staffs.file_name LIKE ? OR User.name LIKE ?",
-			searchQuery, searchQuery, searchQuery,
-		)
+	reviewStatus, ok := reviewStatusFilter(c)
+	if !ok {
+		return
 	}
-
-	if userFilter != "" && userFilter != "all" {
-		countQuery = countQuery.Where("document_staffs.user_id = ?", userFilter)
+	if reviewStatus != "" {
+		query = query.Where("document_staffs.review_status = ?", reviewStatus)
 	}
 
-	if err := countQuery.Count(&total).Error; err != nil {
-		total = 0
+	if requestID := c.Query("submission_request_id"); requestID != "" {
+		query = query.Where("document_staffs.submission_request_id = ?", requestID)
 	}
 
-	offset := (page - 1) * perPage
-	err := query.
-		Select("document_staffs.*").
-		Offset(offset).
-		Limit(perPage).
-		Order("document_staffs.created_at DESC").
-		Preload("User").
-		Find(&documents).Error
+	if unitID := c.Query("unit_id"); unitID != "" && unitID != "all" {
+		if c.Query("include_subunits") == "true" {
+			query = query.Where("document_staffs.unit_id IN ?", services.SubtreeUnitIDs(unitID))
+		} else {
+			query = query.Where("document_staffs.unit_id = ?", unitID)
+		}
+	}
 
+	var documents []models.DocumentStaff
+	meta, err := list.find(query, &documents, selectColumns("document_staffs.*"), preload("User"), preload("Employee"), preload("Unit", selectUnitSummary))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil dokumen"})
 		return
 	}
 
-	lastPage := 0
-	if perPage > 0 {
-		lastPage = int(total) / perPage
-		if int(total)%perPage != 0 {
-			lastPage++
-		}
-	}
-	if lastPage == 0 && total > 0 {
-		lastPage = 1
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"documents":    documents,
-		"total":        total,
-		"current_page": page,
-		"last_page":    lastPage,
-		"per_page":     perPage,
-	})
+	c.JSON(http.StatusOK, listResponse(documents, meta))
 }
 
 // ======================================================
 // GET BY ID
 // ======================================================
 func GetDocumentStaffByID(c *gin.Context) {
-	id := c.Param("id")
-	var document models.DocumentStaff
-
-	if err := config.DB.Preload("User").First(&document, "id = ?", id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen tidak ditemukan"})
+	document, ok := findDocumentStaff(c, config.DB.Preload("User").Preload("Employee").Preload("Unit", selectUnitSummary), services.DocActionView)
+	if !ok {
 		return
 	}
 
@@ -220,30 +242,39 @@ func GetPersonalDocumentStaffs(c *gin.Context) {
 	}
 	user := userRaw.(models.User)
 
-	var documents []models.DocumentStaff
+	list, ok := parseListQuery(c, documentStaffListSpec)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.DocumentStaff{}).Where("document_staffs.user_id = ?", user.ID)
+
+	reviewStatus, ok := reviewStatusFilter(c)
+	if !ok {
+		return
+	}
+	if reviewStatus != "" {
+		query = query.Where("document_staffs.review_status = ?", reviewStatus)
+	}
 
-	if err := config.DB.Where("user_id = ?", user.ID).
-		Order("created_at DESC").
-		Find(&documents).Error; err != nil {
+	var documents []models.DocumentStaff
+	meta, err := list.find(query, &documents)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil dokumen pribadi"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"documents": documents,
-		"total":     len(documents),
-	})
+	c.JSON(http.StatusOK, listResponse(documents, meta))
 }
 
 // ======================================================
 // UPDATE STAFF DOCUMENT
 // ======================================================
 func UpdateDocumentStaff(c *gin.Context) {
-	id := c.Param("id")
-	var document models.DocumentStaff
+	user := c.MustGet("user").(models.User)
 
-	if err := config.DB.First(&document, "id = ?", id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen tidak ditemukan"})
+	document, ok := findDocumentStaff(c, config.DB, services.DocActionEdit)
+	if !ok {
 		return
 	}
 
@@ -255,72 +286,93 @@ func UpdateDocumentStaff(c *gin.Context) {
 		updates["subject"] = subject
 	}
 
-	fileHeader, err := c.FormFile("file")
-	if err == nil {
-		src, err := fileHeader.Open()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Tidak dapat membuka file"})
+	// pindah unit pemilik hanya oleh pemegang staffdoc.manage_all
+	if unitID := c.PostForm("unit_id"); unitID != "" {
+		if !services.HasPermission(user, services.PermStaffDocManageAll) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Tidak boleh memindahkan dokumen ke unit lain"})
 			return
 		}
-		defer src.Close()
-
-		fileBytes, err := io.ReadAll(src)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membaca file buffer"})
-			return
-		}
-		reader := bytes.NewReader(fileBytes)
-
-		ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
-		var resourceType string
-		var folder string
-
-		switch ext {
-		case ".jpg", ".jpeg", ".png", ".gif", ".webp":
-			resourceType = "image"
-			folder = "gambar"
-		case ".pdf", ".doc", ".docx", ".xls", ".xlsx", ".ppt", ".pptx":
-			resourceType = "raw"
-			folder = "document_staff"
-		default:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Format file tidak didukung"})
+		if _, err := services.FindUnit(unitID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		updates["unit_id"] = unitID
+	}
 
-		uploadResult, err := config.UploadToCloudinary(reader, fileHeader.Filename, folder, resourceType)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Upload gagal: " + err.Error()})
+	var newFile *services.VersionFile
+
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		// file lama tidak dihapus, tetapi disimpan sebagai riwayat versi
+		stored, ok := uploadStaffFile(c, fileHeader)
+		if !ok {
 			return
 		}
+		newFile = &stored
 
-		if document.PublicID != "" {
-			config.DeleteFromCloudinary(document.PublicID, document.ResourceType)
+		updates["file_name"] = newFile.FileName
+		updates["file_url"] = newFile.FileURL
+		updates["public_id"] = newFile.PublicID
+		updates["resource_type"] = newFile.ResourceType
+	}
+
+	resubmitted := false
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if newFile != nil {
+			current := services.VersionFile{
+				FileName:     document.FileName,
+				FileURL:      document.FileURL,
+				PublicID:     document.PublicID,
+				ResourceType: document.ResourceType,
+			}
+
+			var ownerID *string
+			if document.UserID != "" {
+				ownerID = &document.UserID
+			}
+
+			if _, err := services.RecordNewVersion(
+				tx, services.VersionTypeDocumentStaff, document.ID,
+				current, ownerID, *newFile, user.ID, c.PostForm("change_note"),
+			); err != nil {
+				return err
+			}
+
+			// revisi diunggah → kembali ke review
+			var err error
+			if resubmitted, err = services.ResubmitForReview(tx, document, user, c.PostForm("change_note")); err != nil {
+				return err
+			}
 		}
 
-		updates["file_name"] = fileHeader.Filename
-		updates["file_url"] = uploadResult.SecureURL
-		updates["public_id"] = uploadResult.PublicID
-		updates["resource_type"] = resourceType
+		if len(updates) > 0 {
+			return tx.Model(&document).Updates(updates).Error
+		}
+		return nil
+	})
+	if err != nil {
+		if newFile != nil {
+			config.FileStorage.Delete(newFile.PublicID, newFile.ResourceType)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan perubahan"})
+		return
 	}
 
-	if len(updates) > 0 {
-		if err := config.DB.Model(&document).Updates(updates).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan perubahan"})
-			return
-		}
+	// file baru → isi teks diekstrak ulang
+	if newFile != nil {
+		services.QueueExtraction(services.VersionTypeDocumentStaff, document.ID)
+	}
+	if resubmitted {
+		services.NotifyRevisionUploaded(document, user)
 	}
 
 	config.DB.Preload("User").Find(&document)
 
-	if userRaw, ok := c.Get("user"); ok {
-		user := userRaw.(models.User)
-		services.CreateActivity(
-			user.ID,
-			user.Name,
-			"update",
-			"Memperbarui dokumen staff: "+document.FileName,
-		)
-	}
+	services.CreateActivity(
+		user.ID,
+		user.Name,
+		"update",
+		"Memperbarui dokumen staff: "+document.FileName,
+	)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":  "Dokumen berhasil diperbarui",
@@ -332,46 +384,37 @@ func UpdateDocumentStaff(c *gin.Context) {
 // DELETE
 // ======================================================
 func DeleteDocumentStaff(c *gin.Context) {
-	id := c.Param("id")
-	var document models.DocumentStaff
+	user := c.MustGet("user").(models.User)
 
-	if err := config.DB.First(&document, "id = ?", id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen tidak ditemukan"})
+	document, ok := findDocumentStaff(c, config.DB, services.DocActionDelete)
+	if !ok {
 		return
 	}
 
-	config.DeleteFromCloudinary(document.PublicID, document.ResourceType)
-	config.DB.Delete(&document)
-
-	if userRaw, ok := c.Get("user"); ok {
-		user := userRaw.(models.User)
-		services.CreateActivity(
-			user.ID,
-			user.Name,
-			"delete",
-			"Menghapus dokumen staff: "+document.FileName,
-		)
+	// soft delete — file baru dihapus permanen saat trash di-purge
+	if err := softDelete(&document, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus dokumen"})
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Dokumen berhasil dihapus"})
+	services.CreateActivity(
+		user.ID,
+		user.Name,
+		"delete",
+		"Memindahkan dokumen staff ke trash: "+document.FileName,
+	)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Dokumen dipindahkan ke trash"})
 }
 
 // ======================================================
-// DOWNLOAD (Redirect)
+// DOWNLOAD (dialirkan lewat backend)
 // ======================================================
 func DownloadDocumentStaff(c *gin.Context) {
-	id := c.Param("id")
-	var document models.DocumentStaff
-
-	if err := config.DB.First(&document, "id = ?", id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen tidak ditemukan"})
-		return
-	}
-
-	if document.FileURL == "" {
-		c.JSON(http.StatusNotFound, gin.H{"error": "File tidak tersedia"})
+	document, ok := findDocumentStaff(c, config.DB, services.DocActionDownload)
+	if !ok {
 		return
 	}
 
-	c.Redirect(http.StatusTemporaryRedirect, document.FileURL)
+	sendDocumentFile(c, services.DocumentStaffRefOf(document), nil, documentStaffFile(document), services.DownloadViaDirect)
 }
@@ -0,0 +1,309 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmployeeRequest struct {
+	NIP      *string `json:"nip"`
+	Name     *string `json:"name"`
+	Position *string `json:"position"`
+	Rank     *string `json:"rank"`
+	Grade    *string `json:"grade"`
+	Status   *string `json:"status"`
+	UnitID   *string `json:"unit_id"`
+}
+
+func (r EmployeeRequest) input() services.EmployeeInput {
+	return services.EmployeeInput{
+		NIP:      r.NIP,
+		Name:     r.Name,
+		Position: r.Position,
+		Rank:     r.Rank,
+		Grade:    r.Grade,
+		Status:   r.Status,
+		UnitID:   r.UnitID,
+	}
+}
+
+// EmployeeResponse — pegawai beserta jumlah berkas kepegawaiannya
+type EmployeeResponse struct {
+	models.Employee
+	DocumentCount int64 `json:"document_count"`
+}
+
+func employeeResponses(employees []models.Employee) ([]EmployeeResponse, error) {
+	ids := make([]string, 0, len(employees))
+	for _, e := range employees {
+		ids = append(ids, e.ID)
+	}
+
+	counts, err := services.EmployeeDocumentCounts(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]EmployeeResponse, 0, len(employees))
+	for _, e := range employees {
+		items = append(items, EmployeeResponse{Employee: e, DocumentCount: counts[e.ID]})
+	}
+	return items, nil
+}
+
+func employeeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrEmployeeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrEmployeeNIPExists),
+		errors.Is(err, services.ErrEmployeeHasDocuments):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrEmployeeNIPInvalid),
+		errors.Is(err, services.ErrEmployeeNameRequired),
+		errors.Is(err, services.ErrEmployeeStatus),
+		errors.Is(err, services.ErrEmployeeUnitNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// =======================
+// DAFTAR & DETAIL PEGAWAI
+// =======================
+var employeeListSpec = listSpec{
+	Table: "employees",
+	Sorts: map[string]listSort{
+		"name":       {Column: "name"},
+		"nip":        {Column: "nip"},
+		"created_at": {Column: "created_at"},
+	},
+	DefaultSort:  "name",
+	DefaultOrder: "asc",
+}
+
+func GetEmployees(c *gin.Context) {
+	list, ok := parseListQuery(c, employeeListSpec)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.Employee{})
+
+	if search := c.Query("search"); search != "" {
+		searchQuery := "%" + search + "%"
+		query = query.Where("employees.name LIKE ? OR employees.nip LIKE ? OR employees.position LIKE ?", searchQuery, searchQuery, searchQuery)
+	}
+
+	if status := c.Query("status"); status != "" && status != "all" {
+		query = query.Where("employees.status = ?", status)
+	}
+
+	if unitID := c.Query("unit_id"); unitID != "" && unitID != "all" {
+		if c.Query("include_subunits") == "true" {
+			query = query.Where("employees.unit_id IN ?", services.SubtreeUnitIDs(unitID))
+		} else {
+			query = query.Where("employees.unit_id = ?", unitID)
+		}
+	}
+
+	var employees []models.Employee
+	meta, err := list.find(query, &employees, preload("Unit", selectUnitSummary))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil daftar pegawai"})
+		return
+	}
+
+	items, err := employeeResponses(employees)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung berkas pegawai"})
+		return
+	}
+
+	c.JSON(http.StatusOK, listResponse(items, meta))
+}
+
+func GetEmployee(c *gin.Context) {
+	var employee models.Employee
+	if err := config.DB.Preload("Unit", selectUnitSummary).First(&employee, "id = ?", c.Param("id")).Error; err != nil {
+		employeeError(c, services.ErrEmployeeNotFound, "")
+		return
+	}
+
+	items, err := employeeResponses([]models.Employee{employee})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung berkas pegawai"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"employee": items[0]})
+}
+
+// =======================
+// KELOLA PEGAWAI
+// =======================
+func CreateEmployee(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req EmployeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	employee, err := services.CreateEmployee(req.input())
+	if err != nil {
+		employeeError(c, err, "Gagal menambahkan pegawai")
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "create", "Menambahkan pegawai: "+employee.Name+" ("+employee.NIP+")")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Pegawai berhasil ditambahkan",
+		"employee": employee,
+	})
+}
+
+func UpdateEmployee(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req EmployeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	employee, err := services.UpdateEmployee(c.Param("id"), req.input())
+	if err != nil {
+		employeeError(c, err, "Gagal memperbarui pegawai")
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "update", "Memperbarui pegawai: "+employee.Name+" ("+employee.NIP+")")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Pegawai berhasil diperbarui",
+		"employee": employee,
+	})
+}
+
+func DeleteEmployee(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	employee, err := services.DeleteEmployee(c.Param("id"))
+	if err != nil {
+		employeeError(c, err, "Gagal menghapus pegawai")
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "delete", "Menghapus pegawai: "+employee.Name+" ("+employee.NIP+")")
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pegawai berhasil dihapus"})
+}
+
+// =======================
+// BERKAS KEPEGAWAIAN (MAP DIGITAL PER PEGAWAI)
+// ubah, hapus, unduh & riwayat versi berkas memakai endpoint /document_staff/:id
+// =======================
+func GetEmployeeDocuments(c *gin.Context) {
+	employee, err := services.FindEmployee(c.Param("id"))
+	if err != nil {
+		employeeError(c, err, "Gagal mengambil pegawai")
+		return
+	}
+
+	list, ok := parseListQuery(c, documentStaffListSpec)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.DocumentStaff{}).Where("document_staffs.employee_id = ?", employee.ID)
+	if search := c.Query("search"); search != "" {
+		searchQuery := "%" + search + "%"
+		query = query.Where("document_staffs.subject LIKE ? OR document_staffs.file_name LIKE ?", searchQuery, searchQuery)
+	}
+
+	var documents []models.DocumentStaff
+	meta, err := list.find(query, &documents)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil berkas pegawai"})
+		return
+	}
+
+	response := listResponse(documents, meta)
+	response["employee"] = employee
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateEmployeeDocument — unggah berkas kepegawaian (multipart: subject, file).
+// Berkas diunggah pengelola sehingga tidak melalui alur review.
+func CreateEmployeeDocument(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	employee, err := services.FindEmployee(c.Param("id"))
+	if err != nil {
+		employeeError(c, err, "Gagal mengambil pegawai")
+		return
+	}
+
+	subject := c.PostForm("subject")
+	if subject == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Subject wajib diisi"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File tidak ditemukan"})
+		return
+	}
+
+	stored, ok := uploadStaffFile(c, fileHeader)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	document := models.DocumentStaff{
+		EmployeeID:   employee.ID,
+		UnitID:       employee.UnitID,
+		Subject:      subject,
+		FileName:     stored.FileName,
+		FileURL:      stored.FileURL,
+		PublicID:     stored.PublicID,
+		ResourceType: stored.ResourceType,
+		ReviewStatus: services.ReviewApproved,
+		ReviewedByID: &user.ID,
+		ReviewedAt:   &now,
+	}
+
+	if err := config.DB.Create(&document).Error; err != nil {
+		config.FileStorage.Delete(stored.PublicID, stored.ResourceType)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+		return
+	}
+
+	services.QueueExtraction(services.VersionTypeDocumentStaff, document.ID)
+
+	document.Employee = &employee
+
+	services.CreateActivity(
+		user.ID,
+		user.Name,
+		"create",
+		"Mengunggah berkas kepegawaian "+employee.Name+" ("+employee.NIP+"): "+document.FileName,
+	)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Berkas pegawai berhasil diupload",
+		"document": document,
+	})
+}
@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportRequest — ids opsional; bila kosong dipakai filter query string yang sama dengan GET /documents
+type ExportRequest struct {
+	IDs []string `json:"ids"`
+}
+
+func exportError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrExportNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrExportNotReady):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrExportRunning):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// exportDocumentIDs — ID surat yang boleh diunduh user, sesuai daftar ids atau filter
+func exportDocumentIDs(c *gin.Context, user models.User, req ExportRequest) ([]string, bool) {
+	access := services.DocumentAccess(user)
+	query := access.ScopeDocuments(config.DB.Model(&models.Document{}))
+
+	if len(req.IDs) > 0 {
+		query = query.Where("documents.id IN ?", req.IDs).Order("documents.created_at DESC")
+	} else {
+		list, ok := parseListQuery(c, documentListSpec)
+		if !ok {
+			return nil, false
+		}
+		if query, ok = filterDocuments(c, query); !ok {
+			return nil, false
+		}
+		query = list.applyFilters(query).
+			Order(list.column(documentListSpec.Sorts[list.Sort].Column) + " " + list.Order)
+	}
+
+	var candidates []models.Document
+	if err := query.Select("documents.id", "documents.user_id", "documents.unit_id", "documents.file_name").
+		Limit(services.ExportMaxFiles() + 1).
+		Find(&candidates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data dokumen"})
+		return nil, false
+	}
+	if len(candidates) > services.ExportMaxFiles() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": services.ErrExportTooLarge.Error() + " (" + strconv.Itoa(services.ExportMaxFiles()) + "), persempit filter",
+		})
+		return nil, false
+	}
+
+	// cakupan daftar juga memuat surat yang hanya boleh dilihat (share view, disposisi);
+	// yang masuk ekspor hanya surat yang boleh diunduh
+	unrestricted := access.All && services.HasPermission(user, services.PermDocumentDownload)
+
+	ids := make([]string, 0, len(candidates))
+	for _, d := range candidates {
+		if unrestricted || services.AuthorizeDocument(user, services.DocumentRefOf(d), services.DocActionDownload) {
+			ids = append(ids, d.ID)
+		}
+	}
+	if len(ids) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrExportEmpty.Error()})
+		return nil, false
+	}
+	return ids, true
+}
+
+// =======================
+// EKSPOR ZIP SURAT
+// kecil: langsung dialirkan; besar (atau ?async=true): job background + progres lewat WebSocket
+// =======================
+func ExportDocuments(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req ExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ids, ok := exportDocumentIDs(c, user, req)
+	if !ok {
+		return
+	}
+
+	async, _ := strconv.ParseBool(c.Query("async"))
+	if async || len(ids) > services.ExportSyncLimit() {
+		job, err := services.StartDocumentExport(user, ids, c.ClientIP(), c.Request.UserAgent())
+		if err != nil {
+			exportError(c, err, "Gagal memulai ekspor")
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "Ekspor diproses di background, progres dikirim lewat WebSocket",
+			"job":     job,
+		})
+		return
+	}
+
+	documents, err := services.LoadExportDocuments(ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data dokumen"})
+		return
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "application/zip")
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": services.ExportArchiveName(time.Now()),
+	}))
+	header.Set("Cache-Control", "private, no-store")
+	c.Status(http.StatusOK)
+
+	// header sudah terkirim; kegagalan di tengah hanya bisa dicatat
+	if _, err := services.WriteDocumentArchive(c.Writer, documents, nil); err != nil {
+		c.Error(err)
+		return
+	}
+
+	services.RecordExportDownloads(user, documents, c.ClientIP(), c.Request.UserAgent())
+}
+
+// =======================
+// JOB EKSPOR MILIK USER
+// =======================
+func GetExportJobs(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	jobs, err := services.UserExportJobs(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil daftar ekspor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"exports": jobs,
+		"total":   len(jobs),
+	})
+}
+
+func GetExportJob(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	job, err := services.FindExportJob(user.ID, c.Param("id"))
+	if err != nil {
+		exportError(c, err, "Gagal mengambil ekspor")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"export": job})
+}
+
+func DownloadExport(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	job, err := services.FindExportJob(user.ID, c.Param("id"))
+	if err != nil {
+		exportError(c, err, "Gagal mengambil ekspor")
+		return
+	}
+
+	file, err := services.OpenExportArchive(job)
+	if err != nil {
+		exportError(c, err, "Gagal membuka file ekspor")
+		return
+	}
+	defer file.Close()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "application/zip")
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": job.FileName}))
+	header.Set("Cache-Control", "private, no-store")
+
+	http.ServeContent(c.Writer, c.Request, "", time.Time{}, file)
+}
+
+func DeleteExport(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	job, err := services.FindExportJob(user.ID, c.Param("id"))
+	if err != nil {
+		exportError(c, err, "Gagal mengambil ekspor")
+		return
+	}
+	if job.Status == services.ExportPending || job.Status == services.ExportRunning {
+		exportError(c, services.ErrExportRunning, "")
+		return
+	}
+
+	if err := services.DeleteExportJob(job); err != nil {
+		exportError(c, err, "Gagal menghapus ekspor")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ekspor berhasil dihapus"})
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"net/http"
+
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func getExtractedContent(c *gin.Context, docType string) {
+	if _, ok := versionedDocument(c, docType, c.Param("id"), services.DocActionView); !ok {
+		return
+	}
+
+	content, err := services.ExtractedContent(docType, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen tidak ditemukan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, content)
+}
+
+// reextract — antrekan ulang ekstraksi, mis. setelah OCR gagal atau tesseract baru dipasang
+func reextract(c *gin.Context, docType string) {
+	user := c.MustGet("user").(models.User)
+
+	document, ok := versionedDocument(c, docType, c.Param("id"), services.DocActionEdit)
+	if !ok {
+		return
+	}
+
+	if err := services.QueueExtraction(docType, c.Param("id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengantrekan ekstraksi teks"})
+		return
+	}
+
+	fileName := ""
+	switch d := document.(type) {
+	case *models.Document:
+		fileName = d.FileName
+	case *models.DocumentStaff:
+		fileName = d.FileName
+	}
+	services.CreateActivity(user.ID, user.Name, "update", "Mengantrekan ulang ekstraksi teks: "+fileName)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":           "Ekstraksi teks diantrekan",
+		"extraction_status": services.ExtractionPending,
+	})
+}
+
+// =======================
+// ISI TEKS DOKUMEN (SURAT)
+// =======================
+func GetDocumentContent(c *gin.Context) {
+	getExtractedContent(c, services.VersionTypeDocument)
+}
+
+func ReextractDocument(c *gin.Context) {
+	reextract(c, services.VersionTypeDocument)
+}
+
+// =======================
+// ISI TEKS DOKUMEN STAFF
+// =======================
+func GetDocumentStaffContent(c *gin.Context) {
+	getExtractedContent(c, services.VersionTypeDocumentStaff)
+}
+
+func ReextractDocumentStaff(c *gin.Context) {
+	reextract(c, services.VersionTypeDocumentStaff)
+}
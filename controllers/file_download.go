@@ -0,0 +1,358 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =======================
+// UNDUHAN FILE LEWAT BACKEND
+// file tidak pernah diarahkan ke URL storage; selalu dialirkan setelah hak akses diperiksa
+// =======================
+
+const downloadPath = "/api/downloads/"
+
+// storedFile — file di storage yang akan dialirkan ke klien
+type storedFile struct {
+	Name         string
+	PublicID     string
+	ResourceType string
+}
+
+func documentFile(d models.Document) storedFile {
+	return storedFile{d.FileName, d.PublicID, d.ResourceType}
+}
+
+func documentStaffFile(d models.DocumentStaff) storedFile {
+	return storedFile{d.FileName, d.PublicID, d.ResourceType}
+}
+
+func versionFile(v models.DocumentVersion) storedFile {
+	return storedFile{v.FileName, v.PublicID, v.ResourceType}
+}
+
+// inlineAllowed — hanya PDF dan gambar raster yang boleh ditampilkan langsung di browser;
+// tipe lain (HTML, SVG, ...) selalu diunduh agar tidak dieksekusi di origin aplikasi
+func inlineAllowed(contentType string) bool {
+	return contentType == "application/pdf" ||
+		(strings.HasPrefix(contentType, "image/") && contentType != "image/svg+xml")
+}
+
+// countsAsDownload — permintaan Range lanjutan (resume / seek) tidak dicatat ulang
+func countsAsDownload(r *http.Request) bool {
+	rangeHeader := r.Header.Get("Range")
+	return rangeHeader == "" || strings.HasPrefix(rangeHeader, "bytes=0-")
+}
+
+// openStoredFile — buka file dari storage. Request ber-Range ke backend remote diteruskan
+// apa adanya (HTTP Range GET); partial berisi jawaban 206/416 yang tinggal direlay.
+func openStoredFile(file storedFile, rangeHeader string) (io.ReadCloser, *config.RangeResult, error) {
+	if ranged, ok := config.FileStorage.(config.RangeOpener); ok && rangeHeader != "" {
+		res, err := ranged.OpenRange(file.PublicID, file.ResourceType, rangeHeader)
+		if err != nil {
+			return nil, nil, err
+		}
+		if res.Status == http.StatusOK {
+			// backend mengabaikan Range: isi lengkap, ditangani seperti Open biasa
+			return res.Body, nil, nil
+		}
+		return res.Body, &res, nil
+	}
+
+	reader, err := config.FileStorage.Open(file.PublicID, file.ResourceType)
+	return reader, nil, err
+}
+
+// serveStoredFile — alirkan file dari storage dengan dukungan Range dan nama file yang benar.
+// record dipanggil setelah file berhasil dibuka, sebelum isi dikirim, hanya untuk unduhan
+// baru; bila record mengembalikan false responsnya sudah ditulis dan file tidak dikirim.
+func serveStoredFile(c *gin.Context, file storedFile, record func() bool) {
+	rangeHeader := c.GetHeader("Range")
+
+	reader, partial, err := openStoredFile(file, rangeHeader)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "File tidak tersedia"})
+		return
+	}
+	defer reader.Close()
+
+	if record != nil && countsAsDownload(c.Request) && !record() {
+		return
+	}
+
+	contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(file.Name)))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	disposition := "attachment"
+	if inline, _ := strconv.ParseBool(c.Query("inline")); inline && inlineAllowed(mediaType) {
+		disposition = "inline"
+	}
+	if value := mime.FormatMediaType(disposition, map[string]string{"filename": file.Name}); value != "" {
+		disposition = value
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Disposition", disposition)
+	header.Set("Cache-Control", "private, no-store")
+	header.Set("X-Content-Type-Options", "nosniff")
+
+	if partial != nil {
+		// potongan dari backend remote: relay status, Content-Range dan Content-Length
+		header.Set("Accept-Ranges", "bytes")
+		if partial.ContentRange != "" {
+			header.Set("Content-Range", partial.ContentRange)
+		}
+		if partial.Status == http.StatusRequestedRangeNotSatisfiable {
+			header.Del("Content-Disposition")
+			c.Status(partial.Status)
+			return
+		}
+		if partial.ContentLength >= 0 {
+			header.Set("Content-Length", strconv.FormatInt(partial.ContentLength, 10))
+		}
+		c.Status(partial.Status)
+		if c.Request.Method != http.MethodHead {
+			io.Copy(c.Writer, reader)
+		}
+		return
+	}
+
+	content, seekable := reader.(io.ReadSeeker)
+	if !seekable {
+		// storage remote: tanpa Range cukup dialirkan langsung. Range hanya ditampung
+		// ke file sementara bila backend tidak dapat melayani Range sendiri.
+		if rangeHeader == "" {
+			header.Set("Accept-Ranges", "bytes")
+			c.Status(http.StatusOK)
+			if c.Request.Method != http.MethodHead {
+				io.Copy(c.Writer, reader)
+			}
+			return
+		}
+
+		spool, err := os.CreateTemp("", "download-*")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyiapkan file"})
+			return
+		}
+		defer os.Remove(spool.Name())
+		defer spool.Close()
+
+		if _, err := io.Copy(spool, reader); err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "File tidak tersedia"})
+			return
+		}
+		content = spool
+	}
+
+	http.ServeContent(c.Writer, c.Request, "", time.Time{}, content)
+}
+
+// sendDocumentFile — catat unduhan user login lalu alirkan file
+func sendDocumentFile(c *gin.Context, ref services.DocumentRef, version *int, file storedFile, via string) {
+	user := c.MustGet("user").(models.User)
+
+	serveStoredFile(c, file, func() bool {
+		services.RecordDownload(services.DownloadEvent{
+			Ref:       ref,
+			Version:   version,
+			FileName:  file.Name,
+			User:      &user,
+			Via:       via,
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		return true
+	})
+}
+
+// downloadTarget — dokumen (dan versi opsional) yang dituju URL bertanda tangan
+func downloadTarget(target services.DownloadTarget) (services.DocumentRef, storedFile, error) {
+	var ref services.DocumentRef
+	var file storedFile
+
+	switch target.Type {
+	case services.VersionTypeDocument:
+		var d models.Document
+		if err := config.DB.First(&d, "id = ?", target.ID).Error; err != nil {
+			return ref, file, err
+		}
+		ref, file = services.DocumentRefOf(d), documentFile(d)
+	case services.VersionTypeDocumentStaff:
+		var d models.DocumentStaff
+		if err := config.DB.First(&d, "id = ?", target.ID).Error; err != nil {
+			return ref, file, err
+		}
+		ref, file = services.DocumentStaffRefOf(d), documentStaffFile(d)
+	default:
+		return ref, file, services.ErrDownloadTokenInvalid
+	}
+
+	if target.Version != nil {
+		var v models.DocumentVersion
+		if err := config.DB.
+			Where("document_type = ? AND document_id = ? AND version = ?", target.Type, target.ID, *target.Version).
+			First(&v).Error; err != nil {
+			return ref, file, err
+		}
+		file = versionFile(v)
+	}
+
+	return ref, file, nil
+}
+
+// =======================
+// URL UNDUHAN BERTANDA TANGAN
+// untuk <a href> / viewer yang tidak bisa mengirim header Authorization
+// =======================
+func issueDownloadURL(c *gin.Context, docType string) {
+	session := c.MustGet("session").(models.SecretToken)
+
+	var ref services.DocumentRef
+	var ok bool
+	if docType == services.VersionTypeDocument {
+		var document models.Document
+		document, ok = findDocument(c, config.DB, services.DocActionDownload)
+		ref = services.DocumentRefOf(document)
+	} else {
+		var document models.DocumentStaff
+		document, ok = findDocumentStaff(c, config.DB, services.DocActionDownload)
+		ref = services.DocumentStaffRefOf(document)
+	}
+	if !ok {
+		return
+	}
+
+	target := services.DownloadTarget{Type: docType, ID: ref.ID}
+	if v := c.Query("version"); v != "" {
+		version, ok := findVersionNumber(c, docType, ref.ID, v)
+		if !ok {
+			return
+		}
+		target.Version = &version.Version
+	}
+
+	token, expiresAt, err := services.SignDownload(target, session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat URL unduhan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"url":        downloadPath + token,
+		"expires_at": expiresAt,
+	})
+}
+
+func GetDocumentDownloadURL(c *gin.Context) {
+	issueDownloadURL(c, services.VersionTypeDocument)
+}
+
+func GetDocumentStaffDownloadURL(c *gin.Context) {
+	issueDownloadURL(c, services.VersionTypeDocumentStaff)
+}
+
+// =======================
+// UNDUH LEWAT URL BERTANDA TANGAN (tanpa header Authorization)
+// hak akses pemilik URL diperiksa ulang saat file diminta
+// =======================
+func DownloadSignedFile(c *gin.Context) {
+	target, user, err := services.VerifyDownload(c.Param("token"))
+	if err != nil {
+		status := http.StatusUnauthorized
+		if errors.Is(err, services.ErrDownloadTokenExpired) {
+			status = http.StatusGone
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	ref, file, err := downloadTarget(target)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen tidak ditemukan"})
+		return
+	}
+
+	if !services.AuthorizeDocument(user, ref, services.DocActionDownload) {
+		services.RecordAccessDenied(user, ref, services.DocActionDownload, "URL unduhan "+c.ClientIP())
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen tidak ditemukan"})
+		return
+	}
+
+	c.Set("user", user)
+	sendDocumentFile(c, ref, target.Version, file, services.DownloadViaSigned)
+}
+
+// =======================
+// RIWAYAT UNDUHAN
+// =======================
+var downloadListSpec = listSpec{
+	Sorts: map[string]listSort{
+		"created_at": {Column: "created_at"},
+	},
+	DefaultSort:  "created_at",
+	DefaultOrder: "desc",
+	DateColumn:   "created_at",
+}
+
+func listDownloads(c *gin.Context, docType string) {
+	var ref services.DocumentRef
+	var ok bool
+	if docType == services.VersionTypeDocument {
+		var document models.Document
+		document, ok = findDocument(c, config.DB, services.DocActionEdit)
+		ref = services.DocumentRefOf(document)
+	} else {
+		var document models.DocumentStaff
+		document, ok = findDocumentStaff(c, config.DB, services.DocActionEdit)
+		ref = services.DocumentStaffRefOf(document)
+	}
+	if !ok {
+		return
+	}
+
+	list, ok := parseListQuery(c, downloadListSpec)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.DocumentDownload{}).
+		Where("document_type = ? AND document_id = ?", ref.Type, ref.ID)
+	if via := c.Query("via"); via != "" {
+		query = query.Where("via = ?", via)
+	}
+
+	var downloads []models.DocumentDownload
+	meta, err := list.find(query, &downloads, preload("User", selectUserSummary))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil riwayat unduhan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, listResponse(downloads, meta))
+}
+
+func GetDocumentDownloads(c *gin.Context) {
+	listDownloads(c, services.VersionTypeDocument)
+}
+
+func GetDocumentStaffDownloads(c *gin.Context) {
+	listDownloads(c, services.VersionTypeDocumentStaff)
+}
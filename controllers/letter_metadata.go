@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"dinsos_kuburaya/models"
+)
+
+const letterDateLayout = "2006-01-02"
+
+var allowedLetterTypes = map[string]bool{
+	"masuk":  true,
+	"keluar": true,
+}
+
+var allowedUrgencies = map[string]bool{
+	"biasa":         true,
+	"segera":        true,
+	"sangat_segera": true,
+}
+
+var allowedConfidentialities = map[string]bool{
+	"biasa":          true,
+	"terbatas":       true,
+	"rahasia":        true,
+	"sangat_rahasia": true,
+}
+
+// kode klasifikasi arsip, contoh: 400, 400.9, 400.9.1.2
+var classificationCodePattern = regexp.MustCompile(`^[0-9]{1,3}(\.[0-9]{1,4})*$`)
+
+// letterMetadata — input metadata surat dalam bentuk string mentah dari form/JSON
+type letterMetadata struct {
+	LetterType         string
+	LetterNumber       string
+	LetterDate         string
+	ReceivedDate       string
+	Recipient          string
+	ClassificationCode string
+	Urgency            string
+	Confidentiality    string
+}
+
+// letterMetadataFromDocument — nilai awal untuk update parsial
+func letterMetadataFromDocument(d models.Document) letterMetadata {
+	return letterMetadata{
+		LetterType:         d.LetterType,
+		LetterNumber:       d.LetterNumber,
+		LetterDate:         formatLetterDate(d.LetterDate),
+		ReceivedDate:       formatLetterDate(d.ReceivedDate),
+		Recipient:          d.Recipient,
+		ClassificationCode: d.ClassificationCode,
+		Urgency:            d.Urgency,
+		Confidentiality:    d.Confidentiality,
+	}
+}
+
+// applyTo — validasi lalu salin metadata ke dokumen
+func (m letterMetadata) applyTo(d *models.Document) error {
+	letterType := strings.TrimSpace(m.LetterType)
+	if !allowedLetterTypes[letterType] {
+		return errors.New("Jenis surat harus 'masuk' atau 'keluar'")
+	}
+
+	letterNumber := strings.TrimSpace(m.LetterNumber)
+	if len(letterNumber) > 100 {
+		return errors.New("Nomor surat maksimal 100 karakter")
+	}
+
+	letterDate, err := parseLetterDate(m.LetterDate)
+	if err != nil {
+		return errors.New("Format tanggal surat harus YYYY-MM-DD")
+	}
+
+	receivedDate, err := parseLetterDate(m.ReceivedDate)
+	if err != nil {
+		return errors.New("Format tanggal diterima/dikirim harus YYYY-MM-DD")
+	}
+
+	if letterDate != nil && receivedDate != nil && receivedDate.Before(*letterDate) {
+		return errors.New("Tanggal diterima/dikirim tidak boleh sebelum tanggal surat")
+	}
+
+	recipient := strings.TrimSpace(m.Recipient)
+	if len(recipient) > 255 {
+		return errors.New("Tujuan surat maksimal 255 karakter")
+	}
+	if letterType == "masuk" {
+		recipient = ""
+	}
+
+	classification := strings.TrimSpace(m.ClassificationCode)
+	if classification != "" && !classificationCodePattern.MatchString(classification) {
+		return errors.New("Kode klasifikasi tidak valid (contoh: 400.9.1)")
+	}
+
+	urgency := strings.TrimSpace(m.Urgency)
+	if urgency == "" {
+		urgency = "biasa"
+	}
+	if !allowedUrgencies[urgency] {
+		return errors.New("Tingkat urgensi tidak valid")
+	}
+
+	confidentiality := strings.TrimSpace(m.Confidentiality)
+	if confidentiality == "" {
+		confidentiality = "biasa"
+	}
+	if !allowedConfidentialities[confidentiality] {
+		return errors.New("Sifat surat tidak valid")
+	}
+
+	d.LetterType = letterType
+	d.LetterNumber = letterNumber
+	d.LetterDate = letterDate
+	d.ReceivedDate = receivedDate
+	d.Recipient = recipient
+	d.ClassificationCode = classification
+	d.Urgency = urgency
+	d.Confidentiality = confidentiality
+	return nil
+}
+
+func parseLetterDate(s string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	t, err := time.ParseInLocation(letterDateLayout, s, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func formatLetterDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(letterDateLayout)
+}
+
+func setIfProvided(dst *string, value *string) {
+	if value != nil {
+		*dst = *value
+	}
+}
@@ -0,0 +1,323 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// =======================
+// QUERY LIST BERSAMA
+// page/per_page atau cursor, sort whitelist, filter rentang tanggal,
+// dan envelope respons yang seragam untuk semua endpoint daftar
+// =======================
+
+const maxPerPage = 100
+
+// listSort — kolom yang boleh dipakai untuk sort. Kolom yang bisa NULL
+// tidak mendukung cursor karena perbandingan keyset dengan NULL tidak stabil.
+type listSort struct {
+	Column   string
+	Nullable bool
+}
+
+// listSpec — konfigurasi daftar per endpoint
+type listSpec struct {
+	Table          string              // nama tabel untuk kualifikasi kolom (query dengan JOIN)
+	Sorts          map[string]listSort // nama param sort → kolom
+	DefaultSort    string
+	DefaultOrder   string // asc | desc
+	DateColumn     string // kolom untuk date_from/date_to, kosong = tidak didukung
+	DefaultPerPage int
+}
+
+// listQuery — parameter daftar hasil parsing request
+type listQuery struct {
+	spec     listSpec
+	Page     int
+	PerPage  int
+	Sort     string
+	Order    string
+	Cursor   *listCursor
+	DateFrom *time.Time
+	DateTo   *time.Time
+}
+
+// listCursor — posisi baris terakhir halaman sebelumnya (nilai sort + id).
+// Value selalu diserialisasi: "" dan 0 adalah nilai sort yang sah, berbeda dengan nil.
+type listCursor struct {
+	Value interface{} `json:"v"`
+	Time  *time.Time  `json:"t,omitempty"`
+	ID    interface{} `json:"id"`
+}
+
+// listMeta — metadata envelope respons
+type listMeta struct {
+	Total       int64  `json:"total"`
+	PerPage     int    `json:"per_page"`
+	CurrentPage int    `json:"current_page,omitempty"`
+	LastPage    int    `json:"last_page"`
+	HasMore     bool   `json:"has_more"`
+	NextCursor  string `json:"next_cursor,omitempty"`
+	Sort        string `json:"sort"`
+	Order       string `json:"order"`
+}
+
+// parseListQuery — baca page, per_page (alias limit), cursor, sort, order, date_from, date_to.
+// Mengirim 400 dan mengembalikan false bila parameter tidak valid.
+func parseListQuery(c *gin.Context, spec listSpec) (listQuery, bool) {
+	q := listQuery{spec: spec, Page: 1, PerPage: spec.DefaultPerPage}
+	if q.PerPage == 0 {
+		q.PerPage = 20
+	}
+
+	fail := func(msg string) (listQuery, bool) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return q, false
+	}
+
+	if v := c.Query("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return fail("page harus bilangan bulat >= 1")
+		}
+		q.Page = page
+	}
+
+	perPage := c.Query("per_page")
+	if perPage == "" {
+		perPage = c.Query("limit")
+	}
+	if perPage != "" {
+		n, err := strconv.Atoi(perPage)
+		if err != nil || n < 1 {
+			return fail("per_page harus bilangan bulat >= 1")
+		}
+		if n > maxPerPage {
+			n = maxPerPage
+		}
+		q.PerPage = n
+	}
+
+	q.Sort = c.DefaultQuery("sort", spec.DefaultSort)
+	if _, ok := spec.Sorts[q.Sort]; !ok {
+		allowed := make([]string, 0, len(spec.Sorts))
+		for name := range spec.Sorts {
+			allowed = append(allowed, name)
+		}
+		sort.Strings(allowed)
+		return fail("sort harus salah satu dari: " + strings.Join(allowed, ", "))
+	}
+
+	q.Order = strings.ToLower(c.DefaultQuery("order", spec.DefaultOrder))
+	if q.Order != "asc" && q.Order != "desc" {
+		return fail("order harus asc atau desc")
+	}
+
+	if v := c.Query("cursor"); v != "" {
+		if spec.Sorts[q.Sort].Nullable {
+			return fail("cursor tidak didukung untuk sort " + q.Sort)
+		}
+		cursor, err := decodeListCursor(v)
+		if err != nil {
+			return fail("cursor tidak valid")
+		}
+		q.Cursor = cursor
+	}
+
+	for _, f := range []struct {
+		param string
+		dst   **time.Time
+	}{{"date_from", &q.DateFrom}, {"date_to", &q.DateTo}} {
+		value := c.Query(f.param)
+		if value == "" {
+			continue
+		}
+		if spec.DateColumn == "" {
+			return fail(f.param + " tidak didukung pada daftar ini")
+		}
+		date, err := time.ParseInLocation(letterDateLayout, value, time.Local)
+		if err != nil {
+			return fail("Format " + f.param + " harus YYYY-MM-DD")
+		}
+		*f.dst = &date
+	}
+
+	return q, true
+}
+
+func (q listQuery) column(name string) string {
+	if q.spec.Table == "" || strings.Contains(name, ".") {
+		return name
+	}
+	return q.spec.Table + "." + name
+}
+
+// applyFilters — filter rentang tanggal (date_to inklusif sampai akhir hari)
+func (q listQuery) applyFilters(query *gorm.DB) *gorm.DB {
+	if q.DateFrom != nil {
+		query = query.Where(q.column(q.spec.DateColumn)+" >= ?", *q.DateFrom)
+	}
+	if q.DateTo != nil {
+		query = query.Where(q.column(q.spec.DateColumn)+" < ?", q.DateTo.AddDate(0, 0, 1))
+	}
+	return query
+}
+
+// listScope — modifikasi yang hanya berlaku untuk query data, bukan query COUNT
+type listScope func(*gorm.DB) *gorm.DB
+
+func preload(association string, args ...interface{}) listScope {
+	return func(db *gorm.DB) *gorm.DB { return db.Preload(association, args...) }
+}
+
+func selectColumns(columns ...string) listScope {
+	return func(db *gorm.DB) *gorm.DB { return db.Select(columns) }
+}
+
+// find — hitung total, terapkan sort + paginasi, lalu isi dest (pointer ke slice model)
+func (q listQuery) find(query *gorm.DB, dest interface{}, scopes ...listScope) (listMeta, error) {
+	meta := listMeta{PerPage: q.PerPage, Sort: q.Sort, Order: q.Order}
+
+	query = q.applyFilters(query)
+
+	if err := query.Session(&gorm.Session{}).Count(&meta.Total).Error; err != nil {
+		return meta, err
+	}
+	meta.LastPage = int((meta.Total + int64(q.PerPage) - 1) / int64(q.PerPage))
+
+	sortColumn := q.column(q.spec.Sorts[q.Sort].Column)
+	idColumn := q.column("id")
+	direction, compare := "DESC", "<"
+	if q.Order == "asc" {
+		direction, compare = "ASC", ">"
+	}
+
+	for _, scope := range scopes {
+		query = scope(query)
+	}
+
+	query = query.Order(sortColumn + " " + direction).Order(idColumn + " " + direction)
+
+	if q.Cursor != nil {
+		value := q.Cursor.Value
+		if q.Cursor.Time != nil {
+			value = *q.Cursor.Time
+		}
+		query = query.Where(
+			"("+sortColumn+" "+compare+" ? OR ("+sortColumn+" = ? AND "+idColumn+" "+compare+" ?))",
+			value, value, q.Cursor.ID,
+		)
+	} else {
+		meta.CurrentPage = q.Page
+		query = query.Offset((q.Page - 1) * q.PerPage)
+	}
+
+	// ambil satu baris ekstra untuk mengetahui apakah masih ada halaman berikutnya
+	if err := query.Limit(q.PerPage + 1).Find(dest).Error; err != nil {
+		return meta, err
+	}
+
+	rows := reflect.ValueOf(dest).Elem()
+	if rows.Len() > q.PerPage {
+		meta.HasMore = true
+		rows.Set(rows.Slice(0, q.PerPage))
+
+		if !q.spec.Sorts[q.Sort].Nullable {
+			cursor, err := q.cursorFor(query, rows.Index(q.PerPage-1))
+			if err != nil {
+				return meta, err
+			}
+			meta.NextCursor = cursor
+		}
+	}
+
+	return meta, nil
+}
+
+// cursorFor — buat cursor dari baris terakhir memakai schema GORM model
+func (q listQuery) cursorFor(db *gorm.DB, row reflect.Value) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(row.Addr().Interface()); err != nil {
+		return "", err
+	}
+
+	sortField := stmt.Schema.LookUpField(q.spec.Sorts[q.Sort].Column)
+	idField := stmt.Schema.LookUpField("id")
+	if sortField == nil || idField == nil {
+		return "", errors.New("kolom cursor tidak ada pada model")
+	}
+
+	ctx := db.Statement.Context
+	value, _ := sortField.ValueOf(ctx, row)
+	id, _ := idField.ValueOf(ctx, row)
+
+	cursor := listCursor{ID: id}
+	if t, ok := value.(time.Time); ok {
+		cursor.Time = &t
+	} else {
+		cursor.Value = value
+	}
+
+	raw, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeListCursor(s string) (*listCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var cursor listCursor
+	if err := decoder.Decode(&cursor); err != nil {
+		return nil, err
+	}
+	if cursor.ID == nil {
+		return nil, errors.New("cursor tanpa id")
+	}
+	if cursor.Value == nil && cursor.Time == nil {
+		return nil, errors.New("cursor tanpa nilai sort")
+	}
+
+	cursor.Value = cursorValue(cursor.Value)
+	cursor.ID = cursorValue(cursor.ID)
+	return &cursor, nil
+}
+
+// cursorValue — json.Number tidak bisa di-bind driver SQL, ubah ke int64/float64
+func cursorValue(v interface{}) interface{} {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	f, _ := n.Float64()
+	return f
+}
+
+// listResponse — envelope seragam: {"data": [...], "meta": {...}}
+func listResponse(items interface{}, meta listMeta) gin.H {
+	return gin.H{
+		"data": items,
+		"meta": meta,
+	}
+}
@@ -1,17 +1,17 @@
 package controllers
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
 	"net/http"
-	"os"
-	"time"
+	"strconv"
 
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 
 	"dinsos_kuburaya/config"
 	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -28,77 +28,133 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	if block := services.CheckLoginThrottle(input.Username, c.ClientIP()); block != nil {
+		respondLoginBlocked(c, block)
+		return
+	}
+
 	db := config.DB
 
 	var user models.User
 	if err := db.Where("username = ?", input.Username).First(&user).Error; err != nil {
+		recordLoginFailure(c, models.User{Username: input.Username}, "username tidak terdaftar")
 		c.JSON(http.StatusUnauthorized, gin.H{"message": "Username atau password salah"})
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
+		recordLoginFailure(c, user, "password salah")
 		c.JSON(http.StatusUnauthorized, gin.H{"message": "Username atau password salah"})
 		return
 	}
 
-	secretKey := os.Getenv("JWT_SECRET")
-	if secretKey == "" {
-		secretKey = "default_secret"
+	if services.TemporaryPasswordExpired(user) {
+		services.CreateActivity(user.ID, user.Name, "login_failed", "Login ditolak: password sementara kedaluwarsa "+loginSource(sessionInfo(c)))
+		c.JSON(http.StatusUnauthorized, gin.H{"message": services.ErrTemporaryPasswordExpired.Error()})
+		return
 	}
 
-	claims := jwt.MapClaims{
-		"user_id": user.ID,
-		"exp":     time.Now().Add(14 * 24 * time.Hour).Unix(),
-		"role":    user.Role,
+	// tahap kedua: user dengan 2FA aktif, atau role yang diwajibkan 2FA tapi belum mendaftar
+	if user.TOTPEnabled {
+		startTwoFactorChallenge(c, user, services.ChallengeModeVerify)
+		return
+	}
+	if services.TwoFactorRequired(user.Role) {
+		startTwoFactorChallenge(c, user, services.ChallengeModeEnroll)
+		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(secretKey))
+	completeLogin(c, user, "Login berhasil", nil)
+}
+
+// completeLogin — buat sesi dan kirim respons login standar. extra ditambahkan ke respons
+// (mis. recovery code saat pendaftaran 2FA di tahap login).
+func completeLogin(c *gin.Context, user models.User, message string, extra gin.H) {
+	info := sessionInfo(c)
+
+	tokens, err := services.CreateSession(user, info)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Gagal membuat token"})
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Gagal membuat sesi"})
 		return
 	}
 
-	hash := sha256.Sum256([]byte(signedToken))
-	hashedToken := hex.EncodeToString(hash[:])
-
-	device := c.GetHeader("X-Device")
-	if device == "" {
-		device = "unknown"
+	services.RecordLoginSuccess(user.Username)
+	services.CreateActivity(user.ID, user.Name, "login", "Login berhasil "+loginSource(info))
+
+	response := gin.H{
+		"message":            message,
+		"token":              tokens.AccessToken,
+		"token_id":           tokens.SessionID,
+		"expires_in":         tokens.ExpiresIn,
+		"refresh_token":      tokens.RefreshToken,
+		"refresh_expires_at": tokens.RefreshExpiresAt,
+		// true = klien harus mengarahkan user ke form ganti password; endpoint lain ditolak
+		"must_change_password": user.MustChangePassword,
+		"user": gin.H{
+			"id":                   user.ID,
+			"name":                 user.Name,
+			"username":             user.Username,
+			"role":                 user.Role,
+			"photo_url":            user.PhotoURL,
+			"totp_enabled":         user.TOTPEnabled,
+			"must_change_password": user.MustChangePassword,
+		},
 	}
+	for key, value := range extra {
+		response[key] = value
+	}
+
+	c.JSON(http.StatusOK, response)
+}
 
-	db.Where("expires_at < ?", time.Now()).Delete(&models.SecretToken{})
-	db.Where("user_id = ? AND device = ?", user.ID, device).Delete(&models.SecretToken{})
+// loginSource — keterangan IP & user agent untuk log aktivitas login
+func loginSource(info services.SessionInfo) string {
+	return fmt.Sprintf("(IP: %s, perangkat: %s, user agent: %s)", info.IPAddress, deviceLabel(info.Device), info.UserAgent)
+}
 
-	var userTokens []models.SecretToken
-	db.Where("user_id = ?", user.ID).Order("created_at DESC").Find(&userTokens)
-	if len(userTokens) >= 2 {
-		oldest := userTokens[len(userTokens)-1]
-		db.Delete(&oldest)
+// recordLoginFailure — catat percobaan gagal untuk throttling dan log aktivitas.
+// user.ID kosong bila username tidak terdaftar.
+func recordLoginFailure(c *gin.Context, user models.User, reason string) {
+	username := user.Username
+	if len(username) > 100 {
+		username = username[:100]
 	}
 
-	secretToken := models.SecretToken{
-		JwtToken:  hashedToken,
-		UserID:    user.ID,
-		Device:    device,
-		ExpiresAt: time.Now().Add(14 * 24 * time.Hour),
+	name := user.Name
+	if name == "" {
+		name = username
 	}
 
-	if err := db.Create(&secretToken).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Gagal menyimpan token"})
+	services.CreateActivity(user.ID, name, "login_failed", "Login gagal untuk username "+username+": "+reason+" "+loginSource(sessionInfo(c)))
+
+	lockedNow, err := services.RecordLoginFailure(user.Username, c.ClientIP())
+	if err != nil {
+		log.Println("Gagal mencatat login gagal:", err)
 		return
 	}
+	if lockedNow {
+		services.CreateActivity(user.ID, name, "security", fmt.Sprintf(
+			"Akun %s dikunci sementara selama %d menit karena terlalu banyak login gagal", username, int(services.LoginLockoutDuration().Minutes()),
+		))
+		if user.ID != "" {
+			services.NotifyAdmins(services.PermUserManage, "Akun "+name+" dikunci sementara karena terlalu banyak login gagal", "/users")
+		}
+	}
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":  "Login berhasil",
-		"token":    signedToken,
-		"token_id": secretToken.ID,
-		"user": gin.H{
-			"id":        user.ID,
-			"name":      user.Name,
-			"username":  user.Username,
-			"role":      user.Role,
-			"photo_url": user.PhotoURL,
-		},
+// respondLoginBlocked — 429 dengan header Retry-After
+func respondLoginBlocked(c *gin.Context, block *services.LoginBlock) {
+	retryAfter := int(math.Ceil(block.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+
+	message := fmt.Sprintf("Terlalu banyak percobaan login, coba lagi dalam %d detik", retryAfter)
+	if block.Locked {
+		message = fmt.Sprintf("Akun atau alamat IP dikunci sementara karena terlalu banyak login gagal, coba lagi dalam %d menit", int(math.Ceil(block.RetryAfter.Minutes())))
+	}
+
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"message":     message,
+		"locked":      block.Locked,
+		"retry_after": retryAfter,
 	})
 }
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =======================
+// PENGUNCIAN LOGIN (superadmin)
+// =======================
+
+// GetLoginLockouts — username & IP yang sedang dalam backoff / terkunci
+func GetLoginLockouts(c *gin.Context) {
+	rows, err := services.ActiveLoginThrottles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data penguncian login"})
+		return
+	}
+
+	now := time.Now()
+	items := make([]gin.H, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, gin.H{
+			"id":             row.ID,
+			"scope":          row.Scope,
+			"key":            row.Key,
+			"failures":       row.Failures,
+			"last_failed_at": row.LastFailedAt,
+			"locked_until":   row.LockedUntil,
+			"locked":         row.LockedUntil != nil && row.LockedUntil.After(now),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"lockouts": items,
+		"total":    len(items),
+	})
+}
+
+// DeleteLoginLockout — buka kunci satu username / IP
+func DeleteLoginLockout(c *gin.Context) {
+	admin := c.MustGet("user").(models.User)
+
+	var row models.LoginThrottle
+	if err := config.DB.First(&row, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data penguncian tidak ditemukan"})
+		return
+	}
+
+	if _, err := services.UnlockLogin(row.Scope, row.Key); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuka kunci login"})
+		return
+	}
+
+	services.CreateActivity(admin.ID, admin.Name, "security", "Membuka kunci login "+row.Scope+": "+row.Key)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Kunci login berhasil dibuka"})
+}
+
+// UnlockUser — buka kunci login akun user
+func UnlockUser(c *gin.Context) {
+	admin := c.MustGet("user").(models.User)
+
+	var target models.User
+	if err := config.DB.Select("id", "name", "username").First(&target, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return
+	}
+
+	unlocked, err := services.UnlockLogin(services.ThrottleScopeUsername, target.Username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuka kunci akun"})
+		return
+	}
+
+	services.CreateActivity(admin.ID, admin.Name, "security", "Membuka kunci login akun: "+target.Name)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Kunci akun berhasil dibuka",
+		"was_held": unlocked > 0,
+	})
+}
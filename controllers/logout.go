@@ -3,27 +3,20 @@ package controllers
 import (
 	"net/http"
 
-	"dinsos_kuburaya/config"
-	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
 
 	"github.com/gin-gonic/gin"
 )
 
-type LogoutRequest struct {
-	TokenID string `json:"token_id" binding:"required"`
-}
-
+// Logout — hanya mencabut sesi perangkat yang sedang dipakai
 func Logout(c *gin.Context) {
-	userRaw, exists := c.Get("user")
-	if !exists {
+	sessionID := currentSessionID(c)
+	if sessionID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak terautentikasi"})
 		return
 	}
 
-	user := userRaw.(models.User)
-	db := config.DB
-
-	if err := db.Where("user_id = ?", user.ID).Delete(&models.SecretToken{}).Error; err != nil {
+	if err := services.RevokeSession(sessionID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal logout"})
 		return
 	}
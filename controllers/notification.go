@@ -2,7 +2,6 @@ package controllers
 
 import (
 	"net/http"
-	"strconv"
 
 	"dinsos_kuburaya/config"
 	"dinsos_kuburaya/models"
@@ -10,6 +9,17 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+var notificationListSpec = listSpec{
+	Table: "notifications",
+	Sorts: map[string]listSort{
+		"created_at": {Column: "created_at"},
+	},
+	DefaultSort:    "created_at",
+	DefaultOrder:   "desc",
+	DateColumn:     "created_at",
+	DefaultPerPage: 15,
+}
+
 // GetNotifications - Ambil semua notifikasi user yang login
 func GetNotifications(c *gin.Context) {
 	userRaw, exists := c.Get("user")
@@ -23,26 +33,23 @@ func GetNotifications(c *gin.Context) {
 	user := userRaw.(models.User)
 	userID := user.ID
 
-	page, _ := strconv.Atoi(c.Query("page"))
-	limit, _ := strconv.Atoi(c.Query("limit"))
-
-	if page < 1 {
-		page = 1
-	}
-	if limit < 1 {
-		limit = 15
+	list, ok := parseListQuery(c, notificationListSpec)
+	if !ok {
+		return
 	}
 
-	offset := (page - 1) * limit
+	query := config.DB.Model(&models.Notification{}).Where("user_id = ?", userID)
 
-	var notifications []models.Notification
-
-	if err := config.DB.Where("user_id = ?", userID).
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&notifications).Error; err != nil {
+	switch c.Query("is_read") {
+	case "true":
+		query = query.Where("is_read = ?", true)
+	case "false":
+		query = query.Where("is_read = ?", false)
+	}
 
+	var notifications []models.Notification
+	meta, err := list.find(query, &notifications)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Gagal mengambil notifikasi",
 		})
@@ -54,13 +61,10 @@ func GetNotifications(c *gin.Context) {
 		Where("user_id = ? AND is_read = ?", userID, false).
 		Count(&unreadCount)
 
-	c.JSON(http.StatusOK, gin.H{
-		"notifications": notifications,
-		"unread_count":  unreadCount,
-		"page":          page,
-		"limit":         limit,
-		"has_more":      len(notifications) == limit,
-	})
+	response := listResponse(notifications, meta)
+	response["unread_count"] = unreadCount
+
+	c.JSON(http.StatusOK, response)
 }
 
 // MarkNotificationAsRead - Tandai notifikasi sebagai sudah dibaca
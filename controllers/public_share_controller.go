@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sharedFile — data file di balik tautan publik
+type sharedFile struct {
+	storedFile
+	Subject string
+}
+
+func loadSharedFile(link models.ShareLink) (sharedFile, error) {
+	if link.DocumentType == services.VersionTypeDocument {
+		var d models.Document
+		err := config.DB.First(&d, "id = ?", link.DocumentID).Error
+		return sharedFile{documentFile(d), d.Subject}, err
+	}
+	var d models.DocumentStaff
+	err := config.DB.First(&d, "id = ?", link.DocumentID).Error
+	return sharedFile{documentStaffFile(d), d.Subject}, err
+}
+
+func publicShareError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrShareLinkExpired),
+		errors.Is(err, services.ErrShareLinkExhausted):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrShareLinkPassword):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "password_required": true})
+	case errors.Is(err, services.ErrShareLinkLocked):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrShareLinkNotFound.Error()})
+	}
+}
+
+// findPublicShare — tautan aktif beserta file-nya; dokumen yang sudah dihapus dianggap tidak ada
+func findPublicShare(c *gin.Context) (models.ShareLink, sharedFile, bool) {
+	link, err := services.FindShareLink(c.Param("token"))
+	if err != nil {
+		publicShareError(c, err)
+		return link, sharedFile{}, false
+	}
+
+	file, err := loadSharedFile(link)
+	if err != nil {
+		publicShareError(c, services.ErrShareLinkNotFound)
+		return link, file, false
+	}
+	return link, file, true
+}
+
+// sharePassword — password dikirim lewat header X-Share-Password atau form/JSON "password" (POST)
+func sharePassword(c *gin.Context) string {
+	if password := c.GetHeader("X-Share-Password"); password != "" {
+		return password
+	}
+	var body struct {
+		Password string `json:"password" form:"password"`
+	}
+	if c.Request.Method == http.MethodPost && c.ShouldBind(&body) == nil {
+		return body.Password
+	}
+	return ""
+}
+
+// =======================
+// INFO TAUTAN PUBLIK (tanpa login)
+// tautan berpassword hanya memperlihatkan nama file & perihal setelah password benar
+// =======================
+func GetPublicShare(c *gin.Context) {
+	link, file, ok := findPublicShare(c)
+	if !ok {
+		return
+	}
+
+	ip, userAgent := c.ClientIP(), c.Request.UserAgent()
+
+	if err := services.ShareLinkAvailable(link, true); err != nil {
+		services.RecordShareLinkAccess(link, services.ShareAccessOutcome(err), ip, userAgent)
+		publicShareError(c, err)
+		return
+	}
+
+	if link.HasPassword {
+		password := sharePassword(c)
+		if password == "" {
+			c.JSON(http.StatusOK, gin.H{
+				"password_required": true,
+				"expires_at":        link.ExpiresAt,
+			})
+			return
+		}
+		if err := services.VerifyShareLinkPassword(link, password, ip); err != nil {
+			services.RecordShareLinkAccess(link, services.ShareAccessOutcome(err), ip, userAgent)
+			publicShareError(c, err)
+			return
+		}
+	}
+
+	var remaining *int
+	if link.MaxDownloads != nil {
+		left := *link.MaxDownloads - link.DownloadCount
+		if left < 0 {
+			left = 0
+		}
+		remaining = &left
+	}
+
+	services.RecordShareLinkAccess(link, services.ShareAccessView, ip, userAgent)
+
+	c.JSON(http.StatusOK, gin.H{
+		"file_name":           file.Name,
+		"subject":             file.Subject,
+		"password_required":   link.HasPassword,
+		"expires_at":          link.ExpiresAt,
+		"remaining_downloads": remaining,
+	})
+}
+
+// =======================
+// UNDUH LEWAT TAUTAN PUBLIK (tanpa login)
+// hanya unduhan baru yang menghabiskan kuota; lanjutan Range (seek / resume)
+// tetap dilayani selama tautan belum kedaluwarsa dan password benar
+// =======================
+func DownloadPublicShare(c *gin.Context) {
+	link, file, ok := findPublicShare(c)
+	if !ok {
+		return
+	}
+
+	ip, userAgent := c.ClientIP(), c.Request.UserAgent()
+
+	if err := services.CheckShareLink(link, sharePassword(c), ip, countsAsDownload(c.Request)); err != nil {
+		services.RecordShareLinkAccess(link, services.ShareAccessOutcome(err), ip, userAgent)
+		publicShareError(c, err)
+		return
+	}
+
+	serveStoredFile(c, file.storedFile, func() bool {
+		if err := services.ConsumeShareLinkDownload(link); err != nil {
+			services.RecordShareLinkAccess(link, services.ShareAccessOutcome(err), ip, userAgent)
+			publicShareError(c, err)
+			return false
+		}
+
+		services.RecordShareLinkAccess(link, services.ShareAccessDownload, ip, userAgent)
+		services.RecordDownload(services.DownloadEvent{
+			Ref:         services.DocumentRef{Type: link.DocumentType, ID: link.DocumentID, Name: file.Name},
+			FileName:    file.Name,
+			ShareLinkID: &link.ID,
+			Via:         services.DownloadViaShareLink,
+			IPAddress:   ip,
+			UserAgent:   userAgent,
+		})
+		return true
+	})
+}
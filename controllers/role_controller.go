@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// UpdateRoleRequest — field yang tidak dikirim tidak diubah
+type UpdateRoleRequest struct {
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// RoleResponse — role beserta izin & jumlah user
+type RoleResponse struct {
+	models.Role
+	Permissions []string `json:"permissions"`
+	UserCount   int64    `json:"user_count"`
+}
+
+func roleResponse(role models.Role) RoleResponse {
+	response := RoleResponse{
+		Role:        role,
+		Permissions: services.UserPermissions(models.User{Role: role.Name}),
+	}
+	config.DB.Model(&models.User{}).Where("role = ?", role.Name).Count(&response.UserCount)
+	return response
+}
+
+func roleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrRoleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRoleExists),
+		errors.Is(err, services.ErrRoleInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRoleSystem),
+		errors.Is(err, services.ErrSuperadminFixed):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRoleNameInvalid),
+		strings.HasPrefix(err.Error(), services.ErrPermissionUnknown.Error()):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// =======================
+// KATALOG IZIN
+// =======================
+func GetPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"permissions": services.PermissionCatalog})
+}
+
+// =======================
+// ROLE
+// =======================
+func GetRoles(c *gin.Context) {
+	var roles []models.Role
+	if err := config.DB.Order("is_system DESC").Order("name").Find(&roles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil daftar role"})
+		return
+	}
+
+	items := make([]RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		items = append(items, roleResponse(role))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": items})
+}
+
+func GetRole(c *gin.Context) {
+	var role models.Role
+	if err := config.DB.First(&role, "name = ?", c.Param("name")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrRoleNotFound.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"role": roleResponse(role)})
+}
+
+func CreateRole(c *gin.Context) {
+	admin := c.MustGet("user").(models.User)
+
+	var input CreateRoleRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nama role wajib diisi"})
+		return
+	}
+
+	role, err := services.CreateRole(strings.TrimSpace(input.Name), input.Description, input.Permissions)
+	if err != nil {
+		roleError(c, err, "Gagal membuat role")
+		return
+	}
+
+	services.CreateActivity(admin.ID, admin.Name, "security",
+		"Membuat role "+role.Name+" dengan izin: "+strings.Join(input.Permissions, ", "))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Role berhasil dibuat",
+		"role":    roleResponse(role),
+	})
+}
+
+func UpdateRole(c *gin.Context) {
+	admin := c.MustGet("user").(models.User)
+
+	var input UpdateRoleRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Data tidak valid"})
+		return
+	}
+
+	role, err := services.UpdateRole(c.Param("name"), input.Description, input.Permissions)
+	if err != nil {
+		roleError(c, err, "Gagal memperbarui role")
+		return
+	}
+
+	message := "Memperbarui role " + role.Name
+	if input.Permissions != nil {
+		message += ", izin: " + strings.Join(input.Permissions, ", ")
+	}
+	services.CreateActivity(admin.ID, admin.Name, "security", message)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role berhasil diperbarui",
+		"role":    roleResponse(role),
+	})
+}
+
+func DeleteRole(c *gin.Context) {
+	admin := c.MustGet("user").(models.User)
+	name := c.Param("name")
+
+	if err := services.DeleteRole(name); err != nil {
+		roleError(c, err, "Gagal menghapus role")
+		return
+	}
+
+	services.CreateActivity(admin.ID, admin.Name, "security", "Menghapus role "+name)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role berhasil dihapus"})
+}
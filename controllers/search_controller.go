@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =======================
+// FULL-TEXT SEARCH
+// GET /api/search?q=...&scope=all|documents|document_staff
+// =======================
+func Search(c *gin.Context) {
+	query := services.ParseSearchQuery(c.Query("q"))
+	if query.IsEmpty() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kata kunci pencarian wajib diisi"})
+		return
+	}
+
+	scope := c.DefaultQuery("scope", "all")
+	if scope != "all" && scope != "documents" && scope != "document_staff" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Scope harus all, documents atau document_staff"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	dateFrom, err := parseLetterDate(c.Query("date_from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format date_from harus YYYY-MM-DD"})
+		return
+	}
+	dateTo, err := parseLetterDate(c.Query("date_to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format date_to harus YYYY-MM-DD"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+	params := services.SearchParams{
+		Query:              query,
+		LetterType:         c.Query("letter_type"),
+		UploaderID:         c.Query("uploader_id"),
+		ClassificationCode: c.Query("classification_code"),
+		DateFrom:           dateFrom,
+		DateTo:             dateTo,
+		Limit:              perPage,
+		Offset:             (page - 1) * perPage,
+
+		DocumentAccess:      services.DocumentAccess(user),
+		DocumentStaffAccess: services.DocumentStaffAccess(user),
+	}
+
+	// filter khusus surat tidak berlaku untuk dokumen staff
+	if scope == "all" && (params.LetterType != "" && params.LetterType != "all" || params.ClassificationCode != "") {
+		scope = "documents"
+	}
+
+	var hits []services.SearchHit
+	var total int64
+
+	switch scope {
+	case "documents":
+		hits, total, err = services.SearchDocuments(params)
+	case "document_staff":
+		hits, total, err = services.SearchDocumentStaffs(params)
+	default:
+		// ambil cukup banyak dari kedua sumber lalu gabungkan sesuai relevansi
+		merged := params
+		merged.Limit = page * perPage
+		merged.Offset = 0
+
+		docHits, docTotal, docErr := services.SearchDocuments(merged)
+		staffHits, staffTotal, staffErr := services.SearchDocumentStaffs(merged)
+		if docErr != nil {
+			err = docErr
+		} else if staffErr != nil {
+			err = staffErr
+		}
+
+		all := services.MergeSearchHits(docHits, staffHits)
+		total = docTotal + staffTotal
+
+		start := (page - 1) * perPage
+		if start > len(all) {
+			start = len(all)
+		}
+		end := start + perPage
+		if end > len(all) {
+			end = len(all)
+		}
+		hits = all[start:end]
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal melakukan pencarian"})
+		return
+	}
+
+	lastPage := int(total) / perPage
+	if int(total)%perPage != 0 {
+		lastPage++
+	}
+
+	c.JSON(http.StatusOK, listResponse(hits, listMeta{
+		Total:       total,
+		PerPage:     perPage,
+		CurrentPage: page,
+		LastPage:    lastPage,
+		HasMore:     page < lastPage,
+		Sort:        "relevance",
+		Order:       "desc",
+	}))
+}
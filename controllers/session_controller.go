@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// SessionResponse — sesi perangkat tanpa hash token
+type SessionResponse struct {
+	ID         string     `json:"id"`
+	Device     string     `json:"device"`
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	Current    bool       `json:"current"`
+}
+
+// sessionInfo — identitas perangkat dari header X-Device, IP dan User-Agent.
+// Tanpa X-Device perangkat tidak dikenali dan setiap login menjadi sesi tersendiri.
+func sessionInfo(c *gin.Context) services.SessionInfo {
+	return services.SessionInfo{
+		Device:    truncateRunes(strings.TrimSpace(c.GetHeader("X-Device")), 50),
+		IPAddress: c.ClientIP(),
+		UserAgent: truncateRunes(c.Request.UserAgent(), 255),
+	}
+}
+
+// truncateRunes — potong per karakter, bukan per byte, agar rune UTF-8 tidak terbelah
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
+
+// deviceLabel — nama perangkat untuk log aktivitas
+func deviceLabel(device string) string {
+	if device == "" {
+		return "tidak diketahui"
+	}
+	return device
+}
+
+func currentSessionID(c *gin.Context) string {
+	if raw, ok := c.Get("session"); ok {
+		return raw.(models.SecretToken).ID
+	}
+	return ""
+}
+
+func listSessions(c *gin.Context, userID string) {
+	var sessions []models.SecretToken
+	if err := config.DB.
+		Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Order("COALESCE(last_seen_at, created_at) DESC").
+		Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil daftar sesi"})
+		return
+	}
+
+	currentID := currentSessionID(c)
+	items := make([]SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		items = append(items, SessionResponse{
+			ID:         s.ID,
+			Device:     s.Device,
+			IPAddress:  s.IPAddress,
+			UserAgent:  s.UserAgent,
+			LastSeenAt: s.LastSeenAt,
+			CreatedAt:  s.CreatedAt,
+			ExpiresAt:  s.ExpiresAt,
+			Current:    s.ID == currentID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sessions":     items,
+		"total":        len(items),
+		"max_sessions": services.MaxSessionsPerUser(),
+	})
+}
+
+// =======================
+// REFRESH TOKEN (rotasi)
+// =======================
+func RefreshToken(c *gin.Context) {
+	var input RefreshTokenRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refresh_token wajib diisi"})
+		return
+	}
+
+	tokens, user, err := services.RefreshSession(input.RefreshToken, sessionInfo(c))
+	if err != nil {
+		if errors.Is(err, services.ErrRefreshTokenReused) {
+			services.CreateActivity(user.ID, user.Name, "security", "Refresh token dipakai ulang, sesi perangkat dicabut")
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrRefreshTokenInvalid) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// =======================
+// SESI MILIK SENDIRI
+// =======================
+func GetMySessions(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+	listSessions(c, user.ID)
+}
+
+func RevokeMySession(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var session models.SecretToken
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Param("id"), user.ID).First(&session).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sesi tidak ditemukan"})
+		return
+	}
+
+	if err := services.RevokeSession(session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mencabut sesi"})
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "logout", "Mencabut sesi perangkat: "+deviceLabel(session.Device))
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sesi berhasil dicabut"})
+}
+
+// =======================
+// SESI USER LAIN (superadmin)
+// =======================
+func GetUserSessions(c *gin.Context) {
+	listSessions(c, c.Param("id"))
+}
+
+func RevokeUserSessions(c *gin.Context) {
+	admin := c.MustGet("user").(models.User)
+
+	var target models.User
+	if err := config.DB.Select("id", "name").First(&target, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return
+	}
+
+	revoked, err := services.RevokeUserSessions(target.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mencabut sesi"})
+		return
+	}
+
+	services.CreateActivity(admin.ID, admin.Name, "logout", "Mencabut semua sesi user: "+target.Name)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Semua sesi user berhasil dicabut",
+		"revoked": revoked,
+	})
+}
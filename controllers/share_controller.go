@@ -0,0 +1,320 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShareRequest struct {
+	UserIDs    []string   `json:"user_ids" binding:"required,min=1"`
+	Permission string     `json:"permission"` // view (default) | download
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+type ShareLinkRequest struct {
+	Password     string     `json:"password"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	MaxDownloads *int       `json:"max_downloads"`
+}
+
+const publicSharePath = "/api/public/share/"
+
+func shareError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrShareNotFound),
+		errors.Is(err, services.ErrShareLinkNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSharePermissionInvalid),
+		errors.Is(err, services.ErrShareUserNotFound),
+		errors.Is(err, services.ErrShareSelf),
+		errors.Is(err, services.ErrShareExpiryInvalid),
+		errors.Is(err, services.ErrShareLinkMaxDownloads),
+		errors.Is(err, services.ErrShareLinkPasswordShort):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// shareTarget — dokumen yang share-nya dikelola; user harus boleh membagikannya
+func shareTarget(c *gin.Context, docType string) (services.DocumentRef, bool) {
+	if docType == services.VersionTypeDocument {
+		document, ok := findDocument(c, config.DB, services.DocActionShare)
+		return services.DocumentRefOf(document), ok
+	}
+	document, ok := findDocumentStaff(c, config.DB, services.DocActionShare)
+	return services.DocumentStaffRefOf(document), ok
+}
+
+func documentLink(ref services.DocumentRef) string {
+	if ref.Type == services.VersionTypeDocument {
+		return "/documents/" + ref.ID
+	}
+	return "/document_staff/" + ref.ID
+}
+
+// =======================
+// SHARE KE USER
+// =======================
+func listShares(c *gin.Context, docType string) {
+	ref, ok := shareTarget(c, docType)
+	if !ok {
+		return
+	}
+
+	shares, err := services.DocumentShares(ref)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil daftar share"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"shares": shares,
+		"total":  len(shares),
+	})
+}
+
+func createShares(c *gin.Context, docType string) {
+	user := c.MustGet("user").(models.User)
+
+	ref, ok := shareTarget(c, docType)
+	if !ok {
+		return
+	}
+
+	var req ShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Permission == "" {
+		req.Permission = services.SharePermissionView
+	}
+
+	shares := make([]models.DocumentShare, 0, len(req.UserIDs))
+	for _, userID := range req.UserIDs {
+		share, err := services.ShareDocument(ref, userID, req.Permission, req.ExpiresAt, user.ID)
+		if err != nil {
+			shareError(c, err, "Gagal membagikan dokumen")
+			return
+		}
+		shares = append(shares, share)
+	}
+
+	for _, share := range shares {
+		services.NotifySpecificUser(share.UserID, user.Name+" membagikan dokumen: "+ref.Name, documentLink(ref))
+		services.CreateActivity(user.ID, user.Name, "share", "Membagikan "+ref.Name+" ke "+share.User.Name+" ("+share.Permission+")")
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Dokumen berhasil dibagikan",
+		"shares":  shares,
+	})
+}
+
+func revokeShare(c *gin.Context, docType string) {
+	user := c.MustGet("user").(models.User)
+
+	ref, ok := shareTarget(c, docType)
+	if !ok {
+		return
+	}
+
+	share, err := services.RevokeDocumentShare(ref, c.Param("share_id"))
+	if err != nil {
+		shareError(c, err, "Gagal mencabut share")
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "share", "Mencabut share "+ref.Name+" dari user "+share.UserID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Share berhasil dicabut"})
+}
+
+// =======================
+// TAUTAN PUBLIK
+// =======================
+func listShareLinks(c *gin.Context, docType string) {
+	ref, ok := shareTarget(c, docType)
+	if !ok {
+		return
+	}
+
+	links, err := services.DocumentShareLinks(ref)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil daftar tautan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"links": links,
+		"total": len(links),
+	})
+}
+
+func createShareLink(c *gin.Context, docType string) {
+	user := c.MustGet("user").(models.User)
+
+	ref, ok := shareTarget(c, docType)
+	if !ok {
+		return
+	}
+
+	var req ShareLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	link, token, err := services.CreateShareLink(ref, services.ShareLinkInput{
+		Password:     req.Password,
+		ExpiresAt:    req.ExpiresAt,
+		MaxDownloads: req.MaxDownloads,
+	}, user.ID)
+	if err != nil {
+		shareError(c, err, "Gagal membuat tautan")
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "share", "Membuat tautan publik untuk "+ref.Name)
+
+	// token hanya ditampilkan sekali
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Tautan berhasil dibuat",
+		"link":    link,
+		"token":   token,
+		"url":     publicSharePath + token,
+	})
+}
+
+func revokeShareLink(c *gin.Context, docType string) {
+	user := c.MustGet("user").(models.User)
+
+	ref, ok := shareTarget(c, docType)
+	if !ok {
+		return
+	}
+
+	if _, err := services.RevokeShareLink(ref, c.Param("link_id")); err != nil {
+		shareError(c, err, "Gagal mencabut tautan")
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "share", "Mencabut tautan publik untuk "+ref.Name)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tautan berhasil dicabut"})
+}
+
+// =======================
+// DOCUMENT (SURAT) SHARES
+// =======================
+func GetDocumentShares(c *gin.Context) {
+	listShares(c, services.VersionTypeDocument)
+}
+
+func ShareDocument(c *gin.Context) {
+	createShares(c, services.VersionTypeDocument)
+}
+
+func RevokeDocumentShare(c *gin.Context) {
+	revokeShare(c, services.VersionTypeDocument)
+}
+
+func GetDocumentShareLinks(c *gin.Context) {
+	listShareLinks(c, services.VersionTypeDocument)
+}
+
+func CreateDocumentShareLink(c *gin.Context) {
+	createShareLink(c, services.VersionTypeDocument)
+}
+
+func RevokeDocumentShareLink(c *gin.Context) {
+	revokeShareLink(c, services.VersionTypeDocument)
+}
+
+// =======================
+// DOCUMENT STAFF SHARES
+// =======================
+func GetDocumentStaffShares(c *gin.Context) {
+	listShares(c, services.VersionTypeDocumentStaff)
+}
+
+func ShareDocumentStaff(c *gin.Context) {
+	createShares(c, services.VersionTypeDocumentStaff)
+}
+
+func RevokeDocumentStaffShare(c *gin.Context) {
+	revokeShare(c, services.VersionTypeDocumentStaff)
+}
+
+func GetDocumentStaffShareLinks(c *gin.Context) {
+	listShareLinks(c, services.VersionTypeDocumentStaff)
+}
+
+func CreateDocumentStaffShareLink(c *gin.Context) {
+	createShareLink(c, services.VersionTypeDocumentStaff)
+}
+
+func RevokeDocumentStaffShareLink(c *gin.Context) {
+	revokeShareLink(c, services.VersionTypeDocumentStaff)
+}
+
+// =======================
+// DIBAGIKAN KE SAYA
+// =======================
+func GetReceivedShares(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	shares, err := services.ReceivedShares(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil dokumen yang dibagikan"})
+		return
+	}
+
+	var documentIDs, staffIDs []string
+	for _, share := range shares {
+		if share.DocumentType == services.VersionTypeDocument {
+			documentIDs = append(documentIDs, share.DocumentID)
+		} else {
+			staffIDs = append(staffIDs, share.DocumentID)
+		}
+	}
+
+	documents := map[string]interface{}{}
+	if len(documentIDs) > 0 {
+		var rows []models.Document
+		config.DB.Preload("User", selectUserSummary).Where("id IN ?", documentIDs).Find(&rows)
+		for _, d := range rows {
+			documents[d.ID] = d
+		}
+	}
+	if len(staffIDs) > 0 {
+		var rows []models.DocumentStaff
+		config.DB.Preload("User", selectUserSummary).Where("id IN ?", staffIDs).Find(&rows)
+		for _, d := range rows {
+			documents[d.ID] = d
+		}
+	}
+
+	// dokumen yang sudah dihapus ke trash tidak ditampilkan
+	items := make([]gin.H, 0, len(shares))
+	for _, share := range shares {
+		document, ok := documents[share.DocumentID]
+		if !ok {
+			continue
+		}
+		items = append(items, gin.H{"share": share, "document": document})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": items,
+		"total": len(items),
+	})
+}
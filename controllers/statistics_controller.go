@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =======================
+// STATISTIK SURAT
+// granularity day|week|month|quarter|year, group_by opsional, compare=true untuk perbandingan tahun lalu
+// =======================
+func GetDocumentStatistics(c *gin.Context) {
+	access := services.DocumentAccess(c.MustGet("user").(models.User))
+
+	from, to, ok := reportPeriod(c)
+	if !ok {
+		return
+	}
+
+	params := services.StatsParams{
+		Granularity: c.DefaultQuery("granularity", services.StatsMonth),
+		GroupBy:     c.Query("group_by"),
+		From:        from,
+		To:          to,
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit harus bilangan positif"})
+			return
+		}
+		params.Limit = limit
+	}
+	params.Compare, _ = strconv.ParseBool(c.Query("compare"))
+
+	stats, err := services.DocumentStatistics(access.ScopeDocuments, params)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrStatsGranularity),
+			errors.Is(err, services.ErrStatsGroupBy),
+			errors.Is(err, services.ErrStatsPeriod),
+			errors.Is(err, services.ErrStatsTooLong):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung statistik surat"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,334 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SubmissionRequestBody — deadline berupa RFC3339 atau YYYY-MM-DD (akhir hari)
+type SubmissionRequestBody struct {
+	Title           *string  `json:"title"`
+	Description     *string  `json:"description"`
+	Deadline        *string  `json:"deadline"`
+	AllowLate       *bool    `json:"allow_late"`
+	Status          *string  `json:"status"`
+	UserIDs         []string `json:"user_ids"`
+	UnitIDs         []string `json:"unit_ids"`
+	IncludeSubunits bool     `json:"include_subunits"`
+}
+
+func (r SubmissionRequestBody) targets() services.SubmissionTargetInput {
+	return services.SubmissionTargetInput{
+		UserIDs:         r.UserIDs,
+		UnitIDs:         r.UnitIDs,
+		IncludeSubunits: r.IncludeSubunits,
+	}
+}
+
+func parseDeadline(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation(letterDateLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return day.Add(24*time.Hour - time.Second), nil
+}
+
+func submissionError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrSubmissionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSubmissionNotTarget):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSubmissionClosed),
+		errors.Is(err, services.ErrSubmissionLate):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSubmissionTitle),
+		errors.Is(err, services.ErrSubmissionDeadline),
+		errors.Is(err, services.ErrSubmissionNoTargets),
+		errors.Is(err, services.ErrSubmissionUserNotFound),
+		errors.Is(err, services.ErrSubmissionStatus),
+		errors.Is(err, services.ErrUnitNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// =======================
+// BUAT PERMINTAAN PENGUMPULAN
+// =======================
+func CreateSubmissionRequest(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req SubmissionRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Title == nil || req.Deadline == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title dan deadline wajib diisi"})
+		return
+	}
+
+	deadline, err := parseDeadline(*req.Deadline)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format deadline harus RFC3339 atau YYYY-MM-DD"})
+		return
+	}
+
+	input := services.SubmissionInput{
+		Title:     *req.Title,
+		Deadline:  deadline,
+		AllowLate: req.AllowLate,
+		Targets:   req.targets(),
+	}
+	if req.Description != nil {
+		input.Description = *req.Description
+	}
+
+	request, targets, err := services.CreateSubmissionRequest(input, user)
+	if err != nil {
+		submissionError(c, err, "Gagal membuat permintaan pengumpulan")
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "create", "Membuat permintaan pengumpulan: "+request.Title+" ("+strconv.Itoa(targets)+" user)")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Permintaan pengumpulan berhasil dibuat",
+		"request": request,
+		"targets": targets,
+	})
+}
+
+// =======================
+// DAFTAR PERMINTAAN
+// =======================
+var submissionListSpec = listSpec{
+	Table: "submission_requests",
+	Sorts: map[string]listSort{
+		"created_at": {Column: "created_at"},
+		"deadline":   {Column: "deadline"},
+		"title":      {Column: "title"},
+	},
+	DefaultSort:  "deadline",
+	DefaultOrder: "desc",
+	DateColumn:   "deadline",
+}
+
+// semua permintaan beserta rekap kepatuhan (pengelola)
+func GetSubmissionRequests(c *gin.Context) {
+	list, ok := parseListQuery(c, submissionListSpec)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.SubmissionRequest{})
+	if status := c.Query("status"); status != "" && status != "all" {
+		query = query.Where("submission_requests.status = ?", status)
+	}
+	if search := c.Query("search"); search != "" {
+		query = query.Where("submission_requests.title LIKE ?", "%"+search+"%")
+	}
+
+	var requests []models.SubmissionRequest
+	meta, err := list.find(query, &requests, preload("CreatedBy", selectUserSummary))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil permintaan pengumpulan"})
+		return
+	}
+
+	views, err := services.SubmissionSummaries(requests)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung rekap pengumpulan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, listResponse(views, meta))
+}
+
+// permintaan yang ditujukan ke user beserta status pengumpulannya
+func GetMySubmissionRequests(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	list, ok := parseListQuery(c, submissionListSpec)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Model(&models.SubmissionRequest{}).
+		Joins("JOIN submission_targets ON submission_targets.request_id = submission_requests.id AND submission_targets.user_id = ?", user.ID)
+	if status := c.Query("status"); status != "" && status != "all" {
+		query = query.Where("submission_requests.status = ?", status)
+	}
+
+	var requests []models.SubmissionRequest
+	meta, err := list.find(query, &requests,
+		selectColumns("submission_requests.*"),
+		preload("CreatedBy", selectUserSummary),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil permintaan pengumpulan"})
+		return
+	}
+
+	views, err := services.MySubmissionViews(requests, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung status pengumpulan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, listResponse(views, meta))
+}
+
+// =======================
+// DETAIL & REKAP PENGUMPULAN
+// pengelola melihat status semua target (filter ?status=submitted|late|missing|pending),
+// target hanya melihat statusnya sendiri
+// =======================
+func GetSubmissionRequest(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	request, err := services.FindSubmissionRequest(c.Param("id"))
+	if err != nil {
+		submissionError(c, err, "Gagal mengambil permintaan pengumpulan")
+		return
+	}
+
+	if !services.HasPermission(user, services.PermSubmissionManage) {
+		if !services.IsSubmissionTarget(request.ID, user.ID) {
+			submissionError(c, services.ErrSubmissionNotFound, "")
+			return
+		}
+
+		views, err := services.MySubmissionViews([]models.SubmissionRequest{request}, user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung status pengumpulan"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"request": views[0]})
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", "all":
+		status = ""
+	case services.SubmissionSubmitted, services.SubmissionLate, services.SubmissionMissing, services.SubmissionPending:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status harus submitted, late, missing atau pending"})
+		return
+	}
+
+	entries, summary, err := services.SubmissionReport(request, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung rekap pengumpulan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"request": request,
+		"summary": summary,
+		"targets": entries,
+	})
+}
+
+// =======================
+// UBAH / TUTUP PERMINTAAN
+// user_ids / unit_ids menambah tujuan baru
+// =======================
+func UpdateSubmissionRequest(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req SubmissionRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input := services.SubmissionUpdate{
+		Title:       req.Title,
+		Description: req.Description,
+		AllowLate:   req.AllowLate,
+		Status:      req.Status,
+		Targets:     req.targets(),
+	}
+	if req.Deadline != nil {
+		deadline, err := parseDeadline(*req.Deadline)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format deadline harus RFC3339 atau YYYY-MM-DD"})
+			return
+		}
+		input.Deadline = &deadline
+	}
+
+	request, added, err := services.UpdateSubmissionRequest(c.Param("id"), input)
+	if err != nil {
+		submissionError(c, err, "Gagal memperbarui permintaan pengumpulan")
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "update", "Memperbarui permintaan pengumpulan: "+request.Title)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Permintaan pengumpulan berhasil diperbarui",
+		"request":       request,
+		"added_targets": added,
+	})
+}
+
+func DeleteSubmissionRequest(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	request, err := services.FindSubmissionRequest(c.Param("id"))
+	if err != nil {
+		submissionError(c, err, "Gagal mengambil permintaan pengumpulan")
+		return
+	}
+
+	if err := services.DeleteSubmissionRequest(request); err != nil {
+		submissionError(c, err, "Gagal menghapus permintaan pengumpulan")
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "delete", "Menghapus permintaan pengumpulan: "+request.Title)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Permintaan pengumpulan berhasil dihapus"})
+}
+
+// =======================
+// PENGINGAT MANUAL
+// =======================
+func RemindSubmissionRequest(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	request, err := services.FindSubmissionRequest(c.Param("id"))
+	if err != nil {
+		submissionError(c, err, "Gagal mengambil permintaan pengumpulan")
+		return
+	}
+
+	sent, err := services.RemindSubmissionTargets(request)
+	if err != nil {
+		submissionError(c, err, "Gagal mengirim pengingat")
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "update", "Mengirim pengingat pengumpulan "+request.Title+" ke "+strconv.Itoa(sent)+" user")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pengingat terkirim",
+		"sent":    sent,
+	})
+}
@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// softDelete — catat siapa yang menghapus lalu set deleted_at
+func softDelete(model interface{}, user models.User) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(model).Update("deleted_by_id", user.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(model).Error
+	})
+}
+
+// restoreFromTrash — kosongkan deleted_at & deleted_by_id
+func restoreFromTrash(model interface{}) error {
+	return config.DB.Unscoped().Model(model).Updates(map[string]interface{}{
+		"deleted_at":    nil,
+		"deleted_by_id": nil,
+	}).Error
+}
+
+func purgeDate(deletedAt gorm.DeletedAt) *time.Time {
+	if !deletedAt.Valid {
+		return nil
+	}
+	t := deletedAt.Time.Add(services.TrashRetention())
+	return &t
+}
+
+// trashListSpec — deleted_at bertipe gorm.DeletedAt sehingga tidak dipakai untuk cursor
+func trashListSpec(table string) listSpec {
+	return listSpec{
+		Table: table,
+		Sorts: map[string]listSort{
+			"deleted_at": {Column: "deleted_at", Nullable: true},
+			"created_at": {Column: "created_at"},
+		},
+		DefaultSort:  "deleted_at",
+		DefaultOrder: "desc",
+		DateColumn:   "deleted_at",
+	}
+}
+
+func trashResponse(items []gin.H, meta listMeta) gin.H {
+	response := listResponse(items, meta)
+	response["retention_days"] = int(services.TrashRetention().Hours() / 24)
+	return response
+}
+
+// =======================
+// TRASH: DOCUMENTS (admin)
+// =======================
+func GetDocumentTrash(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	list, ok := parseListQuery(c, trashListSpec("documents"))
+	if !ok {
+		return
+	}
+
+	query := config.DB.Unscoped().Model(&models.Document{}).Where("deleted_at IS NOT NULL")
+	query = services.DocumentAccess(user).ScopeDocuments(query)
+
+	var documents []models.Document
+	meta, err := list.find(query, &documents, preload("User"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil trash dokumen"})
+		return
+	}
+
+	items := make([]gin.H, 0, len(documents))
+	for _, d := range documents {
+		items = append(items, gin.H{"document": d, "purge_at": purgeDate(d.DeletedAt)})
+	}
+
+	c.JSON(http.StatusOK, trashResponse(items, meta))
+}
+
+func RestoreDocument(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var document models.Document
+	if err := config.DB.Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", c.Param("id")).
+		First(&document).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen tidak ditemukan di trash"})
+		return
+	}
+
+	// izin document.restore diperiksa di route; memulihkan juga butuh hak yang sama dengan
+	// memindahkan ke trash agar penerima share tidak bisa memulihkan dokumen orang lain
+	if !authorizeDocument(c, services.DocumentRefOf(document), services.DocActionDelete) {
+		return
+	}
+
+	if err := restoreFromTrash(&document); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memulihkan dokumen"})
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "restore", "Memulihkan dokumen dari trash: "+document.FileName)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Dokumen berhasil dipulihkan",
+		"document": document,
+	})
+}
+
+// =======================
+// TRASH: DOCUMENT STAFF
+// staff melihat trash miliknya, kepala unit melihat trash unitnya, admin melihat semua
+// =======================
+func GetDocumentStaffTrash(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	list, ok := parseListQuery(c, trashListSpec("document_staffs"))
+	if !ok {
+		return
+	}
+
+	query := config.DB.Unscoped().Model(&models.DocumentStaff{}).Where("deleted_at IS NOT NULL")
+
+	query = services.ScopeManagedDocumentStaffs(user, query)
+
+	var documents []models.DocumentStaff
+	meta, err := list.find(query, &documents, preload("User"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil trash dokumen"})
+		return
+	}
+
+	items := make([]gin.H, 0, len(documents))
+	for _, d := range documents {
+		items = append(items, gin.H{"document": d, "purge_at": purgeDate(d.DeletedAt)})
+	}
+
+	c.JSON(http.StatusOK, trashResponse(items, meta))
+}
+
+func RestoreDocumentStaff(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var document models.DocumentStaff
+	if err := config.DB.Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", c.Param("id")).
+		First(&document).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dokumen tidak ditemukan di trash"})
+		return
+	}
+
+	if !authorizeDocument(c, services.DocumentStaffRefOf(document), services.DocActionDelete) {
+		return
+	}
+
+	if err := restoreFromTrash(&document); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memulihkan dokumen"})
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "restore", "Memulihkan dokumen staff dari trash: "+document.FileName)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Dokumen berhasil dipulihkan",
+		"document": document,
+	})
+}
@@ -0,0 +1,343 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorLoginRequest struct {
+	ChallengeID  string `json:"challenge_id" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type TwoFactorChallengeRequest struct {
+	ChallengeID string `json:"challenge_id" binding:"required"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorPolicyRequest struct {
+	Role     string `json:"role" binding:"required"`
+	Required *bool  `json:"required" binding:"required"`
+}
+
+// twoFactorError — petakan error layanan 2FA ke status HTTP
+func twoFactorError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrChallengeInvalid),
+		errors.Is(err, services.ErrChallengeTooManyAttempt),
+		errors.Is(err, services.ErrTwoFactorCodeInvalid):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, services.ErrTwoFactorNotStarted),
+		errors.Is(err, services.ErrTwoFactorNotEnabled):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// startTwoFactorChallenge — respons login tahap pertama: challenge, bukan token
+func startTwoFactorChallenge(c *gin.Context, user models.User, mode string) {
+	token, _, err := services.CreateTwoFactorChallenge(user, mode)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Gagal memulai verifikasi 2FA"})
+		return
+	}
+
+	response := gin.H{
+		"challenge_id": token,
+		"expires_in":   int(services.TwoFactorChallengeTTL().Seconds()),
+	}
+	if mode == services.ChallengeModeEnroll {
+		response["message"] = "Role Anda wajib memakai 2FA, silakan daftarkan aplikasi authenticator"
+		response["two_factor_setup_required"] = true
+	} else {
+		response["message"] = "Masukkan kode dari aplikasi authenticator"
+		response["two_factor_required"] = true
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
+
+// =======================
+// LOGIN TAHAP KEDUA
+// =======================
+
+// LoginTwoFactorSetup — secret & URI provisioning untuk user yang wajib mendaftar 2FA saat login
+func LoginTwoFactorSetup(c *gin.Context) {
+	var input TwoFactorChallengeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "challenge_id wajib diisi"})
+		return
+	}
+
+	challenge, err := services.FindTwoFactorChallenge(input.ChallengeID)
+	if err != nil {
+		twoFactorError(c, err, "Gagal memulai pendaftaran 2FA")
+		return
+	}
+
+	secret, uri, err := services.ChallengeProvisioning(challenge)
+	if err != nil {
+		twoFactorError(c, err, "Gagal memulai pendaftaran 2FA")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":           secret,
+		"provisioning_uri": uri,
+		"issuer":           services.TOTPIssuer(),
+	})
+}
+
+// LoginTwoFactor — verifikasi kode TOTP / recovery code lalu terbitkan token.
+// Untuk challenge mode enroll, kode pertama sekaligus mengaktifkan 2FA.
+func LoginTwoFactor(c *gin.Context) {
+	var input TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "challenge_id wajib diisi"})
+		return
+	}
+	if input.Code == "" && input.RecoveryCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code atau recovery_code wajib diisi"})
+		return
+	}
+
+	challenge, err := services.FindTwoFactorChallenge(input.ChallengeID)
+	if err != nil {
+		twoFactorError(c, err, "Gagal memverifikasi 2FA")
+		return
+	}
+	user := challenge.User
+
+	if block := services.CheckLoginThrottle(user.Username, c.ClientIP()); block != nil {
+		respondLoginBlocked(c, block)
+		return
+	}
+
+	if challenge.Mode == services.ChallengeModeEnroll {
+		codes, err := services.CompleteTwoFactorEnrollment(user, challenge.PendingSecret, input.Code)
+		if errors.Is(err, services.ErrTwoFactorCodeInvalid) {
+			err = services.FailTwoFactorChallenge(challenge)
+		}
+		if err != nil {
+			twoFactorError(c, err, "Gagal mengaktifkan 2FA")
+			return
+		}
+
+		services.DeleteTwoFactorChallenge(challenge)
+		services.CreateActivity(user.ID, user.Name, "security", "Mengaktifkan 2FA saat login (wajib untuk role "+user.Role+")")
+
+		user.TOTPEnabled = true
+		completeLogin(c, user, "2FA berhasil diaktifkan, simpan recovery code di tempat aman", gin.H{
+			"recovery_codes": codes,
+		})
+		return
+	}
+
+	usedRecovery, err := services.VerifySecondFactor(user, input.Code, input.RecoveryCode)
+	if errors.Is(err, services.ErrTwoFactorCodeInvalid) {
+		recordLoginFailure(c, user, "kode 2FA salah")
+		err = services.FailTwoFactorChallenge(challenge)
+		if errors.Is(err, services.ErrChallengeTooManyAttempt) {
+			services.CreateActivity(user.ID, user.Name, "security", "Login 2FA gagal: terlalu banyak percobaan kode")
+		}
+	}
+	if err != nil {
+		twoFactorError(c, err, "Gagal memverifikasi 2FA")
+		return
+	}
+
+	services.DeleteTwoFactorChallenge(challenge)
+
+	var extra gin.H
+	if usedRecovery {
+		remaining := services.RemainingRecoveryCodes(user.ID)
+		services.CreateActivity(user.ID, user.Name, "security", "Login memakai recovery code 2FA")
+		extra = gin.H{"recovery_codes_remaining": remaining}
+	}
+
+	completeLogin(c, user, "Login berhasil", extra)
+}
+
+// =======================
+// 2FA MILIK SENDIRI
+// =======================
+func GetTwoFactorStatus(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":                  user.TOTPEnabled,
+		"enabled_at":               user.TOTPEnabledAt,
+		"required":                 services.TwoFactorRequired(user.Role),
+		"recovery_codes_remaining": services.RemainingRecoveryCodes(user.ID),
+	})
+}
+
+func SetupTwoFactor(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	secret, uri, err := services.BeginTwoFactorEnrollment(user)
+	if err != nil {
+		twoFactorError(c, err, "Gagal memulai pendaftaran 2FA")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Pindai QR / masukkan secret di aplikasi authenticator, lalu konfirmasi dengan kode",
+		"secret":           secret,
+		"provisioning_uri": uri,
+		"issuer":           services.TOTPIssuer(),
+	})
+}
+
+func EnableTwoFactor(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var input TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code wajib diisi"})
+		return
+	}
+
+	codes, err := services.CompleteTwoFactorEnrollment(user, "", input.Code)
+	if err != nil {
+		twoFactorError(c, err, "Gagal mengaktifkan 2FA")
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "security", "Mengaktifkan 2FA")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "2FA berhasil diaktifkan, simpan recovery code di tempat aman",
+		"recovery_codes": codes,
+	})
+}
+
+// RegenerateRecoveryCodes — wajib konfirmasi kode TOTP saat ini
+func RegenerateRecoveryCodes(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var input TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code wajib diisi"})
+		return
+	}
+
+	if err := services.VerifyUserTOTP(user, input.Code); err != nil {
+		twoFactorError(c, err, "Gagal memverifikasi 2FA")
+		return
+	}
+
+	codes, err := services.RegenerateRecoveryCodes(user)
+	if err != nil {
+		twoFactorError(c, err, "Gagal membuat recovery code")
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "security", "Membuat ulang recovery code 2FA")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Recovery code baru berhasil dibuat, kode lama tidak berlaku lagi",
+		"recovery_codes": codes,
+	})
+}
+
+// =======================
+// KEBIJAKAN & RESET (superadmin)
+// =======================
+func GetTwoFactorPolicies(c *gin.Context) {
+	policies, err := services.TwoFactorPolicies()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil kebijakan 2FA"})
+		return
+	}
+
+	var roles []string
+	config.DB.Model(&models.Role{}).Order("name").Pluck("name", &roles)
+
+	required := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		required[role] = false
+	}
+	for _, p := range policies {
+		required[p.Role] = p.Required
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"policies": policies,
+		"required": required,
+	})
+}
+
+func UpdateTwoFactorPolicy(c *gin.Context) {
+	admin := c.MustGet("user").(models.User)
+
+	var input TwoFactorPolicyRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role dan required wajib diisi"})
+		return
+	}
+	if !services.RoleExists(input.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role tidak valid"})
+		return
+	}
+
+	policy, err := services.SetTwoFactorPolicy(input.Role, *input.Required, admin.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan kebijakan 2FA"})
+		return
+	}
+
+	status := "tidak wajib"
+	if policy.Required {
+		status = "wajib"
+	}
+	services.CreateActivity(admin.ID, admin.Name, "security", "Mengatur 2FA "+status+" untuk role "+policy.Role)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Kebijakan 2FA berhasil disimpan",
+		"policy":  policy,
+	})
+}
+
+// ResetUserTwoFactor — nonaktifkan 2FA user (mis. perangkat hilang). Semua sesi user ikut dicabut.
+func ResetUserTwoFactor(c *gin.Context) {
+	admin := c.MustGet("user").(models.User)
+
+	var target models.User
+	if err := config.DB.Select("id", "name", "role", "totp_enabled").First(&target, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return
+	}
+	if !canManageUser(c, admin, target) {
+		return
+	}
+
+	if err := services.ResetTwoFactor(target.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mereset 2FA"})
+		return
+	}
+	if _, err := services.RevokeUserSessions(target.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mencabut sesi user"})
+		return
+	}
+
+	services.CreateActivity(admin.ID, admin.Name, "security", "Mereset 2FA user: "+target.Name)
+	services.NotifySpecificUser(target.ID, "2FA akun Anda telah direset oleh superadmin", "/profile")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "2FA user berhasil direset",
+		"was_enabled": target.TOTPEnabled,
+	})
+}
@@ -0,0 +1,259 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UnitRequest struct {
+	Name       *string `json:"name"`
+	Code       *string `json:"code"`
+	ParentID   *string `json:"parent_id"`
+	HeadUserID *string `json:"head_user_id"`
+}
+
+func (r UnitRequest) input() services.UnitInput {
+	return services.UnitInput{
+		Name:       r.Name,
+		Code:       r.Code,
+		ParentID:   r.ParentID,
+		HeadUserID: r.HeadUserID,
+	}
+}
+
+type UnitMembersRequest struct {
+	UserIDs []string `json:"user_ids" binding:"required,min=1"`
+}
+
+// UnitResponse — unit beserta kepala unit, kedalaman hierarki & jumlah anggota
+type UnitResponse struct {
+	models.OrgUnit
+	Depth       int          `json:"depth"`
+	Head        *models.User `json:"head,omitempty"`
+	MemberCount int64        `json:"member_count"`
+}
+
+func unitResponse(unit models.OrgUnit) UnitResponse {
+	response := UnitResponse{
+		OrgUnit: unit,
+		Depth:   strings.Count(strings.Trim(unit.Path, "/"), "/"),
+	}
+
+	if unit.HeadUserID != nil {
+		var head models.User
+		if err := config.DB.Scopes(selectUserSummary).First(&head, "id = ?", *unit.HeadUserID).Error; err == nil {
+			response.Head = &head
+		}
+	}
+	config.DB.Model(&models.User{}).Where("unit_id = ?", unit.ID).Count(&response.MemberCount)
+	return response
+}
+
+func unitError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrUnitNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnitCodeExists),
+		errors.Is(err, services.ErrUnitInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnitNameRequired),
+		errors.Is(err, services.ErrUnitParentNotFound),
+		errors.Is(err, services.ErrUnitParentInvalid),
+		errors.Is(err, services.ErrUnitHeadNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// =======================
+// DAFTAR & DETAIL UNIT
+// =======================
+func GetUnits(c *gin.Context) {
+	var units []models.OrgUnit
+	// urut path agar induk selalu muncul sebelum turunannya
+	if err := config.DB.Order("path").Find(&units).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil daftar unit"})
+		return
+	}
+
+	items := make([]UnitResponse, 0, len(units))
+	for _, unit := range units {
+		items = append(items, unitResponse(unit))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"units": items,
+		"total": len(items),
+	})
+}
+
+func GetUnit(c *gin.Context) {
+	unit, err := services.FindUnit(c.Param("id"))
+	if err != nil {
+		unitError(c, err, "Gagal mengambil unit")
+		return
+	}
+
+	var children []models.OrgUnit
+	config.DB.Where("parent_id = ?", unit.ID).Order("name").Find(&children)
+
+	c.JSON(http.StatusOK, gin.H{
+		"unit":     unitResponse(unit),
+		"children": children,
+	})
+}
+
+func GetUnitMembers(c *gin.Context) {
+	unit, err := services.FindUnit(c.Param("id"))
+	if err != nil {
+		unitError(c, err, "Gagal mengambil anggota unit")
+		return
+	}
+
+	var members []models.User
+	if err := config.DB.Scopes(selectUserSummary).
+		Where("unit_id = ?", unit.ID).
+		Order("name").
+		Find(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil anggota unit"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"unit_id": unit.ID,
+		"members": members,
+		"total":   len(members),
+	})
+}
+
+// =======================
+// KELOLA UNIT (unit.manage)
+// =======================
+func CreateUnit(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req UnitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	unit, err := services.CreateUnit(req.input())
+	if err != nil {
+		unitError(c, err, "Gagal membuat unit")
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "create", "Membuat unit: "+unit.Name)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Unit berhasil dibuat",
+		"unit":    unitResponse(unit),
+	})
+}
+
+func UpdateUnit(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req UnitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	unit, err := services.UpdateUnit(c.Param("id"), req.input())
+	if err != nil {
+		unitError(c, err, "Gagal memperbarui unit")
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "update", "Memperbarui unit: "+unit.Name)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Unit berhasil diperbarui",
+		"unit":    unitResponse(unit),
+	})
+}
+
+func DeleteUnit(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	unit, err := services.FindUnit(c.Param("id"))
+	if err != nil {
+		unitError(c, err, "Gagal menghapus unit")
+		return
+	}
+
+	if err := services.DeleteUnit(unit.ID); err != nil {
+		unitError(c, err, "Gagal menghapus unit")
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "delete", "Menghapus unit: "+unit.Name)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Unit berhasil dihapus"})
+}
+
+// AddUnitMembers — pindahkan user ke unit ini
+func AddUnitMembers(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req UnitMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	unit, err := services.FindUnit(c.Param("id"))
+	if err != nil {
+		unitError(c, err, "Gagal menambahkan anggota unit")
+		return
+	}
+
+	affected, err := services.AssignUsersToUnit(unit.ID, req.UserIDs)
+	if err != nil {
+		unitError(c, err, "Gagal menambahkan anggota unit")
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "update", "Menambahkan anggota ke unit: "+unit.Name)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Anggota unit berhasil diperbarui",
+		"updated": affected,
+	})
+}
+
+// RemoveUnitMember — keluarkan user dari unit
+func RemoveUnitMember(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	unit, err := services.FindUnit(c.Param("id"))
+	if err != nil {
+		unitError(c, err, "Gagal mengeluarkan anggota unit")
+		return
+	}
+
+	var member models.User
+	if err := config.DB.First(&member, "id = ? AND unit_id = ?", c.Param("user_id"), unit.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User bukan anggota unit ini"})
+		return
+	}
+
+	if _, err := services.AssignUsersToUnit("", []string{member.ID}); err != nil {
+		unitError(c, err, "Gagal mengeluarkan anggota unit")
+		return
+	}
+
+	services.CreateActivity(user.ID, user.Name, "update", "Mengeluarkan "+member.Name+" dari unit: "+unit.Name)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Anggota berhasil dikeluarkan dari unit"})
+}
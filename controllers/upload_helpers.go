@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// documentUploadTarget — surat dinas menerima semua format, gambar dipisah ke folder gambar
+func documentUploadTarget(fileName string) (resourceType, folder string) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp":
+		return "image", "gambar"
+	default:
+		return "raw", "arsip"
+	}
+}
+
+// staffUploadTarget — dokumen staff hanya menerima gambar dan dokumen office/PDF
+func staffUploadTarget(fileName string) (resourceType, folder string, ok bool) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp":
+		return "image", "gambar", true
+	case ".pdf", ".doc", ".docx", ".xls", ".xlsx", ".ppt", ".pptx":
+		return "raw", "document_staff", true
+	default:
+		return "", "", false
+	}
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -14,26 +15,48 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
-var allowedRoles = map[string]bool{
-	"superadmin": true,
-	"admin":      true,
-	"staff":      true,
-}
-
 type StorePushTokenRequest struct {
 	Token string `json:"token"`
 }
 
-func hashPassword(pass string) (string, error) {
-	hashed, err := bcrypt.GenerateFromPassword([]byte(pass), bcrypt.DefaultCost)
-	return string(hashed), err
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// canManageUser — akun superadmin hanya boleh dikelola sesama superadmin,
+// walau role lain diberi izin user.manage
+func canManageUser(c *gin.Context, currentUser, target models.User) bool {
+	if target.Role == services.RoleSuperadmin && currentUser.Role != services.RoleSuperadmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Hanya superadmin yang dapat mengelola akun superadmin"})
+		return false
+	}
+	return true
+}
+
+// passwordError — pelanggaran kebijakan / pemakaian ulang = 400, selain itu 500
+func passwordError(c *gin.Context, err error) {
+	var violation *services.PasswordPolicyViolation
+	if errors.As(err, &violation) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": violation.Error(),
+			"rules": violation.Rules,
+		})
+		return
+	}
+	if errors.Is(err, services.ErrPasswordReused) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah password"})
 }
 
 // CREATE USERS
 func CreateUserWithRole(c *gin.Context, role string) {
-	if !allowedRoles[role] {
+	if !services.RoleExists(role) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Role tidak valid"})
 		return
 	}
@@ -47,17 +70,38 @@ func CreateUserWithRole(c *gin.Context, role string) {
 	input.ID = uuid.NewString()
 	input.Role = role
 
-	hashed, err := hashPassword(input.Password)
+	// 2FA hanya bisa diaktifkan oleh user sendiri lewat alur pendaftaran
+	input.TOTPSecret = ""
+	input.TOTPEnabled = false
+	input.TOTPEnabledAt = nil
+	input.TOTPLastStep = 0
+
+	if err := services.ValidatePassword(input.Password); err != nil {
+		passwordError(c, err)
+		return
+	}
+
+	hashed, err := services.HashPassword(input.Password)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengenkripsi password"})
 		return
 	}
 	input.Password = hashed
 
-	if err := config.DB.Create(&input).Error; err != nil {
+	now := time.Now()
+	input.PasswordChangedAt = &now
+	input.PasswordExpiresAt = nil
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&input).Error; err != nil {
+			return err
+		}
+		return services.RecordPasswordHistory(tx, input.ID, hashed)
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat user"})
 		return
 	}
+	input.Password = ""
 
 	currentUser := c.MustGet("user").(models.User)
 	services.CreateActivity(
@@ -78,19 +122,44 @@ func CreateAdmin(c *gin.Context)      { CreateUserWithRole(c, "admin") }
 func CreateStaff(c *gin.Context)      { CreateUserWithRole(c, "staff") }
 
 // READ ALL USERS
+var userListSpec = listSpec{
+	Table: "users",
+	Sorts: map[string]listSort{
+		"created_at": {Column: "created_at"},
+		"name":       {Column: "name"},
+		"username":   {Column: "username"},
+		"role":       {Column: "role"},
+	},
+	DefaultSort:  "created_at",
+	DefaultOrder: "desc",
+	DateColumn:   "created_at",
+}
+
 func GetUsers(c *gin.Context) {
-	var users []models.User
+	list, ok := parseListQuery(c, userListSpec)
+	if !ok {
+		return
+	}
 
-	result := config.DB.Select("id", "name", "username", "role", "created_at", "updated_at", "photo_url").
-		Order("created_at DESC").
-		Find(&users)
+	query := config.DB.Model(&models.User{})
 
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data users: " + result.Error.Error()})
+	if role := c.Query("role"); role != "" && role != "all" {
+		query = query.Where("role = ?", role)
+	}
+	if search := c.Query("search"); search != "" {
+		s := "%" + search + "%"
+		query = query.Where("name LIKE ? OR username LIKE ?", s, s)
+	}
+
+	var users []models.User
+	meta, err := list.find(query, &users,
+		selectColumns("id", "name", "username", "role", "created_at", "updated_at", "photo_url"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data users: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, users)
+	c.JSON(http.StatusOK, listResponse(users, meta))
 }
 
 // READ BY ID
@@ -115,9 +184,10 @@ func GetUsersForFilter(c *gin.Context) {
 		Name string `json:"name"`
 	}
 
+	// user yang role-nya boleh mengakses dokumen, termasuk role buatan lewat API
 	result := config.DB.Model(&models.User{}).
 		Select("id", "name").
-		Where("role IN ?", []string{"staff", "admin", "superadmin"}). // Sesuaikan dengan role yang diinginkan
+		Where("role IN ?", services.RolesWithPermission(services.PermDocumentView)).
 		Order("name ASC").
 		Find(&users)
 
@@ -143,11 +213,16 @@ func GetMe(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"user": gin.H{
-			"id":        user.ID,
-			"name":      user.Name,
-			"username":  user.Username,
-			"role":      user.Role,
-			"photo_url": user.PhotoURL,
+			"id":                   user.ID,
+			"name":                 user.Name,
+			"username":             user.Username,
+			"role":                 user.Role,
+			"photo_url":            user.PhotoURL,
+			"totp_enabled":         user.TOTPEnabled,
+			"must_change_password": user.MustChangePassword,
+			"permissions":          services.UserPermissions(user),
+			"unit_id":              user.UnitID,
+			"headed_unit_ids":      services.HeadedUnitIDs(user.ID),
 		},
 	})
 }
@@ -195,7 +270,21 @@ func UpdateUser(c *gin.Context) {
 		updates["username"] = input.Username
 	}
 
-	if input.Role != "" {
+	// ganti role hanya oleh pemilik izin user.manage; role superadmin hanya diberikan superadmin
+	if input.Role != "" && input.Role != user.Role {
+		currentUser := c.MustGet("user").(models.User)
+		if !services.HasPermission(currentUser, services.PermUserManage) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak memiliki izin mengubah role"})
+			return
+		}
+		if !services.RoleExists(input.Role) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Role tidak valid"})
+			return
+		}
+		if (input.Role == services.RoleSuperadmin || user.Role == services.RoleSuperadmin) && currentUser.Role != services.RoleSuperadmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Hanya superadmin yang dapat mengubah role superadmin"})
+			return
+		}
 		updates["role"] = input.Role
 	}
 
@@ -212,18 +301,12 @@ func UpdateUser(c *gin.Context) {
 			return
 		}
 
-		if len(newPassword) < 6 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Password baru minimal 6 karakter"})
+		// kebijakan password, riwayat & status wajib ganti ditangani layanan password
+		if err := services.ChangePassword(user, newPassword); err != nil {
+			passwordError(c, err)
 			return
 		}
-
-		hashed, err := hashPassword(newPassword)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengenkripsi password"})
-			return
-		}
-
-		updates["password"] = hashed
+		services.RevokeOtherSessions(user.ID, currentSessionID(c))
 	}
 
 	// Photo upload
@@ -252,7 +335,7 @@ func UpdateUser(c *gin.Context) {
 		timestamp := time.Now().Unix()
 		uniqueFileName := fmt.Sprintf("user-%s-%d%s", userID[:8], timestamp, ext)
 
-		uploadRes, err := config.UploadToCloudinary(f, uniqueFileName, "users", "image")
+		uploadRes, err := config.FileStorage.Upload(f, uniqueFileName, config.PublicStorageFolder, "image")
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal upload foto: " + err.Error()})
 			return
@@ -261,11 +344,11 @@ func UpdateUser(c *gin.Context) {
 		if user.PhotoID != nil && *user.PhotoID != "" {
 
 			if *user.PhotoID != uploadRes.PublicID {
-				config.DeleteFromCloudinary(*user.PhotoID, "image")
+				config.FileStorage.Delete(*user.PhotoID, "image")
 			}
 		}
 
-		updates["photo_url"] = uploadRes.SecureURL
+		updates["photo_url"] = uploadRes.URL
 		updates["photo_id"] = uploadRes.PublicID
 	}
 
@@ -297,8 +380,8 @@ func ResetPassword(c *gin.Context) {
 	id := c.Param("id")
 
 	currentUser := c.MustGet("user").(models.User)
-	if currentUser.Role != "superadmin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Hanya superadmin yang dapat reset password"})
+	if !services.HasPermission(currentUser, services.PermUserManage) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak memiliki izin reset password"})
 		return
 	}
 
@@ -308,14 +391,13 @@ func ResetPassword(c *gin.Context) {
 		return
 	}
 
-	defaultPassword := "123456"
-	hashed, err := hashPassword(defaultPassword)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengenkripsi password"})
+	if !canManageUser(c, currentUser, user) {
 		return
 	}
 
-	if err := config.DB.Model(&user).Update("password", hashed).Error; err != nil {
+	// password sementara acak, berlaku terbatas dan wajib diganti saat login berikutnya
+	temporary, expiresAt, err := services.ResetPasswordTemporary(user.ID)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal reset password"})
 		return
 	}
@@ -323,15 +405,53 @@ func ResetPassword(c *gin.Context) {
 	services.CreateActivity(
 		currentUser.ID,
 		currentUser.Name,
-		"update",
-		"Reset password user: "+user.Name+" ke default",
+		"security",
+		"Reset password user: "+user.Name+" dengan password sementara (semua sesi dicabut)",
 	)
 
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"message":              "Password berhasil direset. Serahkan password sementara ini ke user; password hanya ditampilkan sekali",
+		"temporary_password":   temporary,
+		"expires_at":           expiresAt,
+		"must_change_password": true,
+	})
+}
+
+// CHANGE PASSWORD (user sendiri, termasuk saat wajib ganti password)
+func ChangeMyPassword(c *gin.Context) {
+	currentUser := c.MustGet("user").(models.User)
+
+	var input ChangePasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password lama dan baru harus diisi"})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(currentUser.Password), []byte(input.OldPassword)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password lama salah"})
+		return
+	}
+
+	if err := services.ChangePassword(currentUser, input.NewPassword); err != nil {
+		passwordError(c, err)
+		return
+	}
+
+	services.RevokeOtherSessions(currentUser.ID, currentSessionID(c))
+	services.CreateActivity(currentUser.ID, currentUser.Name, "security", "Mengganti password")
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Password berhasil direset ke default (123456)",
+		"message":              "Password berhasil diganti",
+		"must_change_password": false,
 	})
 }
 
+// PASSWORD POLICY
+func GetPasswordPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"policy": services.CurrentPasswordPolicy()})
+}
+
 // DELETE USERS
 func DeleteUser(c *gin.Context) {
 	id := c.Param("id")
@@ -342,12 +462,16 @@ func DeleteUser(c *gin.Context) {
 		return
 	}
 
+	currentUser := c.MustGet("user").(models.User)
+	if !canManageUser(c, currentUser, user) {
+		return
+	}
+
 	if err := config.DB.Delete(&models.User{}, "id = ?", id).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus user"})
 		return
 	}
 
-	currentUser := c.MustGet("user").(models.User)
 	services.CreateActivity(
 		currentUser.ID,
 		currentUser.Name,
@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/gin-gonic/gin"
 
@@ -10,6 +11,7 @@ import (
 	"dinsos_kuburaya/middleware"
 	"dinsos_kuburaya/models"
 	"dinsos_kuburaya/routes"
+	"dinsos_kuburaya/services"
 	"dinsos_kuburaya/utils"
 
 	ws "dinsos_kuburaya/websocket"
@@ -20,24 +22,70 @@ func main() {
 	r.MaxMultipartMemory = 100 << 20
 
 	config.ConnectDatabase()
+	config.InitStorage()
 	utils.StartActivityLogCleaner()
 	utils.StartNotificationCleaner()
+	utils.StartTrashPurger()
+	utils.StartLoginThrottleCleaner()
 
-	if err := config.DB.AutoMigrate(
+	if err := config.Migrate(
+		&models.Role{},
+		&models.RolePermission{},
+		&models.Permission{},
+		&models.OrgUnit{},
 		&models.User{},
 		&models.Document{},
 		&models.SecretToken{},
+		&models.RefreshToken{},
+		&models.RecoveryCode{},
+		&models.TwoFactorChallenge{},
+		&models.TwoFactorPolicy{},
+		&models.LoginThrottle{},
+		&models.PasswordHistory{},
+		&models.SubmissionRequest{},
+		&models.SubmissionTarget{},
+		&models.Employee{},
 		&models.DocumentStaff{},
+		&models.DocumentStaffReview{},
+		&models.DocumentComment{},
+		&models.DocumentCommentMention{},
+		&models.DocumentShare{},
+		&models.ShareLink{},
+		&models.ShareLinkAccess{},
+		&models.DocumentDownload{},
+		&models.ExportJob{},
 		&models.Notification{},
 		&models.ActivityLog{},
+		&models.AgendaCounter{},
+		&models.Disposition{},
+		&models.DispositionNote{},
+		&models.DocumentVersion{},
 	); err != nil {
 		log.Fatal("Gagal migrasi tabel:", err)
 	}
 
+	if err := services.SeedRoles(); err != nil {
+		log.Fatal("Gagal menyiapkan role default:", err)
+	}
+
+	if err := config.EnsureFullTextIndexes(); err != nil {
+		log.Fatal("Gagal membuat indeks pencarian:", err)
+	}
+
+	utils.StartLegacyAssetRestriction()
+	utils.StartTextExtractor()
+	utils.StartExportCleaner()
+	utils.StartSubmissionReminder()
+
 	r.Use(middleware.RateLimiter())
 	r.Use(middleware.CORSMiddleware())
 	r.Use(middleware.XSSBlocker())
 
+	// hanya foto profil yang disajikan publik; file dokumen wajib lewat endpoint unduhan
+	if local, ok := config.FileStorage.(*config.LocalStorage); ok {
+		r.Static(local.Route+"/"+config.PublicStorageFolder, filepath.Join(local.BaseDir, config.PublicStorageFolder))
+	}
+
 	websocketHub := ws.NewHub()
 	go websocketHub.Run()
 
@@ -48,9 +96,20 @@ func main() {
 		routes.WebSocketRoutes(api, websocketHub)
 		routes.LoginRoutes(api)
 		routes.LogoutRoutes(api)
+		routes.SessionRoutes(api)
+		routes.TwoFactorRoutes(api)
 		routes.UserRoutes(api)
+		routes.RoleRoutes(api)
+		routes.UnitRoutes(api)
 		routes.DocumentRoutes(api)
 		routes.DocumentStaffRoutes(api)
+		routes.SubmissionRoutes(api)
+		routes.EmployeeRoutes(api)
+		routes.DispositionRoutes(api)
+		routes.ShareRoutes(api)
+		routes.DownloadRoutes(api)
+		routes.ExportRoutes(api)
+		routes.SearchRoutes(api)
 		routes.NotificationRoutes(api)
 		routes.ActivityLogRoutes(api)
 	}
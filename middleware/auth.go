@@ -3,6 +3,7 @@ package middleware
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"net/http"
 	"os"
 	"strings"
@@ -10,11 +11,20 @@ import (
 
 	"dinsos_kuburaya/config"
 	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// route yang tetap boleh diakses selama user wajib mengganti password
+var passwordChangeRoutes = map[string]bool{
+	"/api/users/me":              true,
+	"/api/users/me/password":     true,
+	"/api/users/password-policy": true,
+	"/api/logout":                true,
+}
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 
@@ -45,15 +55,20 @@ func AuthMiddleware() gin.HandlerFunc {
 			return []byte(secret), nil
 		})
 
+		// access token kedaluwarsa tidak menghapus sesi — klien memperbarui lewat /token/refresh
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "token expired"})
+			c.Abort()
+			return
+		}
+
 		if err != nil {
-			config.DB.Where("jwt_token = ?", hashedToken).Delete(&models.SecretToken{})
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "token invalid"})
 			c.Abort()
 			return
 		}
 
 		if !token.Valid {
-			config.DB.Where("jwt_token = ?", hashedToken).Delete(&models.SecretToken{})
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "token invalid"})
 			c.Abort()
 			return
@@ -61,32 +76,21 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok {
-			config.DB.Where("jwt_token = ?", hashedToken).Delete(&models.SecretToken{})
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token claims"})
 			c.Abort()
 			return
 		}
 
-		// 4. Validasi exp claim
-		exp, ok := claims["exp"].(float64)
-		if !ok {
-			config.DB.Where("jwt_token = ?", hashedToken).Delete(&models.SecretToken{})
+		// 4. Validasi exp claim (masa berlakunya sudah diperiksa jwt.Parse)
+		if _, ok := claims["exp"].(float64); !ok {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid exp claim"})
 			c.Abort()
 			return
 		}
 
-		if time.Now().Unix() > int64(exp) {
-			config.DB.Where("jwt_token = ?", hashedToken).Delete(&models.SecretToken{})
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "token expired"})
-			c.Abort()
-			return
-		}
-
 		// 5. Validasi user_id claim
 		userID, ok := claims["user_id"].(string)
 		if !ok || userID == "" {
-			config.DB.Where("jwt_token = ?", hashedToken).Delete(&models.SecretToken{})
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user_id claim"})
 			c.Abort()
 			return
@@ -116,7 +120,27 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 9. Token harus milik sesi yang tercatat di klaim
+		if sid, ok := claims["sid"].(string); ok && sid != st.ID {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "token invalid"})
+			c.Abort()
+			return
+		}
+
+		// 10. User dengan password sementara hanya boleh mengganti password
+		if st.User.MustChangePassword && !passwordChangeRoutes[c.FullPath()] {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":                "Anda wajib mengganti password sebelum melanjutkan",
+				"must_change_password": true,
+			})
+			c.Abort()
+			return
+		}
+
+		services.TouchSession(st)
+
 		c.Set("user", st.User)
+		c.Set("session", st)
 		c.Next()
 	}
 }
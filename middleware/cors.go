@@ -13,8 +13,8 @@ func CORSMiddleware() gin.HandlerFunc {
 			"https://dinsos-frontend-s67t.vercel.app",
 		},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Device"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Device", "X-Share-Password", "Range"},
+		ExposeHeaders:    []string{"Content-Length", "Content-Disposition", "Content-Range", "Accept-Ranges"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}
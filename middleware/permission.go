@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission — izinkan hanya user yang role-nya memiliki izin tersebut
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		userRaw, exists := c.Get("user")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		user := userRaw.(models.User)
+
+		if !services.HasPermission(user, permission) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"message":    "Akses ditolak, Anda tidak memiliki izin " + permission,
+				"permission": permission,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,35 +0,0 @@
-package middleware
-
-import (
-	"net/http"
-
-	"dinsos_kuburaya/models"
-
-	"github.com/gin-gonic/gin"
-)
-
-func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-
-		userRaw, exists := c.Get("user")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
-			c.Abort()
-			return
-		}
-
-		user := userRaw.(models.User)
-
-		for _, role := range allowedRoles {
-			if user.Role == role {
-				c.Next()
-				return
-			}
-		}
-
-		c.JSON(http.StatusForbidden, gin.H{
-			"message": "Akses ditolak, role tidak sesuai",
-		})
-		c.Abort()
-	}
-}
@@ -4,12 +4,13 @@ import (
 	"net/http"
 
 	"dinsos_kuburaya/models"
+	"dinsos_kuburaya/services"
 
 	"github.com/gin-gonic/gin"
 )
 
-// Hanya superadmin boleh akses user lain
-// admin & staff hanya boleh akses dirinya sendiri
+// Hanya pemilik izin user.manage boleh akses user lain,
+// selain itu hanya boleh akses dirinya sendiri
 func UserSelfOrSuperAdmin() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRaw, exists := c.Get("user")
@@ -22,7 +23,7 @@ func UserSelfOrSuperAdmin() gin.HandlerFunc {
 		user := userRaw.(models.User)
 		targetID := c.Param("id")
 
-		if user.Role == "superadmin" {
+		if services.HasPermission(user, services.PermUserManage) {
 			c.Next()
 			return
 		}
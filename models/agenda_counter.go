@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// AgendaCounter — nomor urut agenda terakhir per tahun dan jenis surat.
+// Nilai hanya naik, sehingga nomor yang sudah terpakai tidak pernah dipakai ulang.
+type AgendaCounter struct {
+	Year       int       `gorm:"primaryKey;autoIncrement:false" json:"year"`
+	LetterType string    `gorm:"type:varchar(10);primaryKey" json:"letter_type"`
+	LastNumber int       `gorm:"not null;default:0" json:"last_number"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
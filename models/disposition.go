@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Disposition — disposisi surat masuk dari satu user ke user lain.
+// Penerusan (forward) membuat disposisi baru dengan ParentID menunjuk ke disposisi asal.
+type Disposition struct {
+	ID             string            `gorm:"type:char(36);primaryKey" json:"id"`
+	DocumentID     string            `gorm:"type:char(36);not null;index" json:"document_id"`
+	Document       *Document         `gorm:"foreignKey:DocumentID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"document,omitempty"`
+	ParentID       *string           `gorm:"type:char(36);index" json:"parent_id"`
+	FromUserID     string            `gorm:"type:char(36);not null" json:"from_user_id"`
+	FromUser       User              `gorm:"foreignKey:FromUserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"from_user"`
+	ToUserID       string            `gorm:"type:char(36);not null;index" json:"to_user_id"`
+	ToUser         User              `gorm:"foreignKey:ToUserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"to_user"`
+	Instruction    string            `gorm:"type:text" json:"instruction"`
+	DueDate        *time.Time        `gorm:"type:date" json:"due_date"`
+	Priority       string            `gorm:"type:enum('rendah','sedang','tinggi');default:'sedang'" json:"priority"`
+	Status         string            `gorm:"type:enum('pending','acknowledged','in_progress','forwarded','done');default:'pending';index" json:"status"`
+	AcknowledgedAt *time.Time        `json:"acknowledged_at"`
+	CompletedAt    *time.Time        `json:"completed_at"`
+	Notes          []DispositionNote `gorm:"foreignKey:DispositionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"notes,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// DispositionNote — catatan progres pada sebuah disposisi
+type DispositionNote struct {
+	ID            string    `gorm:"type:char(36);primaryKey" json:"id"`
+	DispositionID string    `gorm:"type:char(36);not null;index" json:"disposition_id"`
+	UserID        string    `gorm:"type:char(36);not null" json:"user_id"`
+	User          User      `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user"`
+	Note          string    `gorm:"type:text;not null" json:"note"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Generate UUID
+func (d *Disposition) BeforeCreate(tx *gorm.DB) (err error) {
+	d.ID = uuid.NewString()
+	return
+}
+
+// Generate UUID
+func (n *DispositionNote) BeforeCreate(tx *gorm.DB) (err error) {
+	n.ID = uuid.NewString()
+	return
+}
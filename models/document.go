@@ -9,7 +9,8 @@ import (
 
 type Document struct {
 	ID           string    `gorm:"type:char(36);primaryKey" json:"id"`
-	FileURL      string    `gorm:"type:text" json:"file_url"`
+	FileURL      string    `gorm:"type:text" json:"-"` // URL storage tidak pernah dikirim ke klien
+	DownloadURL  string    `gorm:"-" json:"download_url"`
 	Sender       string    `gorm:"type:varchar(255)" json:"sender"`
 	FileName     string    `gorm:"type:varchar(255)" json:"file_name"`
 	Subject      string    `gorm:"type:varchar(255)" json:"subject"`
@@ -18,8 +19,37 @@ type Document struct {
 	User         User      `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"user"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
-	PublicID     string    `gorm:"type:varchar(255)" json:"public_id"`
-	ResourceType string    `gorm:"type:varchar(50)" json:"resource_type"`
+	PublicID     string    `gorm:"type:varchar(255)" json:"-"`
+	ResourceType string    `gorm:"type:varchar(50)" json:"-"`
+
+	// Unit pemilik surat. Surat tanpa unit berlaku untuk seluruh kantor.
+	UnitID *string  `gorm:"type:char(36);index" json:"unit_id"`
+	Unit   *OrgUnit `gorm:"foreignKey:UnitID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"unit,omitempty"`
+
+	// Metadata surat dinas untuk buku agenda
+	LetterNumber       string     `gorm:"type:varchar(100);index" json:"letter_number"`
+	LetterDate         *time.Time `gorm:"type:date" json:"letter_date"`
+	ReceivedDate       *time.Time `gorm:"type:date" json:"received_date"` // tanggal diterima (masuk) / dikirim (keluar)
+	Recipient          string     `gorm:"type:varchar(255)" json:"recipient"`
+	ClassificationCode string     `gorm:"type:varchar(50);index" json:"classification_code"`
+	Urgency            string     `gorm:"type:enum('biasa','segera','sangat_segera');default:'biasa'" json:"urgency"`
+	Confidentiality    string     `gorm:"type:enum('biasa','terbatas','rahasia','sangat_rahasia');default:'biasa'" json:"confidentiality"`
+
+	// Nomor agenda otomatis (lihat services.AssignAgendaNumber)
+	AgendaYear     *int   `gorm:"index" json:"agenda_year"`
+	AgendaSequence *int   `json:"agenda_sequence"`
+	AgendaNumber   string `gorm:"type:varchar(150);index" json:"agenda_number"`
+
+	// Soft delete — file fisik baru dihapus saat trash di-purge
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	DeletedByID *string        `gorm:"type:char(36)" json:"deleted_by_id,omitempty"`
+
+	// Isi teks hasil ekstraksi/OCR di latar belakang (lihat services.QueueExtraction)
+	ExtractedText    string     `gorm:"type:longtext" json:"-"`
+	ExtractionStatus string     `gorm:"type:varchar(20);index" json:"extraction_status"`
+	ExtractionMethod string     `gorm:"type:varchar(20)" json:"extraction_method,omitempty"`
+	ExtractionError  string     `gorm:"type:varchar(500)" json:"extraction_error,omitempty"`
+	ExtractedAt      *time.Time `json:"extracted_at"`
 }
 
 // Generate UUID
@@ -27,3 +57,13 @@ func (d *Document) BeforeCreate(tx *gorm.DB) (err error) {
 	d.ID = uuid.NewString()
 	return
 }
+
+// Endpoint unduhan terautentikasi, pengganti URL file publik
+func (d *Document) AfterFind(tx *gorm.DB) (err error) {
+	d.DownloadURL = "/api/documents/" + d.ID + "/download"
+	return
+}
+
+func (d *Document) AfterCreate(tx *gorm.DB) (err error) {
+	return d.AfterFind(tx)
+}
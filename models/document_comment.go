@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DocumentComment — komentar berutas pada surat atau dokumen staf.
+// DocumentType memakai nilai yang sama dengan riwayat versi ("document" / "document_staff");
+// ParentID menunjuk komentar yang dibalas.
+type DocumentComment struct {
+	ID           string                   `gorm:"type:char(36);primaryKey" json:"id"`
+	DocumentType string                   `gorm:"type:varchar(20);not null;index:idx_document_comment,priority:1" json:"document_type"`
+	DocumentID   string                   `gorm:"type:char(36);not null;index:idx_document_comment,priority:2" json:"document_id"`
+	ParentID     *string                  `gorm:"type:char(36);index" json:"parent_id"`
+	UserID       *string                  `gorm:"type:char(36);index" json:"user_id"`
+	User         *User                    `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"user,omitempty"`
+	Body         string                   `gorm:"type:text;not null" json:"body"`
+	Mentions     []DocumentCommentMention `gorm:"foreignKey:CommentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"mentions"`
+	EditedAt     *time.Time               `json:"edited_at"`
+	CreatedAt    time.Time                `json:"created_at"`
+	UpdatedAt    time.Time                `json:"updated_at"`
+
+	// Soft delete — komentar yang dihapus tetap tampil sebagai penanda agar utas balasan utuh
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	DeletedByID *string        `gorm:"type:char(36)" json:"deleted_by_id,omitempty"`
+}
+
+// DocumentCommentMention — user yang di-@mention dalam komentar
+type DocumentCommentMention struct {
+	ID        uint   `gorm:"primaryKey" json:"-"`
+	CommentID string `gorm:"type:char(36);not null;uniqueIndex:idx_comment_mention" json:"-"`
+	UserID    string `gorm:"type:char(36);not null;uniqueIndex:idx_comment_mention;index" json:"user_id"`
+	User      User   `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user"`
+}
+
+// Generate UUID
+func (c *DocumentComment) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.NewString()
+	return
+}
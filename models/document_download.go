@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// DocumentDownload — jejak setiap unduhan file surat / dokumen staf,
+// baik lewat endpoint terautentikasi, URL bertanda tangan, tautan publik, maupun ekspor ZIP
+type DocumentDownload struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	DocumentType string    `gorm:"type:varchar(20);not null;index:idx_document_download,priority:1" json:"document_type"`
+	DocumentID   string    `gorm:"type:char(36);not null;index:idx_document_download,priority:2" json:"document_id"`
+	Version      *int      `json:"version"` // nil = file terbaru
+	FileName     string    `gorm:"type:varchar(500)" json:"file_name"`
+	UserID       *string   `gorm:"type:char(36);index" json:"user_id"`
+	User         *User     `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"user,omitempty"`
+	ShareLinkID  *string   `gorm:"type:char(36);index" json:"share_link_id"`
+	Via          string    `gorm:"type:varchar(20);index" json:"via"` // direct | signed | share_link | export
+	IPAddress    string    `gorm:"type:varchar(45)" json:"ip_address"`
+	UserAgent    string    `gorm:"type:varchar(255)" json:"user_agent"`
+	CreatedAt    time.Time `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DocumentShare — izin eksplisit atas satu surat / dokumen staf untuk user tertentu.
+// DocumentType memakai nilai yang sama dengan riwayat versi ("document" / "document_staff").
+type DocumentShare struct {
+	ID           string     `gorm:"type:char(36);primaryKey" json:"id"`
+	DocumentType string     `gorm:"type:varchar(20);not null;uniqueIndex:idx_document_share_user,priority:1" json:"document_type"`
+	DocumentID   string     `gorm:"type:char(36);not null;uniqueIndex:idx_document_share_user,priority:2" json:"document_id"`
+	UserID       string     `gorm:"type:char(36);not null;uniqueIndex:idx_document_share_user,priority:3;index" json:"user_id"`
+	User         User       `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user"`
+	Permission   string     `gorm:"type:enum('view','download');default:'view'" json:"permission"`
+	ExpiresAt    *time.Time `gorm:"index" json:"expires_at"`
+	SharedByID   *string    `gorm:"type:char(36)" json:"shared_by_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Generate UUID
+func (s *DocumentShare) BeforeCreate(tx *gorm.DB) (err error) {
+	s.ID = uuid.NewString()
+	return
+}
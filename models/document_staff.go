@@ -10,15 +10,43 @@ import (
 type DocumentStaff struct {
 	ID           string    `gorm:"type:char(36);primaryKey" json:"id"`
 	UserID       string    `gorm:"type:char(36);null;default:null" json:"user_id"`
-	EmployeeID   string    `gorm:"type:char(36);null;default:null" json:"employee_id"`
-	FileURL      string    `gorm:"type:text" json:"file_url"`
+	EmployeeID   string    `gorm:"type:char(36);null;default:null;index" json:"employee_id"`
+	FileURL      string    `gorm:"type:text" json:"-"` // URL storage tidak pernah dikirim ke klien
+	DownloadURL  string    `gorm:"-" json:"download_url"`
 	User         User      `gorm:"foreignKey:UserID;references:ID" json:"user"`
 	Subject      string    `gorm:"type:varchar(255)" json:"subject"`
 	FileName     string    `gorm:"type:varchar(500)" json:"file_name"`
-	PublicID     string    `gorm:"type:varchar(255)" json:"public_id"`
-	ResourceType string    `gorm:"type:varchar(20)" json:"resource_type"`
+	PublicID     string    `gorm:"type:varchar(255)" json:"-"`
+	ResourceType string    `gorm:"type:varchar(20)" json:"-"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+
+	// Pegawai pemilik dokumen kepegawaian; dokumen ini tidak memiliki UserID
+	Employee *Employee `gorm:"foreignKey:EmployeeID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;" json:"employee,omitempty"`
+
+	// Unit pemilik dokumen, diisi dari unit pengunggah
+	UnitID *string  `gorm:"type:char(36);index" json:"unit_id"`
+	Unit   *OrgUnit `gorm:"foreignKey:UnitID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"unit,omitempty"`
+
+	// Status review (lihat services.ReviewDocumentStaff), riwayatnya di DocumentStaffReview
+	ReviewStatus string     `gorm:"type:enum('submitted','under_review','revision_requested','approved','rejected');default:'submitted';index" json:"review_status"`
+	ReviewedByID *string    `gorm:"type:char(36)" json:"reviewed_by_id"`
+	ReviewedAt   *time.Time `json:"reviewed_at"`
+
+	// Permintaan pengumpulan yang dipenuhi dokumen ini (opsional)
+	SubmissionRequestID *string            `gorm:"type:char(36);index" json:"submission_request_id"`
+	SubmissionRequest   *SubmissionRequest `gorm:"foreignKey:SubmissionRequestID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"submission_request,omitempty"`
+
+	// Soft delete — file fisik baru dihapus saat trash di-purge
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	DeletedByID *string        `gorm:"type:char(36)" json:"deleted_by_id,omitempty"`
+
+	// Isi teks hasil ekstraksi/OCR di latar belakang (lihat services.QueueExtraction)
+	ExtractedText    string     `gorm:"type:longtext" json:"-"`
+	ExtractionStatus string     `gorm:"type:varchar(20);index" json:"extraction_status"`
+	ExtractionMethod string     `gorm:"type:varchar(20)" json:"extraction_method,omitempty"`
+	ExtractionError  string     `gorm:"type:varchar(500)" json:"extraction_error,omitempty"`
+	ExtractedAt      *time.Time `json:"extracted_at"`
 }
 
 func (d *DocumentStaff) BeforeCreate(tx *gorm.DB) (err error) {
@@ -26,6 +54,16 @@ func (d *DocumentStaff) BeforeCreate(tx *gorm.DB) (err error) {
 	return
 }
 
+// Endpoint unduhan terautentikasi, pengganti URL file publik
+func (d *DocumentStaff) AfterFind(tx *gorm.DB) (err error) {
+	d.DownloadURL = "/api/document_staff/" + d.ID + "/download"
+	return
+}
+
+func (d *DocumentStaff) AfterCreate(tx *gorm.DB) (err error) {
+	return d.AfterFind(tx)
+}
+
 func (d *DocumentStaff) BeforeSave(tx *gorm.DB) (err error) {
 	if d.UserID == "" && d.EmployeeID == "" {
 		return gorm.ErrInvalidData
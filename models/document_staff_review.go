@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// DocumentStaffReview — riwayat perpindahan status review dokumen staf beserta komentar peninjau.
+// Unggahan revisi juga tercatat di sini dengan ReviewerID = pengunggah.
+type DocumentStaffReview struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	DocumentStaffID string    `gorm:"type:char(36);not null;index" json:"document_staff_id"`
+	FromStatus      string    `gorm:"type:varchar(30)" json:"from_status"`
+	ToStatus        string    `gorm:"type:varchar(30);not null" json:"to_status"`
+	Comment         string    `gorm:"type:text" json:"comment"`
+	ReviewerID      *string   `gorm:"type:char(36);index" json:"reviewer_id"`
+	Reviewer        *User     `gorm:"foreignKey:ReviewerID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"reviewer,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+
+	DocumentStaff *DocumentStaff `gorm:"foreignKey:DocumentStaffID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DocumentVersion — riwayat file untuk Document ("document") maupun DocumentStaff ("document_staff")
+type DocumentVersion struct {
+	ID           string    `gorm:"type:char(36);primaryKey" json:"id"`
+	DocumentType string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_document_version" json:"document_type"`
+	DocumentID   string    `gorm:"type:char(36);not null;uniqueIndex:idx_document_version" json:"document_id"`
+	Version      int       `gorm:"not null;uniqueIndex:idx_document_version" json:"version"`
+	FileName     string    `gorm:"type:varchar(500)" json:"file_name"`
+	FileURL      string    `gorm:"type:text" json:"-"`
+	DownloadURL  string    `gorm:"-" json:"download_url"`
+	PublicID     string    `gorm:"type:varchar(255)" json:"-"`
+	ResourceType string    `gorm:"type:varchar(50)" json:"-"`
+	UploadedByID *string   `gorm:"type:char(36)" json:"uploaded_by_id"`
+	UploadedBy   *User     `gorm:"foreignKey:UploadedByID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"uploaded_by,omitempty"`
+	ChangeNote   string    `gorm:"type:text" json:"change_note"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Generate UUID
+func (v *DocumentVersion) BeforeCreate(tx *gorm.DB) (err error) {
+	v.ID = uuid.NewString()
+	return
+}
+
+func (v *DocumentVersion) AfterFind(tx *gorm.DB) (err error) {
+	prefix := "/api/documents/"
+	if v.DocumentType == "document_staff" {
+		prefix = "/api/document_staff/"
+	}
+	v.DownloadURL = prefix + v.DocumentID + "/versions/" + strconv.Itoa(v.Version) + "/download"
+	return
+}
+
+func (v *DocumentVersion) AfterCreate(tx *gorm.DB) (err error) {
+	return v.AfterFind(tx)
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Employee — pegawai yang tidak harus memiliki akun sistem. Dokumen kepegawaiannya
+// (SK, ijazah, dsb.) disimpan sebagai DocumentStaff dengan EmployeeID menunjuk ke sini.
+// Pegawai yang pensiun atau pindah ditandai inactive agar berkasnya tetap tersimpan.
+type Employee struct {
+	ID       string   `gorm:"type:char(36);primaryKey" json:"id"`
+	NIP      string   `gorm:"column:nip;type:varchar(18);not null;uniqueIndex" json:"nip"`
+	Name     string   `gorm:"type:varchar(255);not null;index" json:"name"`
+	Position string   `gorm:"type:varchar(255)" json:"position"`               // jabatan
+	Rank     string   `gorm:"column:rank_title;type:varchar(100)" json:"rank"` // pangkat, mis. "Penata Muda" (RANK kata kunci MySQL)
+	Grade    string   `gorm:"type:varchar(10)" json:"grade"`                   // golongan/ruang, mis. "III/a"
+	Status   string   `gorm:"type:enum('active','inactive');default:'active';index" json:"status"`
+	UnitID   *string  `gorm:"type:char(36);index" json:"unit_id"`
+	Unit     *OrgUnit `gorm:"foreignKey:UnitID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"unit,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Generate UUID
+func (e *Employee) BeforeCreate(tx *gorm.DB) (err error) {
+	e.ID = uuid.NewString()
+	return
+}
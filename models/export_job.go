@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExportJob — ekspor ZIP surat yang dibangun di background. File hasil disimpan
+// di server sampai ExpiresAt lalu dihapus oleh StartExportCleaner.
+type ExportJob struct {
+	ID          string     `gorm:"type:char(36);primaryKey" json:"id"`
+	UserID      string     `gorm:"type:char(36);not null;index" json:"user_id"`
+	User        User       `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Status      string     `gorm:"type:varchar(20);index" json:"status"` // pending | running | done | failed
+	Total       int        `json:"total"`
+	Processed   int        `json:"processed"`
+	Failed      int        `json:"failed"` // file yang tidak bisa diambil dari storage
+	FileName    string     `gorm:"type:varchar(255)" json:"file_name"`
+	FilePath    string     `gorm:"type:varchar(500)" json:"-"`
+	Size        int64      `json:"size"`
+	DocumentIDs string     `gorm:"type:longtext" json:"-"` // JSON array, urutan sesuai filter
+	Error       string     `gorm:"type:varchar(500)" json:"error,omitempty"`
+	DownloadURL string     `gorm:"-" json:"download_url,omitempty"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `gorm:"index" json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Generate UUID
+func (j *ExportJob) BeforeCreate(tx *gorm.DB) (err error) {
+	j.ID = uuid.NewString()
+	return
+}
+
+func (j *ExportJob) AfterFind(tx *gorm.DB) (err error) {
+	if j.Status == "done" {
+		j.DownloadURL = "/api/exports/" + j.ID + "/download"
+	}
+	return
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginThrottle — hitungan login gagal per username atau per IP, dipakai untuk
+// backoff eksponensial dan penguncian sementara
+type LoginThrottle struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Scope        string     `gorm:"type:enum('username','ip');not null;uniqueIndex:idx_login_throttle_key" json:"scope"`
+	Key          string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_login_throttle_key" json:"key"`
+	Failures     int        `gorm:"default:0" json:"failures"`
+	LastFailedAt time.Time  `json:"last_failed_at"`
+	LockedUntil  *time.Time `gorm:"index" json:"locked_until"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
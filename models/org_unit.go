@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrgUnit — unit organisasi (bidang/seksi) yang tersusun hierarkis.
+// Path berisi rantai ID dari akar, mis. "/<bidang>/<seksi>/", sehingga seluruh
+// turunan sebuah unit dapat diambil dengan satu kueri LIKE prefix.
+type OrgUnit struct {
+	ID         string    `gorm:"type:char(36);primaryKey" json:"id"`
+	Name       string    `gorm:"type:varchar(150);not null" json:"name"`
+	Code       *string   `gorm:"type:varchar(50);uniqueIndex" json:"code"`
+	ParentID   *string   `gorm:"type:char(36);index" json:"parent_id"`
+	Parent     *OrgUnit  `gorm:"foreignKey:ParentID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;" json:"parent,omitempty"`
+	Path       string    `gorm:"type:varchar(760);index" json:"path"`
+	HeadUserID *string   `gorm:"type:char(36);index" json:"head_user_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Generate UUID
+func (u *OrgUnit) BeforeCreate(tx *gorm.DB) (err error) {
+	u.ID = uuid.NewString()
+	return
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PasswordHistory — hash password lama untuk mencegah pemakaian ulang
+type PasswordHistory struct {
+	ID           string    `gorm:"type:char(36);primaryKey" json:"id"`
+	UserID       string    `gorm:"type:char(36);not null;index" json:"user_id"`
+	User         User      `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	PasswordHash string    `gorm:"type:varchar(255);not null" json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (p *PasswordHistory) BeforeCreate(tx *gorm.DB) (err error) {
+	p.ID = uuid.NewString()
+	return
+}
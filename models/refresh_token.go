@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefreshToken — refresh token sekali pakai milik satu sesi (SecretToken).
+// Token lama tetap disimpan dengan UsedAt terisi untuk mendeteksi pemakaian ulang.
+type RefreshToken struct {
+	ID        string      `gorm:"type:char(36);primaryKey" json:"id"`
+	SessionID string      `gorm:"type:char(36);not null;index" json:"session_id"`
+	Session   SecretToken `gorm:"foreignKey:SessionID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	TokenHash string      `gorm:"type:char(64);uniqueIndex" json:"-"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	UsedAt    *time.Time  `json:"used_at"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+func (r *RefreshToken) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.NewString()
+	return
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// Role — peran user beserta daftar izinnya. Role sistem (superadmin, admin, staff)
+// dibuat otomatis saat start dan tidak bisa dihapus.
+type Role struct {
+	Name        string           `gorm:"type:varchar(50);primaryKey" json:"name"`
+	Description string           `gorm:"type:varchar(255)" json:"description"`
+	IsSystem    bool             `gorm:"default:false" json:"is_system"`
+	Permissions []RolePermission `gorm:"foreignKey:RoleName;references:Name;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// RolePermission — izin yang dimiliki sebuah role
+type RolePermission struct {
+	RoleName   string    `gorm:"type:varchar(50);primaryKey" json:"role_name"`
+	Permission string    `gorm:"type:varchar(100);primaryKey" json:"permission"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Permission — katalog izin yang pernah dikenal aplikasi. Dipakai untuk mengetahui izin
+// baru yang perlu diberikan ke role default tanpa menimpa perubahan superadmin.
+type Permission struct {
+	Name        string    `gorm:"type:varchar(100);primaryKey" json:"name"`
+	Description string    `gorm:"type:varchar(255)" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
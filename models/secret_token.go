@@ -16,6 +16,11 @@ type SecretToken struct {
 	ExpiresAt time.Time `gorm:"null" json:"expires_at"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Info sesi per perangkat
+	IPAddress  string     `gorm:"type:varchar(45)" json:"ip_address"`
+	UserAgent  string     `gorm:"type:varchar(255)" json:"user_agent"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
 }
 
 // Generate UUID
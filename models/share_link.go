@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ShareLink — tautan publik ke satu surat / dokumen staf. Token hanya disimpan
+// sebagai hash SHA-256; token asli diberikan sekali saat tautan dibuat.
+type ShareLink struct {
+	ID             string     `gorm:"type:char(36);primaryKey" json:"id"`
+	DocumentType   string     `gorm:"type:varchar(20);not null;index:idx_share_link_document,priority:1" json:"document_type"`
+	DocumentID     string     `gorm:"type:char(36);not null;index:idx_share_link_document,priority:2" json:"document_id"`
+	TokenHash      string     `gorm:"type:char(64);not null;uniqueIndex" json:"-"`
+	PasswordHash   string     `gorm:"type:varchar(255)" json:"-"`
+	HasPassword    bool       `gorm:"-" json:"has_password"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	MaxDownloads   *int       `json:"max_downloads"`
+	DownloadCount  int        `gorm:"default:0" json:"download_count"`
+	LastAccessedAt *time.Time `json:"last_accessed_at"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+	CreatedByID    string     `gorm:"type:char(36)" json:"created_by_id"`
+	CreatedBy      User       `gorm:"foreignKey:CreatedByID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// ShareLinkAccess — jejak setiap akses ke tautan publik, termasuk yang ditolak
+type ShareLinkAccess struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ShareLinkID string    `gorm:"type:char(36);not null;index" json:"share_link_id"`
+	Outcome     string    `gorm:"type:varchar(30);index" json:"outcome"`
+	IPAddress   string    `gorm:"type:varchar(45)" json:"ip_address"`
+	UserAgent   string    `gorm:"type:varchar(255)" json:"user_agent"`
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`
+}
+
+// Generate UUID
+func (l *ShareLink) BeforeCreate(tx *gorm.DB) (err error) {
+	l.ID = uuid.NewString()
+	return
+}
+
+func (l *ShareLink) AfterFind(tx *gorm.DB) (err error) {
+	l.HasPassword = l.PasswordHash != ""
+	return
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SubmissionRequest — permintaan pengumpulan dokumen staf (mis. laporan bulanan)
+// kepada sejumlah user dengan batas waktu. Dokumen yang dikumpulkan adalah
+// DocumentStaff dengan SubmissionRequestID menunjuk ke permintaan ini.
+type SubmissionRequest struct {
+	ID          string     `gorm:"type:char(36);primaryKey" json:"id"`
+	Title       string     `gorm:"type:varchar(255);not null" json:"title"`
+	Description string     `gorm:"type:text" json:"description"`
+	Deadline    time.Time  `gorm:"index" json:"deadline"`
+	AllowLate   bool       `gorm:"default:true" json:"allow_late"` // boleh mengumpulkan setelah batas waktu
+	Status      string     `gorm:"type:enum('open','closed');default:'open';index" json:"status"`
+	CreatedByID *string    `gorm:"type:char(36);index" json:"created_by_id"`
+	CreatedBy   *User      `gorm:"foreignKey:CreatedByID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"created_by,omitempty"`
+	ClosedAt    *time.Time `json:"closed_at"`
+
+	// ringkasan ke pembuat saat batas waktu lewat (lihat services.SendSubmissionReminders)
+	DeadlineNotifiedAt *time.Time `json:"deadline_notified_at"`
+
+	Targets   []SubmissionTarget `gorm:"foreignKey:RequestID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"targets,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// SubmissionTarget — user yang wajib mengumpulkan. Target unit diuraikan menjadi
+// anggotanya saat permintaan dibuat; UnitID mencatat unit asalnya.
+type SubmissionTarget struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	RequestID string    `gorm:"type:char(36);not null;uniqueIndex:idx_submission_target" json:"request_id"`
+	UserID    string    `gorm:"type:char(36);not null;uniqueIndex:idx_submission_target;index" json:"user_id"`
+	User      User      `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"user"`
+	UnitID    *string   `gorm:"type:char(36)" json:"unit_id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// status pengingat otomatis
+	RemindedBeforeAt *time.Time `json:"reminded_before_at"`
+	OverdueReminders int        `gorm:"default:0" json:"overdue_reminders"`
+	LastRemindedAt   *time.Time `json:"last_reminded_at"`
+}
+
+// Generate UUID
+func (r *SubmissionRequest) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.NewString()
+	return
+}
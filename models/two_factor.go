@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RecoveryCode — kode cadangan 2FA sekali pakai (disimpan dalam bentuk hash)
+type RecoveryCode struct {
+	ID        string     `gorm:"type:char(36);primaryKey" json:"id"`
+	UserID    string     `gorm:"type:char(36);not null;index" json:"user_id"`
+	User      User       `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	CodeHash  string     `gorm:"type:char(64);uniqueIndex" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (r *RecoveryCode) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.NewString()
+	return
+}
+
+// TwoFactorChallenge — tantangan login tahap kedua setelah password benar.
+// Mode "verify" untuk user yang sudah aktif 2FA, "enroll" untuk user yang wajib mendaftar dulu.
+type TwoFactorChallenge struct {
+	ID            string    `gorm:"type:char(36);primaryKey" json:"id"`
+	TokenHash     string    `gorm:"type:char(64);uniqueIndex" json:"-"`
+	UserID        string    `gorm:"type:char(36);not null;index" json:"user_id"`
+	User          User      `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	Mode          string    `gorm:"type:enum('verify','enroll');default:'verify'" json:"mode"`
+	PendingSecret string    `gorm:"type:varchar(255)" json:"-"`
+	Attempts      int       `gorm:"default:0" json:"attempts"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (t *TwoFactorChallenge) BeforeCreate(tx *gorm.DB) (err error) {
+	t.ID = uuid.NewString()
+	return
+}
+
+// TwoFactorPolicy — kewajiban 2FA per role, diatur superadmin
+type TwoFactorPolicy struct {
+	Role        string    `gorm:"type:varchar(50);primaryKey" json:"role"`
+	Required    bool      `gorm:"default:false" json:"required"`
+	UpdatedByID *string   `gorm:"type:char(36)" json:"updated_by_id"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
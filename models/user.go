@@ -12,13 +12,28 @@ type User struct {
 	Name      string  `gorm:"type:varchar(100)" json:"name"`
 	Username  string  `gorm:"type:varchar(100);unique" json:"username"`
 	Password  string  `gorm:"type:varchar(255)" json:"password"`
-	Role      string  `gorm:"type:enum('admin','staff','superadmin')" json:"role"`
+	Role      string  `gorm:"type:varchar(50);index" json:"role"`
 	PushToken *string `gorm:"column:push_token;type:varchar(255);default:null" json:"push_token,omitempty"`
 	PhotoURL  *string `gorm:"type:text;default:null" json:"photo_url"`
 	PhotoID   *string `gorm:"type:varchar(255);default:null" json:"photo_id"`
 
+	// Unit organisasi tempat user bertugas
+	UnitID *string  `gorm:"type:char(36);index" json:"unit_id"`
+	Unit   *OrgUnit `gorm:"foreignKey:UnitID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"unit,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Two-factor authentication (TOTP). Secret disimpan terenkripsi.
+	TOTPSecret    string     `gorm:"column:totp_secret;type:varchar(255)" json:"-"`
+	TOTPEnabled   bool       `gorm:"column:totp_enabled;default:false" json:"totp_enabled"`
+	TOTPEnabledAt *time.Time `gorm:"column:totp_enabled_at" json:"totp_enabled_at,omitempty"`
+	TOTPLastStep  int64      `gorm:"column:totp_last_step" json:"-"`
+
+	// Password sementara hasil reset: wajib diganti saat login berikutnya
+	MustChangePassword bool       `gorm:"default:false" json:"must_change_password"`
+	PasswordExpiresAt  *time.Time `json:"password_expires_at,omitempty"`
+	PasswordChangedAt  *time.Time `json:"password_changed_at,omitempty"`
 }
 
 // Generate UUID
@@ -3,6 +3,7 @@ package routes
 import (
 	"dinsos_kuburaya/controllers"
 	"dinsos_kuburaya/middleware"
+	"dinsos_kuburaya/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,7 +12,7 @@ func ActivityLogRoutes(router *gin.RouterGroup) {
 	logs := router.Group("/activity-logs")
 	logs.Use(
 		middleware.AuthMiddleware(),
-		middleware.RoleMiddleware("admin", "superadmin"),
+		middleware.RequirePermission(services.PermActivityLogView),
 	)
 	{
 		logs.GET("", controllers.GetAllActivityLogs)
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"dinsos_kuburaya/controllers"
+	"dinsos_kuburaya/middleware"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func DispositionRoutes(r *gin.RouterGroup) {
+	r.GET("/documents/:id/dispositions", middleware.AuthMiddleware(), controllers.GetDocumentDispositions)
+
+	r.POST("/documents/:id/dispositions",
+		middleware.AuthMiddleware(),
+		middleware.RequirePermission(services.PermDispositionCreate),
+		controllers.CreateDisposition,
+	)
+
+	dispositions := r.Group("/dispositions")
+	dispositions.Use(middleware.AuthMiddleware())
+	{
+		dispositions.GET("/mine", controllers.GetMyDispositions)
+
+		dispositions.POST("/:id/acknowledge", controllers.AcknowledgeDisposition)
+
+		dispositions.POST("/:id/notes", controllers.AddDispositionNote)
+
+		dispositions.POST("/:id/forward", controllers.ForwardDisposition)
+
+		dispositions.POST("/:id/done", controllers.CompleteDisposition)
+	}
+}
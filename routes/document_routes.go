@@ -3,6 +3,7 @@ package routes
 import (
 	"dinsos_kuburaya/controllers"
 	"dinsos_kuburaya/middleware"
+	"dinsos_kuburaya/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,24 +12,72 @@ func DocumentRoutes(r *gin.RouterGroup) {
 	documents := r.Group("/documents")
 	documents.Use(middleware.AuthMiddleware())
 
-	documents.GET("", controllers.GetDocuments)
+	view := middleware.RequirePermission(services.PermDocumentView)
+	{
+		documents.GET("", view, controllers.GetDocuments)
 
-	documents.GET("/", controllers.GetDocuments)
+		documents.GET("/", view, controllers.GetDocuments)
 
-	documents.GET("/:id", controllers.GetDocumentByID)
+		documents.GET("/summary", view, controllers.GetDocumentSummary)
 
-	documents.GET("/summary", controllers.GetDocumentSummary)
+		documents.GET("/statistics", view, controllers.GetDocumentStatistics)
+	}
 
-	documents.Use(middleware.RoleMiddleware("admin", "superadmin"))
+	// akses per dokumen (izin role, cakupan unit, share) diperiksa di controller
 	{
+		documents.GET("/:id", controllers.GetDocumentByID)
+
+		documents.GET("/:id/versions", controllers.GetDocumentVersions)
+
+		documents.GET("/:id/content", controllers.GetDocumentContent)
+
 		documents.GET("/:id/download", controllers.DownloadDocument)
 
-		documents.POST("", controllers.CreateDocument)
+		documents.GET("/:id/download-url", controllers.GetDocumentDownloadURL)
+
+		documents.GET("/:id/downloads", controllers.GetDocumentDownloads)
+
+		documents.GET("/:id/versions/:version/download", controllers.DownloadDocumentVersion)
+
+		documents.GET("/:id/comments", controllers.GetDocumentComments)
+
+		documents.POST("/:id/comments", controllers.CreateDocumentComment)
+
+		documents.POST("/:id/comments/watch", controllers.WatchDocumentComments)
+
+		documents.DELETE("/:id/comments/watch", controllers.UnwatchDocumentComments)
+
+		documents.PUT("/:id/comments/:comment_id", controllers.UpdateDocumentComment)
+
+		documents.DELETE("/:id/comments/:comment_id", controllers.DeleteDocumentComment)
+	}
+
+	documents.POST("", middleware.RequirePermission(services.PermDocumentCreate), controllers.CreateDocument)
 
-		documents.POST("/", controllers.CreateDocument)
+	documents.POST("/", middleware.RequirePermission(services.PermDocumentCreate), controllers.CreateDocument)
+
+	export := middleware.RequirePermission(services.PermDocumentExport)
+	{
+		documents.POST("/export", export, controllers.ExportDocuments)
 
-		documents.PUT("/:id", controllers.UpdateDocument)
+		documents.GET("/agenda-book", export, controllers.ExportAgendaBook)
+	}
+
+	update := middleware.RequirePermission(services.PermDocumentUpdate)
+	{
+		documents.PUT("/:id", update, controllers.UpdateDocument)
+
+		documents.POST("/:id/versions/:version/restore", update, controllers.RestoreDocumentVersion)
+
+		documents.POST("/:id/extract", update, controllers.ReextractDocument)
+	}
+
+	documents.DELETE("/:id", middleware.RequirePermission(services.PermDocumentDelete), controllers.DeleteDocument)
+
+	restore := middleware.RequirePermission(services.PermDocumentRestore)
+	{
+		documents.GET("/trash", restore, controllers.GetDocumentTrash)
 
-		documents.DELETE("/:id", controllers.DeleteDocument)
+		documents.POST("/:id/restore", restore, controllers.RestoreDocument)
 	}
 }
@@ -21,6 +21,16 @@ func DocumentStaffRoutes(r *gin.RouterGroup) {
 
 		docStaff.GET("/:id/download", controllers.DownloadDocumentStaff)
 
+		docStaff.GET("/:id/download-url", controllers.GetDocumentStaffDownloadURL)
+
+		docStaff.GET("/:id/downloads", controllers.GetDocumentStaffDownloads)
+
+		docStaff.GET("/:id/versions", controllers.GetDocumentStaffVersions)
+
+		docStaff.GET("/:id/versions/:version/download", controllers.DownloadDocumentStaffVersion)
+
+		docStaff.POST("/:id/versions/:version/restore", controllers.RestoreDocumentStaffVersion)
+
 		docStaff.POST("", controllers.CreateDocumentStaff)
 
 		docStaff.POST("/", controllers.CreateDocumentStaff)
@@ -28,5 +38,29 @@ func DocumentStaffRoutes(r *gin.RouterGroup) {
 		docStaff.PUT("/:id", controllers.UpdateDocumentStaff)
 
 		docStaff.DELETE("/:id", controllers.DeleteDocumentStaff)
+
+		docStaff.GET("/trash", controllers.GetDocumentStaffTrash)
+
+		docStaff.POST("/:id/restore", controllers.RestoreDocumentStaff)
+
+		docStaff.GET("/:id/content", controllers.GetDocumentStaffContent)
+
+		docStaff.POST("/:id/extract", controllers.ReextractDocumentStaff)
+
+		docStaff.POST("/:id/review", controllers.ReviewDocumentStaff)
+
+		docStaff.GET("/:id/reviews", controllers.GetDocumentStaffReviews)
+
+		docStaff.GET("/:id/comments", controllers.GetDocumentStaffComments)
+
+		docStaff.POST("/:id/comments", controllers.CreateDocumentStaffComment)
+
+		docStaff.POST("/:id/comments/watch", controllers.WatchDocumentStaffComments)
+
+		docStaff.DELETE("/:id/comments/watch", controllers.UnwatchDocumentStaffComments)
+
+		docStaff.PUT("/:id/comments/:comment_id", controllers.UpdateDocumentStaffComment)
+
+		docStaff.DELETE("/:id/comments/:comment_id", controllers.DeleteDocumentStaffComment)
 	}
 }
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"dinsos_kuburaya/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DownloadRoutes — URL unduhan bertanda tangan; token menggantikan header Authorization
+func DownloadRoutes(r *gin.RouterGroup) {
+	downloads := r.Group("/downloads")
+	{
+		downloads.GET("/:token", controllers.DownloadSignedFile)
+	}
+}
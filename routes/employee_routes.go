@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"dinsos_kuburaya/controllers"
+	"dinsos_kuburaya/middleware"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func EmployeeRoutes(r *gin.RouterGroup) {
+	employees := r.Group("/employees")
+	employees.Use(middleware.AuthMiddleware(), middleware.RequirePermission(services.PermEmployeeManage))
+	{
+		employees.GET("", controllers.GetEmployees)
+
+		employees.POST("", controllers.CreateEmployee)
+
+		employees.GET("/:id", controllers.GetEmployee)
+
+		employees.PUT("/:id", controllers.UpdateEmployee)
+
+		employees.DELETE("/:id", controllers.DeleteEmployee)
+
+		employees.GET("/:id/documents", controllers.GetEmployeeDocuments)
+
+		employees.POST("/:id/documents", controllers.CreateEmployeeDocument)
+	}
+}
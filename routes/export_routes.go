@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"dinsos_kuburaya/controllers"
+	"dinsos_kuburaya/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportRoutes — hasil ekspor ZIP hanya dapat diakses oleh pembuatnya
+func ExportRoutes(r *gin.RouterGroup) {
+	exports := r.Group("/exports")
+	exports.Use(middleware.AuthMiddleware())
+	{
+		exports.GET("", controllers.GetExportJobs)
+
+		exports.GET("/:id", controllers.GetExportJob)
+
+		exports.GET("/:id/download", controllers.DownloadExport)
+
+		exports.DELETE("/:id", controllers.DeleteExport)
+	}
+}
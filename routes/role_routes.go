@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"dinsos_kuburaya/controllers"
+	"dinsos_kuburaya/middleware"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RoleRoutes(r *gin.RouterGroup) {
+	r.GET("/permissions",
+		middleware.AuthMiddleware(),
+		middleware.RequirePermission(services.PermRoleManage),
+		controllers.GetPermissions,
+	)
+
+	roles := r.Group("/roles")
+	roles.Use(
+		middleware.AuthMiddleware(),
+		middleware.RequirePermission(services.PermRoleManage),
+	)
+	{
+		roles.GET("", controllers.GetRoles)
+
+		roles.GET("/:name", controllers.GetRole)
+
+		roles.POST("", controllers.CreateRole)
+
+		roles.PUT("/:name", controllers.UpdateRole)
+
+		roles.DELETE("/:name", controllers.DeleteRole)
+	}
+}
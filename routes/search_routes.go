@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"dinsos_kuburaya/controllers"
+	"dinsos_kuburaya/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SearchRoutes(r *gin.RouterGroup) {
+	r.GET("/search", middleware.AuthMiddleware(), controllers.Search)
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"dinsos_kuburaya/controllers"
+	"dinsos_kuburaya/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SessionRoutes(r *gin.RouterGroup) {
+	r.POST("/token/refresh", controllers.RefreshToken)
+
+	sessions := r.Group("/sessions")
+	sessions.Use(middleware.AuthMiddleware())
+	{
+		sessions.GET("", controllers.GetMySessions)
+
+		sessions.DELETE("/:id", controllers.RevokeMySession)
+	}
+}
@@ -0,0 +1,51 @@
+package routes
+
+import (
+	"dinsos_kuburaya/controllers"
+	"dinsos_kuburaya/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ShareRoutes(r *gin.RouterGroup) {
+	// hak membagikan diperiksa per dokumen di controller
+	auth := r.Group("")
+	auth.Use(middleware.AuthMiddleware())
+	{
+		auth.GET("/documents/:id/shares", controllers.GetDocumentShares)
+
+		auth.POST("/documents/:id/shares", controllers.ShareDocument)
+
+		auth.DELETE("/documents/:id/shares/:share_id", controllers.RevokeDocumentShare)
+
+		auth.GET("/documents/:id/links", controllers.GetDocumentShareLinks)
+
+		auth.POST("/documents/:id/links", controllers.CreateDocumentShareLink)
+
+		auth.DELETE("/documents/:id/links/:link_id", controllers.RevokeDocumentShareLink)
+
+		auth.GET("/document_staff/:id/shares", controllers.GetDocumentStaffShares)
+
+		auth.POST("/document_staff/:id/shares", controllers.ShareDocumentStaff)
+
+		auth.DELETE("/document_staff/:id/shares/:share_id", controllers.RevokeDocumentStaffShare)
+
+		auth.GET("/document_staff/:id/links", controllers.GetDocumentStaffShareLinks)
+
+		auth.POST("/document_staff/:id/links", controllers.CreateDocumentStaffShareLink)
+
+		auth.DELETE("/document_staff/:id/links/:link_id", controllers.RevokeDocumentStaffShareLink)
+
+		auth.GET("/shares/received", controllers.GetReceivedShares)
+	}
+
+	// tautan publik — tanpa login
+	public := r.Group("/public/share")
+	{
+		public.GET("/:token", controllers.GetPublicShare)
+
+		public.GET("/:token/download", controllers.DownloadPublicShare)
+
+		public.POST("/:token/download", controllers.DownloadPublicShare)
+	}
+}
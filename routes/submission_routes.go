@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"dinsos_kuburaya/controllers"
+	"dinsos_kuburaya/middleware"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SubmissionRoutes(r *gin.RouterGroup) {
+	submissions := r.Group("/submissions")
+	submissions.Use(middleware.AuthMiddleware())
+
+	// target hanya melihat statusnya sendiri (diperiksa di controller)
+	{
+		submissions.GET("/mine", controllers.GetMySubmissionRequests)
+
+		submissions.GET("/:id", controllers.GetSubmissionRequest)
+	}
+
+	manage := middleware.RequirePermission(services.PermSubmissionManage)
+	{
+		submissions.GET("", manage, controllers.GetSubmissionRequests)
+
+		submissions.POST("", manage, controllers.CreateSubmissionRequest)
+
+		submissions.PUT("/:id", manage, controllers.UpdateSubmissionRequest)
+
+		submissions.DELETE("/:id", manage, controllers.DeleteSubmissionRequest)
+
+		submissions.POST("/:id/remind", manage, controllers.RemindSubmissionRequest)
+	}
+}
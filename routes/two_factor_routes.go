@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"dinsos_kuburaya/controllers"
+	"dinsos_kuburaya/middleware"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TwoFactorRoutes(r *gin.RouterGroup) {
+	// login tahap kedua (belum punya token, memakai challenge_id)
+	r.POST("/login/2fa", controllers.LoginTwoFactor)
+	r.POST("/login/2fa/setup", controllers.LoginTwoFactorSetup)
+
+	twoFactor := r.Group("/2fa")
+	twoFactor.Use(middleware.AuthMiddleware())
+	{
+		twoFactor.GET("/status", controllers.GetTwoFactorStatus)
+
+		twoFactor.POST("/setup", controllers.SetupTwoFactor)
+
+		twoFactor.POST("/enable", controllers.EnableTwoFactor)
+
+		twoFactor.POST("/recovery-codes", controllers.RegenerateRecoveryCodes)
+
+		twoFactor.GET("/policy", middleware.RequirePermission(services.PermRoleManage), controllers.GetTwoFactorPolicies)
+
+		twoFactor.PUT("/policy", middleware.RequirePermission(services.PermRoleManage), controllers.UpdateTwoFactorPolicy)
+	}
+}
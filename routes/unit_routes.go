@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"dinsos_kuburaya/controllers"
+	"dinsos_kuburaya/middleware"
+	"dinsos_kuburaya/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func UnitRoutes(r *gin.RouterGroup) {
+	units := r.Group("/units")
+	units.Use(middleware.AuthMiddleware())
+	{
+		units.GET("", controllers.GetUnits)
+
+		units.GET("/:id", controllers.GetUnit)
+
+		units.GET("/:id/members", controllers.GetUnitMembers)
+	}
+
+	manage := middleware.RequirePermission(services.PermUnitManage)
+	{
+		units.POST("", manage, controllers.CreateUnit)
+
+		units.PUT("/:id", manage, controllers.UpdateUnit)
+
+		units.DELETE("/:id", manage, controllers.DeleteUnit)
+
+		units.PUT("/:id/members", manage, controllers.AddUnitMembers)
+
+		units.DELETE("/:id/members/:user_id", manage, controllers.RemoveUnitMember)
+	}
+}
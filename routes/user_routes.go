@@ -3,6 +3,7 @@ package routes
 import (
 	"dinsos_kuburaya/controllers"
 	"dinsos_kuburaya/middleware"
+	"dinsos_kuburaya/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,25 +15,41 @@ func UserRoutes(router *gin.RouterGroup) {
 
 	// users.POST("/superadmin", controllers.CreateSuperAdmin)
 
-	users.POST("/admin", middleware.AuthMiddleware(), middleware.RoleMiddleware("superadmin"), controllers.CreateAdmin)
+	users.POST("/admin", middleware.AuthMiddleware(), middleware.RequirePermission(services.PermUserManage), controllers.CreateAdmin)
 
-	users.POST("/staff", middleware.AuthMiddleware(), middleware.RoleMiddleware("superadmin"), controllers.CreateStaff)
+	users.POST("/staff", middleware.AuthMiddleware(), middleware.RequirePermission(services.PermUserManage), controllers.CreateStaff)
 
 	usersAuth := users.Group("")
 	usersAuth.Use(middleware.AuthMiddleware())
 	{
 		usersAuth.GET("/me", controllers.GetMe)
 
-		usersAuth.GET("", middleware.RoleMiddleware("admin", "superadmin"), controllers.GetUsers)
+		usersAuth.POST("/me/password", controllers.ChangeMyPassword)
 
-		usersAuth.GET("/:id", middleware.RoleMiddleware("admin", "superadmin"), controllers.GetUserByID)
+		usersAuth.GET("/password-policy", controllers.GetPasswordPolicy)
+
+		usersAuth.GET("", middleware.RequirePermission(services.PermUserView), controllers.GetUsers)
+
+		usersAuth.GET("/:id", middleware.RequirePermission(services.PermUserView), controllers.GetUserByID)
 
 		usersAuth.PUT("/:id", middleware.UserSelfOrSuperAdmin(), controllers.UpdateUser)
 
-		usersAuth.PUT("/:id/reset-password", middleware.RoleMiddleware("superadmin"), controllers.ResetPassword)
+		usersAuth.PUT("/:id/reset-password", middleware.RequirePermission(services.PermUserManage), controllers.ResetPassword)
+
+		usersAuth.DELETE("/:id", middleware.RequirePermission(services.PermUserManage), controllers.DeleteUser)
+
+		usersAuth.GET("/:id/sessions", middleware.RequirePermission(services.PermUserManage), controllers.GetUserSessions)
+
+		usersAuth.DELETE("/:id/sessions", middleware.RequirePermission(services.PermUserManage), controllers.RevokeUserSessions)
+
+		usersAuth.DELETE("/:id/2fa", middleware.RequirePermission(services.PermUserManage), controllers.ResetUserTwoFactor)
+
+		usersAuth.POST("/:id/unlock", middleware.RequirePermission(services.PermUserManage), controllers.UnlockUser)
+
+		usersAuth.GET("/lockouts", middleware.RequirePermission(services.PermUserManage), controllers.GetLoginLockouts)
 
-		usersAuth.DELETE("/:id", middleware.RoleMiddleware("superadmin"), controllers.DeleteUser)
+		usersAuth.DELETE("/lockouts/:id", middleware.RequirePermission(services.PermUserManage), controllers.DeleteLoginLockout)
 
-		usersAuth.GET("/for-filter", middleware.RoleMiddleware("admin", "superadmin"), controllers.GetUsersForFilter)
+		usersAuth.GET("/for-filter", middleware.RequirePermission(services.PermUserView), controllers.GetUsersForFilter)
 	}
 }
@@ -0,0 +1,451 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+
+	"gorm.io/gorm"
+)
+
+// Format keluaran buku agenda
+const (
+	AgendaBookXLSX = "xlsx"
+	AgendaBookPDF  = "pdf"
+)
+
+var (
+	ErrAgendaBookLetterType = errors.New("letter_type harus masuk atau keluar")
+	ErrAgendaBookFormat     = errors.New("format harus xlsx atau pdf")
+	ErrAgendaBookPeriod     = errors.New("periode tidak valid")
+	ErrAgendaBookTooLarge   = errors.New("jumlah surat pada periode melebihi batas buku agenda, persempit periode")
+)
+
+// agendaBookMaxRows — batas baris satu buku agenda (env AGENDA_BOOK_MAX_ROWS, default 5000)
+func agendaBookMaxRows() int {
+	return envInt("AGENDA_BOOK_MAX_ROWS", 5000)
+}
+
+var indonesianMonths = []string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+// FormatIndonesianDate — mis. 2 Januari 2026
+func FormatIndonesianDate(t time.Time) string {
+	return strconv.Itoa(t.Day()) + " " + indonesianMonths[t.Month()-1] + " " + strconv.Itoa(t.Year())
+}
+
+func formatShortDate(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format("02-01-2006")
+}
+
+// Letterhead — baris kop surat (env LETTERHEAD, dipisah "|") dan alamat (env LETTERHEAD_ADDRESS)
+func Letterhead() ([]string, string) {
+	lines := []string{"PEMERINTAH KABUPATEN KUBU RAYA", "DINAS SOSIAL"}
+	if v := os.Getenv("LETTERHEAD"); v != "" {
+		lines = lines[:0]
+		for _, line := range strings.Split(v, "|") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines, os.Getenv("LETTERHEAD_ADDRESS")
+}
+
+// AgendaBookRow — satu baris register agenda
+type AgendaBookRow struct {
+	AgendaNumber   string
+	Date           *time.Time // tanggal diterima (masuk) / dikirim (keluar), fallback tanggal registrasi
+	LetterNumber   string
+	LetterDate     *time.Time
+	Party          string // pengirim (masuk) / tujuan (keluar)
+	Subject        string
+	Classification string
+	Disposition    string
+}
+
+// AgendaBook — register agenda satu jenis surat untuk satu periode
+type AgendaBook struct {
+	LetterType  string
+	From, To    time.Time // To inklusif
+	Rows        []AgendaBookRow
+	GeneratedAt time.Time
+	GeneratedBy string
+}
+
+func (b AgendaBook) Title() string {
+	return "BUKU AGENDA SURAT " + strings.ToUpper(b.LetterType)
+}
+
+func (b AgendaBook) PeriodLabel() string {
+	return "Periode " + FormatIndonesianDate(b.From) + " s.d. " + FormatIndonesianDate(b.To)
+}
+
+// FileName — nama file unduhan, mis. buku-agenda-masuk-20260101-20260131.pdf
+func (b AgendaBook) FileName(format string) string {
+	return "buku-agenda-" + b.LetterType + "-" + b.From.Format("20060102") + "-" + b.To.Format("20060102") + "." + format
+}
+
+var dispositionStatusLabels = map[string]string{
+	"pending":      "belum dibaca",
+	"acknowledged": "diterima",
+	"in_progress":  "diproses",
+	"forwarded":    "diteruskan",
+	"done":         "selesai",
+}
+
+// dispositionSummaries — ringkasan disposisi per surat: "Nama (status); ..."
+func dispositionSummaries(documentIDs []string) map[string]string {
+	summaries := map[string]string{}
+	if len(documentIDs) == 0 {
+		return summaries
+	}
+
+	var dispositions []models.Disposition
+	config.DB.Preload("ToUser", selectSearchUser).
+		Where("document_id IN ?", documentIDs).
+		Order("created_at ASC").
+		Find(&dispositions)
+
+	for _, d := range dispositions {
+		status := dispositionStatusLabels[d.Status]
+		if status == "" {
+			status = d.Status
+		}
+		entry := d.ToUser.Name + " (" + status + ")"
+		if summaries[d.DocumentID] != "" {
+			entry = summaries[d.DocumentID] + "; " + entry
+		}
+		summaries[d.DocumentID] = entry
+	}
+	return summaries
+}
+
+// BuildAgendaBook — susun register agenda dari query surat yang sudah dibatasi cakupan unit.
+// Periode mengikuti tanggal registrasi (nomor agenda diberikan saat surat dicatat).
+func BuildAgendaBook(query *gorm.DB, letterType string, from, to time.Time, generatedBy string) (AgendaBook, error) {
+	book := AgendaBook{LetterType: letterType, From: from, To: to, GeneratedAt: time.Now(), GeneratedBy: generatedBy}
+
+	if letterType != "masuk" && letterType != "keluar" {
+		return book, ErrAgendaBookLetterType
+	}
+	if to.Before(from) {
+		return book, ErrAgendaBookPeriod
+	}
+
+	var documents []models.Document
+	if err := query.
+		Where("documents.letter_type = ?", letterType).
+		Where("documents.created_at >= ? AND documents.created_at < ?", from, to.AddDate(0, 0, 1)).
+		Order("documents.agenda_year ASC").
+		Order("documents.agenda_sequence ASC").
+		Order("documents.created_at ASC").
+		Limit(agendaBookMaxRows() + 1).
+		Find(&documents).Error; err != nil {
+		return book, err
+	}
+	if len(documents) > agendaBookMaxRows() {
+		return book, ErrAgendaBookTooLarge
+	}
+
+	var dispositions map[string]string
+	if letterType == "masuk" {
+		ids := make([]string, len(documents))
+		for i, d := range documents {
+			ids[i] = d.ID
+		}
+		dispositions = dispositionSummaries(ids)
+	}
+
+	book.Rows = make([]AgendaBookRow, 0, len(documents))
+	for _, d := range documents {
+		row := AgendaBookRow{
+			AgendaNumber:   d.AgendaNumber,
+			Date:           d.ReceivedDate,
+			LetterNumber:   d.LetterNumber,
+			LetterDate:     d.LetterDate,
+			Party:          d.Sender,
+			Subject:        d.Subject,
+			Classification: d.ClassificationCode,
+			Disposition:    "-",
+		}
+		if row.Date == nil {
+			created := d.CreatedAt
+			row.Date = &created
+		}
+		if letterType == "keluar" {
+			row.Party = d.Recipient
+		} else if summary := dispositions[d.ID]; summary != "" {
+			row.Disposition = summary
+		} else {
+			row.Disposition = "Belum didisposisi"
+		}
+		book.Rows = append(book.Rows, row)
+	}
+
+	return book, nil
+}
+
+// columns — judul kolom dan bobot lebar relatif
+func (b AgendaBook) columns() ([]string, []float64) {
+	dateLabel, partyLabel := "Tanggal Diterima", "Pengirim"
+	if b.LetterType == "keluar" {
+		dateLabel, partyLabel = "Tanggal Dikirim", "Tujuan"
+	}
+	return []string{"No", "Nomor Agenda", dateLabel, "Nomor Surat", "Tanggal Surat", partyLabel, "Perihal", "Klasifikasi", "Status Disposisi"},
+		[]float64{4, 11, 8, 13, 8, 15, 22, 7, 16}
+}
+
+func (b AgendaBook) cells(index int, row AgendaBookRow) []string {
+	return []string{
+		strconv.Itoa(index + 1), row.AgendaNumber, formatShortDate(row.Date), row.LetterNumber,
+		formatShortDate(row.LetterDate), row.Party, row.Subject, row.Classification, row.Disposition,
+	}
+}
+
+func (b AgendaBook) printedLabel() string {
+	label := "Dicetak " + FormatIndonesianDate(b.GeneratedAt) + " " + b.GeneratedAt.Format("15:04")
+	if b.GeneratedBy != "" {
+		label += " oleh " + b.GeneratedBy
+	}
+	return label
+}
+
+// =========================
+// XLSX
+// =========================
+
+func (b AgendaBook) WriteXLSX(w io.Writer) error {
+	headers, weights := b.columns()
+	lastColumn := xlsxColumn(len(headers) - 1)
+
+	sheet := xlsxSheet{
+		Name:       "Agenda " + b.LetterType,
+		RowHeights: map[int]float64{},
+		Landscape:  true,
+		Footer:     "&L" + strings.ReplaceAll(b.printedLabel(), "&", "&&") + "&RHalaman &P dari &N",
+	}
+	for _, weight := range weights {
+		sheet.ColumnWidth = append(sheet.ColumnWidth, weight*1.6)
+	}
+
+	merged := func(value string, style int) {
+		row := make([]xlsxCell, len(headers))
+		row[0] = xlsxCell{Value: value, Style: style}
+		sheet.Rows = append(sheet.Rows, row)
+		n := strconv.Itoa(len(sheet.Rows))
+		sheet.Merges = append(sheet.Merges, "A"+n+":"+lastColumn+n)
+	}
+
+	lines, address := Letterhead()
+	for _, line := range lines {
+		merged(line, xlsxStyleTitle)
+	}
+	if address != "" {
+		merged(address, xlsxStyleSubtitle)
+	}
+	sheet.Rows = append(sheet.Rows, nil)
+	merged(b.Title(), xlsxStyleTitle)
+	merged(b.PeriodLabel(), xlsxStyleSubtitle)
+	sheet.Rows = append(sheet.Rows, nil)
+
+	header := make([]xlsxCell, len(headers))
+	for i, h := range headers {
+		header[i] = xlsxCell{Value: h, Style: xlsxStyleHeader}
+	}
+	sheet.RowHeights[len(sheet.Rows)] = 28
+	sheet.Rows = append(sheet.Rows, header)
+	sheet.PrintTitle = len(sheet.Rows)
+
+	for i, row := range b.Rows {
+		values := b.cells(i, row)
+		cells := make([]xlsxCell, len(values))
+		for c, value := range values {
+			style := xlsxStyleBody
+			if c == 0 || c == 2 || c == 4 {
+				style = xlsxStyleBodyCenter
+			}
+			cells[c] = xlsxCell{Value: value, Style: style}
+		}
+		sheet.Rows = append(sheet.Rows, cells)
+	}
+	if len(b.Rows) == 0 {
+		merged("Tidak ada surat pada periode ini", xlsxStyleBodyCenter)
+	}
+
+	return writeXLSX(w, sheet)
+}
+
+// =========================
+// PDF (A4 landscape)
+// =========================
+
+const (
+	agendaPDFMargin   = 36.0
+	agendaPDFFontSize = 8.0
+	agendaPDFLeading  = 10.0
+	agendaPDFPadding  = 3.0
+	agendaPDFFooter   = 24.0
+)
+
+func (b AgendaBook) WritePDF(w io.Writer) error {
+	doc := newPDFDocument(pdfA4Height, pdfA4Width)
+	doc.Title = b.Title()
+
+	logoRatio := 0.0
+	if path := os.Getenv("LETTERHEAD_LOGO"); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			logoRatio, _ = doc.SetImage(data)
+		}
+	}
+
+	headers, weights := b.columns()
+	left := agendaPDFMargin
+	right := doc.Width - agendaPDFMargin
+	tableWidth := right - left
+
+	totalWeight := 0.0
+	for _, weight := range weights {
+		totalWeight += weight
+	}
+	widths := make([]float64, len(weights))
+	for i, weight := range weights {
+		widths[i] = tableWidth * weight / totalWeight
+	}
+
+	bottom := agendaPDFMargin + agendaPDFFooter
+	var page *pdfPage
+	var y float64
+
+	drawRow := func(cells []string, bold bool, fill float64) {
+		lines := make([][]string, len(cells))
+		maxLines := 1
+		for i, cell := range cells {
+			lines[i] = pdfWrap(cell, widths[i]-2*agendaPDFPadding, agendaPDFFontSize, bold)
+			if len(lines[i]) > maxLines {
+				maxLines = len(lines[i])
+			}
+		}
+
+		// baris yang lebih tinggi dari satu halaman dipotong
+		limit := int((doc.Height - 2*agendaPDFMargin - agendaPDFFooter - 40) / agendaPDFLeading)
+		if maxLines > limit {
+			maxLines = limit
+		}
+		height := float64(maxLines)*agendaPDFLeading + 2*agendaPDFPadding
+
+		x := left
+		for i := range cells {
+			page.Rect(x, y-height, widths[i], height, fill)
+			for l, line := range lines[i] {
+				if l >= maxLines {
+					break
+				}
+				baseline := y - agendaPDFPadding - float64(l+1)*agendaPDFLeading + 2.5
+				if bold || i == 0 || i == 2 || i == 4 {
+					page.TextCenter(x+widths[i]/2, baseline, agendaPDFFontSize, bold, line)
+				} else {
+					page.Text(x+agendaPDFPadding, baseline, agendaPDFFontSize, bold, line)
+				}
+			}
+			x += widths[i]
+		}
+		y -= height
+	}
+
+	rowHeight := func(cells []string) float64 {
+		maxLines := 1
+		for i, cell := range cells {
+			if n := len(pdfWrap(cell, widths[i]-2*agendaPDFPadding, agendaPDFFontSize, false)); n > maxLines {
+				maxLines = n
+			}
+		}
+		return float64(maxLines)*agendaPDFLeading + 2*agendaPDFPadding
+	}
+
+	newPage := func(first bool) {
+		page = doc.AddPage()
+		y = doc.Height - agendaPDFMargin
+
+		if first {
+			y = b.drawLetterhead(page, doc, y, left, right, logoRatio)
+		}
+		drawRow(headers, true, 0.85)
+	}
+
+	newPage(true)
+	for i, row := range b.Rows {
+		cells := b.cells(i, row)
+		if y-rowHeight(cells) < bottom {
+			newPage(false)
+		}
+		drawRow(cells, false, -1)
+	}
+	if len(b.Rows) == 0 {
+		y -= 20
+		page.TextCenter(doc.Width/2, y, 10, false, "Tidak ada surat pada periode ini")
+	}
+
+	// nomor halaman baru diketahui setelah seluruh tabel tersusun
+	pages := doc.Pages()
+	for i, p := range pages {
+		footerY := agendaPDFMargin
+		p.Line(left, footerY+12, right, footerY+12, 0.5)
+		p.Text(left, footerY, 8, false, b.Title()+" — "+b.printedLabel())
+		p.TextRight(right, footerY, 8, false, "Halaman "+strconv.Itoa(i+1)+" dari "+strconv.Itoa(len(pages)))
+	}
+
+	return doc.Write(w)
+}
+
+// drawLetterhead — kop surat, garis ganda, judul dan periode; mengembalikan posisi y berikutnya
+func (b AgendaBook) drawLetterhead(page *pdfPage, doc *pdfDocument, y, left, right, logoRatio float64) float64 {
+	lines, address := Letterhead()
+	center := doc.Width / 2
+	top := y
+
+	for i, line := range lines {
+		size := 13.0
+		if i == len(lines)-1 {
+			size = 16
+		}
+		y -= size + 3
+		page.TextCenter(center, y, size, true, line)
+	}
+	if address != "" {
+		y -= 12
+		page.TextCenter(center, y, 9, false, address)
+	}
+
+	if logoRatio > 0 {
+		height := top - y
+		if height < 40 {
+			height = 40
+		}
+		page.Image(left, top-height, height*logoRatio, height)
+		if top-height < y {
+			y = top - height
+		}
+	}
+
+	y -= 8
+	page.Line(left, y, right, y, 2)
+	page.Line(left, y-3, right, y-3, 0.5)
+
+	y -= 24
+	page.TextCenter(center, y, 12, true, b.Title())
+	y -= 14
+	page.TextCenter(center, y, 9, false, b.PeriodLabel())
+	return y - 12
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"dinsos_kuburaya/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Format default nomor agenda. Dapat diubah lewat env
+// AGENDA_FORMAT_MASUK dan AGENDA_FORMAT_KELUAR.
+const (
+	defaultAgendaFormatMasuk  = "{seq}"
+	defaultAgendaFormatKeluar = "{seq}/{classification}/DINSOS/{roman_month}/{year}"
+)
+
+var romanMonths = []string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X", "XI", "XII"}
+
+func agendaFormat(letterType string) string {
+	if letterType == "keluar" {
+		if f := os.Getenv("AGENDA_FORMAT_KELUAR"); f != "" {
+			return f
+		}
+		return defaultAgendaFormatKeluar
+	}
+
+	if f := os.Getenv("AGENDA_FORMAT_MASUK"); f != "" {
+		return f
+	}
+	return defaultAgendaFormatMasuk
+}
+
+// CheckAgendaRequirements — pastikan dokumen punya data yang dibutuhkan template
+// sebelum file diunggah
+func CheckAgendaRequirements(doc models.Document) error {
+	if strings.Contains(agendaFormat(doc.LetterType), "{classification}") && doc.ClassificationCode == "" {
+		return errors.New("Kode klasifikasi wajib diisi untuk penomoran surat " + doc.LetterType)
+	}
+	return nil
+}
+
+// FormatAgendaNumber — isi placeholder {seq}, {classification}, {month},
+// {roman_month}, {year} dan {type} pada template
+func FormatAgendaNumber(template string, seq int, doc models.Document, at time.Time) string {
+	replacer := strings.NewReplacer(
+		"{seq}", strconv.Itoa(seq),
+		"{classification}", doc.ClassificationCode,
+		"{month}", strconv.Itoa(int(at.Month())),
+		"{roman_month}", romanMonths[at.Month()-1],
+		"{year}", strconv.Itoa(at.Year()),
+		"{type}", strings.ToUpper(doc.LetterType),
+	)
+	return replacer.Replace(template)
+}
+
+// AssignAgendaNumber — ambil nomor urut berikutnya secara atomik dan cap ke dokumen.
+// Harus dipanggil di dalam transaksi yang sama dengan penyimpanan dokumen agar
+// counter terkunci (SELECT ... FOR UPDATE) sampai dokumen tersimpan.
+func AssignAgendaNumber(tx *gorm.DB, doc *models.Document) error {
+	now := time.Now()
+	year := now.Year()
+
+	counter := models.AgendaCounter{Year: year, LetterType: doc.LetterType}
+
+	// baris counter dibuat sekali per tahun & jenis surat; abaikan jika sudah ada
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&counter).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("year = ? AND letter_type = ?", year, doc.LetterType).
+		First(&counter).Error; err != nil {
+		return err
+	}
+
+	seq := counter.LastNumber + 1
+	if err := tx.Model(&models.AgendaCounter{}).
+		Where("year = ? AND letter_type = ?", year, doc.LetterType).
+		Update("last_number", seq).Error; err != nil {
+		return err
+	}
+
+	doc.AgendaYear = &year
+	doc.AgendaSequence = &seq
+	doc.AgendaNumber = FormatAgendaNumber(agendaFormat(doc.LetterType), seq, *doc, now)
+
+	// nomor surat keluar diterbitkan dari nomor agenda bila belum diisi manual
+	if doc.LetterType == "keluar" && doc.LetterNumber == "" {
+		doc.LetterNumber = doc.AgendaNumber
+	}
+
+	return nil
+}
@@ -0,0 +1,383 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+	ws "dinsos_kuburaya/websocket"
+
+	"gorm.io/gorm"
+)
+
+// Event WebSocket komentar, dikirim ke user yang sedang membuka dokumen
+const (
+	CommentEventAdded   = "comment_added"
+	CommentEventUpdated = "comment_updated"
+	CommentEventDeleted = "comment_deleted"
+)
+
+var (
+	ErrCommentEmpty           = errors.New("komentar tidak boleh kosong")
+	ErrCommentTooLong         = errors.New("komentar maksimal 5000 karakter")
+	ErrCommentNotFound        = errors.New("komentar tidak ditemukan")
+	ErrCommentParent          = errors.New("komentar yang dibalas tidak ditemukan")
+	ErrCommentNotAuthor       = errors.New("hanya penulis yang dapat mengubah komentar")
+	ErrCommentEditWindow      = errors.New("batas waktu mengubah komentar sudah lewat")
+	ErrCommentDeleteForbidden = errors.New("hanya penulis atau moderator yang dapat menghapus komentar")
+)
+
+const commentMaxLength = 5000
+
+// @username — diawali awal teks atau karakter bukan huruf/angka agar alamat email tidak terbaca sebagai mention
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w.\-]{2,100})`)
+
+// CommentEditWindow — komentar hanya bisa diubah penulisnya selama jendela ini (env COMMENT_EDIT_WINDOW_MINUTES, default 15)
+func CommentEditWindow() time.Duration {
+	return envDuration("COMMENT_EDIT_WINDOW_MINUTES", time.Minute, 15)
+}
+
+func commentLink(ref DocumentRef, commentID string) string {
+	base := "/documents/"
+	if ref.Type == VersionTypeDocumentStaff {
+		base = "/document_staff/"
+	}
+	return base + ref.ID + "?comment=" + commentID
+}
+
+func validCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return body, ErrCommentEmpty
+	}
+	if utf8.RuneCountInString(body) > commentMaxLength {
+		return body, ErrCommentTooLong
+	}
+	return body, nil
+}
+
+// =========================
+// Mention
+// =========================
+
+// mentionedUsernames — username unik yang di-@mention dalam teks
+func mentionedUsernames(body string) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		name := strings.TrimRight(match[1], ".-")
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// resolveMentions — user yang di-mention dan boleh melihat dokumen; penulis tidak dihitung
+func resolveMentions(ref DocumentRef, body, authorID string) ([]models.User, error) {
+	names := mentionedUsernames(body)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var candidates []models.User
+	if err := config.DB.Where("username IN ? AND id <> ?", names, authorID).Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	users := make([]models.User, 0, len(candidates))
+	for _, u := range candidates {
+		if AuthorizeDocument(u, ref, DocActionView) {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+func replaceMentions(tx *gorm.DB, commentID string, users []models.User) error {
+	if err := tx.Where("comment_id = ?", commentID).Delete(&models.DocumentCommentMention{}).Error; err != nil {
+		return err
+	}
+	for _, u := range users {
+		if err := tx.Create(&models.DocumentCommentMention{CommentID: commentID, UserID: u.ID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// =========================
+// Penonton dokumen (untuk event real-time)
+// =========================
+
+// commentWatchTTL — user dianggap masih membuka dokumen selama ini sejak heartbeat terakhir
+const commentWatchTTL = 2 * time.Minute
+
+var (
+	commentWatchers   = map[string]map[string]time.Time{}
+	commentWatchersMu sync.Mutex
+)
+
+func commentTopic(ref DocumentRef) string {
+	return ref.Type + ":" + ref.ID
+}
+
+// WatchComments — daftarkan user sebagai penonton dokumen. Akses sudah diperiksa pemanggil.
+func WatchComments(ref DocumentRef, userID string) {
+	commentWatchersMu.Lock()
+	defer commentWatchersMu.Unlock()
+
+	topic := commentTopic(ref)
+	if commentWatchers[topic] == nil {
+		commentWatchers[topic] = map[string]time.Time{}
+	}
+	commentWatchers[topic][userID] = time.Now().Add(commentWatchTTL)
+}
+
+func UnwatchComments(ref DocumentRef, userID string) {
+	commentWatchersMu.Lock()
+	defer commentWatchersMu.Unlock()
+
+	topic := commentTopic(ref)
+	delete(commentWatchers[topic], userID)
+	if len(commentWatchers[topic]) == 0 {
+		delete(commentWatchers, topic)
+	}
+}
+
+// commentWatcherIDs — penonton yang masih aktif; yang kedaluwarsa dibuang
+func commentWatcherIDs(ref DocumentRef) []string {
+	commentWatchersMu.Lock()
+	defer commentWatchersMu.Unlock()
+
+	topic := commentTopic(ref)
+	now := time.Now()
+	var ids []string
+	for userID, expiresAt := range commentWatchers[topic] {
+		if now.After(expiresAt) {
+			delete(commentWatchers[topic], userID)
+			continue
+		}
+		ids = append(ids, userID)
+	}
+	if len(commentWatchers[topic]) == 0 {
+		delete(commentWatchers, topic)
+	}
+	return ids
+}
+
+func emitCommentEvent(ref DocumentRef, eventType string, payload map[string]interface{}) {
+	if ws.HubInstance == nil {
+		return
+	}
+
+	payload["document_type"] = ref.Type
+	payload["document_id"] = ref.ID
+	for _, userID := range commentWatcherIDs(ref) {
+		ws.HubInstance.Emit(ws.NotificationEvent{
+			UserID:  userID,
+			Type:    eventType,
+			Message: ref.ID,
+			Payload: payload,
+		})
+	}
+}
+
+// =========================
+// Komentar
+// =========================
+
+func preloadComment(db *gorm.DB) *gorm.DB {
+	return db.Preload("User", selectSearchUser).Preload("Mentions.User", selectSearchUser)
+}
+
+// DocumentComments — seluruh komentar dokumen, terlama lebih dulu. Komentar yang dihapus
+// hanya disertakan (tanpa isi) bila masih memiliki balasan.
+func DocumentComments(ref DocumentRef) ([]models.DocumentComment, error) {
+	var all []models.DocumentComment
+	if err := preloadComment(config.DB.Unscoped()).
+		Where("document_type = ? AND document_id = ?", ref.Type, ref.ID).
+		Order("created_at ASC, id ASC").
+		Find(&all).Error; err != nil {
+		return nil, err
+	}
+
+	replied := map[string]bool{}
+	for _, c := range all {
+		if c.ParentID != nil {
+			replied[*c.ParentID] = true
+		}
+	}
+
+	comments := make([]models.DocumentComment, 0, len(all))
+	for _, c := range all {
+		if c.DeletedAt.Valid {
+			if !replied[c.ID] {
+				continue
+			}
+			c.Body = ""
+			c.Mentions = nil
+		}
+		comments = append(comments, c)
+	}
+	return comments, nil
+}
+
+func FindDocumentComment(ref DocumentRef, id string) (models.DocumentComment, error) {
+	var comment models.DocumentComment
+	err := preloadComment(config.DB).
+		Where("document_type = ? AND document_id = ?", ref.Type, ref.ID).
+		First(&comment, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return comment, ErrCommentNotFound
+	}
+	return comment, err
+}
+
+// CreateDocumentComment — simpan komentar, beri tahu user yang di-mention dan penulis
+// komentar yang dibalas, lalu kirim event ke penonton dokumen
+func CreateDocumentComment(ref DocumentRef, author models.User, parentID *string, body string) (models.DocumentComment, error) {
+	body, err := validCommentBody(body)
+	if err != nil {
+		return models.DocumentComment{}, err
+	}
+
+	var parent *models.DocumentComment
+	if parentID != nil && *parentID != "" {
+		found, err := FindDocumentComment(ref, *parentID)
+		if err != nil {
+			return models.DocumentComment{}, ErrCommentParent
+		}
+		parent = &found
+	} else {
+		parentID = nil
+	}
+
+	mentioned, err := resolveMentions(ref, body, author.ID)
+	if err != nil {
+		return models.DocumentComment{}, err
+	}
+
+	comment := models.DocumentComment{
+		DocumentType: ref.Type,
+		DocumentID:   ref.ID,
+		ParentID:     parentID,
+		UserID:       &author.ID,
+		Body:         body,
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Mentions").Create(&comment).Error; err != nil {
+			return err
+		}
+		return replaceMentions(tx, comment.ID, mentioned)
+	})
+	if err != nil {
+		return comment, err
+	}
+
+	comment, err = FindDocumentComment(ref, comment.ID)
+	if err != nil {
+		return comment, err
+	}
+
+	notified := map[string]bool{author.ID: true}
+	for _, u := range mentioned {
+		notified[u.ID] = true
+		NotifySpecificUser(u.ID, author.Name+" menyebut Anda dalam komentar di "+ref.Name, commentLink(ref, comment.ID))
+	}
+	if parent != nil && parent.UserID != nil && !notified[*parent.UserID] {
+		NotifySpecificUser(*parent.UserID, author.Name+" membalas komentar Anda di "+ref.Name, commentLink(ref, comment.ID))
+	}
+
+	emitCommentEvent(ref, CommentEventAdded, map[string]interface{}{"comment": comment})
+	return comment, nil
+}
+
+// UpdateDocumentComment — hanya penulis, selama CommentEditWindow sejak komentar dibuat.
+// User yang baru di-mention ikut diberi tahu.
+func UpdateDocumentComment(ref DocumentRef, comment models.DocumentComment, editor models.User, body string) (models.DocumentComment, error) {
+	if comment.UserID == nil || *comment.UserID != editor.ID {
+		return comment, ErrCommentNotAuthor
+	}
+	if time.Since(comment.CreatedAt) > CommentEditWindow() {
+		return comment, ErrCommentEditWindow
+	}
+
+	body, err := validCommentBody(body)
+	if err != nil {
+		return comment, err
+	}
+
+	mentioned, err := resolveMentions(ref, body, editor.ID)
+	if err != nil {
+		return comment, err
+	}
+
+	previous := map[string]bool{}
+	for _, m := range comment.Mentions {
+		previous[m.UserID] = true
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&comment).Omit("Mentions").Updates(map[string]interface{}{
+			"body":      body,
+			"edited_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		return replaceMentions(tx, comment.ID, mentioned)
+	})
+	if err != nil {
+		return comment, err
+	}
+
+	comment, err = FindDocumentComment(ref, comment.ID)
+	if err != nil {
+		return comment, err
+	}
+
+	for _, u := range mentioned {
+		if !previous[u.ID] {
+			NotifySpecificUser(u.ID, editor.Name+" menyebut Anda dalam komentar di "+ref.Name, commentLink(ref, comment.ID))
+		}
+	}
+
+	emitCommentEvent(ref, CommentEventUpdated, map[string]interface{}{"comment": comment})
+	return comment, nil
+}
+
+// DeleteDocumentComment — soft delete oleh penulis atau moderator; balasan tetap ada
+func DeleteDocumentComment(ref DocumentRef, comment models.DocumentComment, actor models.User) error {
+	author := comment.UserID != nil && *comment.UserID == actor.ID
+	if !author && !HasPermission(actor, PermCommentModerate) {
+		return ErrCommentDeleteForbidden
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&comment).Omit("Mentions").Update("deleted_by_id", actor.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&comment).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	emitCommentEvent(ref, CommentEventDeleted, map[string]interface{}{"comment_id": comment.ID})
+	return nil
+}
+
+// DeleteDocumentComments — hapus permanen komentar dokumen (saat dokumen di-purge dari trash)
+func DeleteDocumentComments(docType, docID string) {
+	config.DB.Unscoped().
+		Where("document_type = ? AND document_id = ?", docType, docID).
+		Delete(&models.DocumentComment{})
+}
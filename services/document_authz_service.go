@@ -0,0 +1,185 @@
+package services
+
+import (
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+
+	"gorm.io/gorm"
+)
+
+// Aksi yang diperiksa oleh AuthorizeDocument
+const (
+	DocActionView     = "view"
+	DocActionDownload = "download"
+	DocActionEdit     = "edit"
+	DocActionDelete   = "delete"
+	DocActionShare    = "share"
+	DocActionReview   = "review" // khusus dokumen staf
+)
+
+// Tingkat izin share: download sudah mencakup view
+const (
+	SharePermissionView     = "view"
+	SharePermissionDownload = "download"
+)
+
+// DocumentRef — identitas dokumen yang diperiksa aksesnya, baik surat maupun dokumen staf
+type DocumentRef struct {
+	Type    string // VersionTypeDocument / VersionTypeDocumentStaff
+	ID      string
+	Name    string
+	OwnerID string
+	UnitID  *string
+
+	// dokumen kepegawaian (lihat authorizeStaffDocument)
+	EmployeeID string
+}
+
+func DocumentRefOf(d models.Document) DocumentRef {
+	ref := DocumentRef{Type: VersionTypeDocument, ID: d.ID, Name: d.FileName, UnitID: d.UnitID}
+	if d.UserID != nil {
+		ref.OwnerID = *d.UserID
+	}
+	return ref
+}
+
+func DocumentStaffRefOf(d models.DocumentStaff) DocumentRef {
+	return DocumentRef{Type: VersionTypeDocumentStaff, ID: d.ID, Name: d.FileName, OwnerID: d.UserID, UnitID: d.UnitID, EmployeeID: d.EmployeeID}
+}
+
+// sharePermissionsFor — tingkat share yang cukup untuk sebuah aksi
+func sharePermissionsFor(action string) []string {
+	switch action {
+	case DocActionView:
+		return []string{SharePermissionView, SharePermissionDownload}
+	case DocActionDownload:
+		return []string{SharePermissionDownload}
+	default:
+		// share tidak pernah memberi hak ubah / hapus
+		return nil
+	}
+}
+
+// HasDocumentShare — user memegang share aktif yang cukup untuk aksi tersebut
+func HasDocumentShare(userID string, ref DocumentRef, action string) bool {
+	permissions := sharePermissionsFor(action)
+	if len(permissions) == 0 {
+		return false
+	}
+
+	var count int64
+	config.DB.Model(&models.DocumentShare{}).
+		Where("document_type = ? AND document_id = ? AND user_id = ?", ref.Type, ref.ID, userID).
+		Where("permission IN ?", permissions).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Count(&count)
+	return count > 0
+}
+
+// sharedDocumentIDs — subquery ID dokumen yang dibagikan ke user (share masih berlaku)
+func sharedDocumentIDs(docType, userID string) *gorm.DB {
+	return config.DB.Model(&models.DocumentShare{}).
+		Select("document_id").
+		Where("document_type = ? AND user_id = ?", docType, userID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now())
+}
+
+// isUnitAdmin — kepala unit (atau unit di atasnya) dari unit pemilik dokumen
+func isUnitAdmin(user models.User, unitID *string) bool {
+	return unitID != nil && containsString(SubtreeUnitIDs(HeadedUnitIDs(user.ID)...), *unitID)
+}
+
+// AuthorizeDocument — satu pintu pemeriksaan akses dokumen.
+//
+// Surat: izin role (document.view/download/update/delete/share) dalam cakupan unit,
+// pengunggah boleh melihat & mengunduh, share memberi hak view/download.
+//
+// Dokumen staf: pemilik, kepala unit pemilik dan pemegang staffdoc.manage_all boleh
+// semua aksi termasuk membagikan; anggota unit (atau staffdoc.view_all_units) dan
+// pemegang share hanya boleh melihat & mengunduh. Review dilakukan kepala unit pemilik
+// atau pemegang staffdoc.review yang boleh melihat dokumen, tidak pernah oleh pemiliknya.
+func AuthorizeDocument(user models.User, ref DocumentRef, action string) bool {
+	if ref.Type == VersionTypeDocument {
+		return authorizeLetter(user, ref, action)
+	}
+	return authorizeStaffDocument(user, ref, action)
+}
+
+func authorizeLetter(user models.User, ref DocumentRef, action string) bool {
+	owner := ref.OwnerID != "" && ref.OwnerID == user.ID
+
+	var permission string
+	switch action {
+	case DocActionView:
+		permission = PermDocumentView
+	case DocActionDownload:
+		permission = PermDocumentDownload
+	case DocActionEdit:
+		permission = PermDocumentUpdate
+	case DocActionDelete:
+		permission = PermDocumentDelete
+	case DocActionShare:
+		permission = PermDocumentShare
+	default:
+		return false
+	}
+
+	if HasPermission(user, permission) {
+		if owner || DocumentAccess(user).CanViewDocument(models.Document{ID: ref.ID, UnitID: ref.UnitID}) {
+			return true
+		}
+	}
+	if owner && (action == DocActionView || action == DocActionDownload) {
+		return true
+	}
+	return HasDocumentShare(user.ID, ref, action)
+}
+
+func authorizeStaffDocument(user models.User, ref DocumentRef, action string) bool {
+	// dokumen kepegawaian hanya untuk pengelola pegawai; tidak dibagikan dan tidak direview
+	if ref.EmployeeID != "" {
+		return action != DocActionShare && action != DocActionReview && HasPermission(user, PermEmployeeManage)
+	}
+
+	if action == DocActionReview {
+		if ref.OwnerID == user.ID {
+			return false
+		}
+		if isUnitAdmin(user, ref.UnitID) {
+			return true
+		}
+		return HasPermission(user, PermStaffDocReview) &&
+			DocumentStaffAccess(user).CanViewDocumentStaff(models.DocumentStaff{UserID: ref.OwnerID, UnitID: ref.UnitID})
+	}
+
+	if ref.OwnerID == user.ID || HasPermission(user, PermStaffDocManageAll) || isUnitAdmin(user, ref.UnitID) {
+		return true
+	}
+
+	switch action {
+	case DocActionView, DocActionDownload:
+		access := DocumentStaffAccess(user)
+		if access.CanViewDocumentStaff(models.DocumentStaff{UserID: ref.OwnerID, UnitID: ref.UnitID}) {
+			return true
+		}
+		return HasDocumentShare(user.ID, ref, action)
+	default:
+		return false
+	}
+}
+
+// RecordAccessDenied — catat percobaan akses yang ditolak ke log aktivitas
+func RecordAccessDenied(user models.User, ref DocumentRef, action, source string) {
+	label := "surat"
+	if ref.Type == VersionTypeDocumentStaff {
+		label = "dokumen staff"
+	}
+
+	message := "Akses " + action + " ditolak untuk " + label + ": " + ref.Name + " (" + ref.ID + ")"
+	if source != "" {
+		message += " — " + source
+	}
+	CreateActivity(user.ID, user.Name, "access_denied", message)
+}
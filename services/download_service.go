@@ -0,0 +1,150 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+)
+
+// Jalur unduhan yang dicatat di document_downloads
+const (
+	DownloadViaDirect    = "direct"
+	DownloadViaSigned    = "signed"
+	DownloadViaShareLink = "share_link"
+	DownloadViaExport    = "export"
+)
+
+var (
+	ErrDownloadTokenInvalid = errors.New("tautan unduhan tidak valid")
+	ErrDownloadTokenExpired = errors.New("tautan unduhan sudah kedaluwarsa")
+)
+
+// DownloadURLTTL — umur URL unduhan bertanda tangan (env DOWNLOAD_URL_TTL_MINUTES, default 5 menit)
+func DownloadURLTTL() time.Duration {
+	return envDuration("DOWNLOAD_URL_TTL_MINUTES", time.Minute, 5)
+}
+
+// DownloadTarget — file yang boleh diunduh lewat URL bertanda tangan
+type DownloadTarget struct {
+	Type    string `json:"t"` // VersionTypeDocument / VersionTypeDocumentStaff
+	ID      string `json:"d"`
+	Version *int   `json:"v,omitempty"` // nil = file terbaru
+}
+
+type downloadClaims struct {
+	DownloadTarget
+	SessionID string `json:"s"`
+	ExpiresAt int64  `json:"e"`
+}
+
+// downloadKey — kunci HMAC diturunkan dari JWT_SECRET agar tanda tangan URL
+// tidak bisa dipertukarkan dengan token lain yang memakai secret yang sama
+func downloadKey() []byte {
+	mac := hmac.New(sha256.New, jwtSecret())
+	mac.Write([]byte("document-download"))
+	return mac.Sum(nil)
+}
+
+func signDownloadPayload(payload string) string {
+	mac := hmac.New(sha256.New, downloadKey())
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignDownload — buat token URL unduhan yang terikat ke sesi login pemintanya.
+// Logout / pencabutan sesi otomatis membatalkan URL yang sudah dibagikan.
+func SignDownload(target DownloadTarget, sessionID string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(DownloadURLTTL())
+
+	raw, err := json.Marshal(downloadClaims{
+		DownloadTarget: target,
+		SessionID:      sessionID,
+		ExpiresAt:      expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", expiresAt, err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(raw)
+	return payload + "." + signDownloadPayload(payload), expiresAt, nil
+}
+
+// VerifyDownload — periksa tanda tangan & masa berlaku token, lalu kembalikan
+// target beserta user pemilik sesi (hak akses tetap diperiksa ulang oleh pemanggil)
+func VerifyDownload(token string) (DownloadTarget, models.User, error) {
+	var claims downloadClaims
+
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(signDownloadPayload(payload))) {
+		return claims.DownloadTarget, models.User{}, ErrDownloadTokenInvalid
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil || json.Unmarshal(raw, &claims) != nil {
+		return claims.DownloadTarget, models.User{}, ErrDownloadTokenInvalid
+	}
+	if time.Now().Unix() > claims.ExpiresAt {
+		return claims.DownloadTarget, models.User{}, ErrDownloadTokenExpired
+	}
+
+	var session models.SecretToken
+	if err := config.DB.Preload("User").Where("id = ?", claims.SessionID).First(&session).Error; err != nil ||
+		time.Now().After(session.ExpiresAt) || session.User.ID == "" {
+		return claims.DownloadTarget, models.User{}, ErrDownloadTokenExpired
+	}
+	if session.User.MustChangePassword {
+		return claims.DownloadTarget, models.User{}, ErrDownloadTokenInvalid
+	}
+
+	return claims.DownloadTarget, session.User, nil
+}
+
+// DownloadEvent — satu unduhan yang akan dicatat
+type DownloadEvent struct {
+	Ref         DocumentRef
+	Version     *int
+	FileName    string
+	User        *models.User // nil untuk tautan publik
+	ShareLinkID *string
+	Via         string
+	IPAddress   string
+	UserAgent   string
+}
+
+// RecordDownload — simpan jejak unduhan; unduhan oleh user login juga masuk log aktivitas
+func RecordDownload(event DownloadEvent) {
+	userAgent := event.UserAgent
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	download := models.DocumentDownload{
+		DocumentType: event.Ref.Type,
+		DocumentID:   event.Ref.ID,
+		Version:      event.Version,
+		FileName:     event.FileName,
+		ShareLinkID:  event.ShareLinkID,
+		Via:          event.Via,
+		IPAddress:    event.IPAddress,
+		UserAgent:    userAgent,
+	}
+	if event.User != nil {
+		download.UserID = &event.User.ID
+	}
+	config.DB.Create(&download)
+
+	if event.User != nil {
+		label := "surat"
+		if event.Ref.Type == VersionTypeDocumentStaff {
+			label = "dokumen staff"
+		}
+		CreateActivity(event.User.ID, event.User.Name, "download", "Mengunduh "+label+": "+event.FileName)
+	}
+}
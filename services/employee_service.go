@@ -0,0 +1,234 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+
+	"gorm.io/gorm"
+)
+
+// Status pegawai
+const (
+	EmployeeActive   = "active"
+	EmployeeInactive = "inactive"
+)
+
+var (
+	ErrEmployeeNotFound     = errors.New("pegawai tidak ditemukan")
+	ErrEmployeeNIPInvalid   = errors.New("NIP harus terdiri dari 18 digit angka")
+	ErrEmployeeNIPExists    = errors.New("NIP sudah terdaftar")
+	ErrEmployeeNameRequired = errors.New("nama pegawai wajib diisi")
+	ErrEmployeeStatus       = errors.New("status pegawai harus active atau inactive")
+	ErrEmployeeHasDocuments = errors.New("pegawai masih memiliki berkas kepegawaian; tandai inactive bila sudah tidak bertugas")
+	ErrEmployeeUnitNotFound = errors.New("unit pegawai tidak ditemukan")
+)
+
+var nipPattern = regexp.MustCompile(`^\d{18}$`)
+
+// normalizeNIP — NIP sering ditulis berkelompok ("19800101 200501 1 001"), spasi dibuang
+func normalizeNIP(nip string) (string, error) {
+	nip = strings.Join(strings.Fields(nip), "")
+	if !nipPattern.MatchString(nip) {
+		return nip, ErrEmployeeNIPInvalid
+	}
+	return nip, nil
+}
+
+// EmployeeInput — field yang nil tidak diubah; string kosong mengosongkan field opsional
+type EmployeeInput struct {
+	NIP      *string
+	Name     *string
+	Position *string
+	Rank     *string
+	Grade    *string
+	Status   *string
+	UnitID   *string
+}
+
+func FindEmployee(id string) (models.Employee, error) {
+	var employee models.Employee
+	if err := config.DB.First(&employee, "id = ?", id).Error; err != nil {
+		return employee, ErrEmployeeNotFound
+	}
+	return employee, nil
+}
+
+func checkEmployeeNIP(tx *gorm.DB, nip, exceptID string) error {
+	var count int64
+	tx.Model(&models.Employee{}).Where("nip = ? AND id <> ?", nip, exceptID).Count(&count)
+	if count > 0 {
+		return ErrEmployeeNIPExists
+	}
+	return nil
+}
+
+func checkEmployeeUnit(tx *gorm.DB, unitID *string) error {
+	if unitID == nil {
+		return nil
+	}
+	var count int64
+	tx.Model(&models.OrgUnit{}).Where("id = ?", *unitID).Count(&count)
+	if count == 0 {
+		return ErrEmployeeUnitNotFound
+	}
+	return nil
+}
+
+func validEmployeeStatus(status string) bool {
+	return status == EmployeeActive || status == EmployeeInactive
+}
+
+func trimmed(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return strings.TrimSpace(*value)
+}
+
+func CreateEmployee(input EmployeeInput) (models.Employee, error) {
+	var employee models.Employee
+
+	nip, err := normalizeNIP(trimmed(input.NIP))
+	if err != nil {
+		return employee, err
+	}
+	if trimmed(input.Name) == "" {
+		return employee, ErrEmployeeNameRequired
+	}
+
+	employee = models.Employee{
+		NIP:      nip,
+		Name:     trimmed(input.Name),
+		Position: trimmed(input.Position),
+		Rank:     trimmed(input.Rank),
+		Grade:    trimmed(input.Grade),
+		Status:   EmployeeActive,
+		UnitID:   emptyToNil(input.UnitID),
+	}
+	if input.Status != nil {
+		if !validEmployeeStatus(*input.Status) {
+			return employee, ErrEmployeeStatus
+		}
+		employee.Status = *input.Status
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkEmployeeNIP(tx, employee.NIP, ""); err != nil {
+			return err
+		}
+		if err := checkEmployeeUnit(tx, employee.UnitID); err != nil {
+			return err
+		}
+		return tx.Create(&employee).Error
+	})
+
+	return employee, err
+}
+
+// UpdateEmployee — berkas yang sudah diunggah tetap milik unit lamanya bila pegawai pindah unit
+func UpdateEmployee(id string, input EmployeeInput) (models.Employee, error) {
+	employee, err := FindEmployee(id)
+	if err != nil {
+		return employee, err
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{}
+
+		if input.NIP != nil {
+			nip, err := normalizeNIP(*input.NIP)
+			if err != nil {
+				return err
+			}
+			if err := checkEmployeeNIP(tx, nip, employee.ID); err != nil {
+				return err
+			}
+			employee.NIP = nip
+			updates["nip"] = nip
+		}
+		if input.Name != nil {
+			name := trimmed(input.Name)
+			if name == "" {
+				return ErrEmployeeNameRequired
+			}
+			employee.Name = name
+			updates["name"] = name
+		}
+		if input.Position != nil {
+			employee.Position = trimmed(input.Position)
+			updates["position"] = employee.Position
+		}
+		if input.Rank != nil {
+			employee.Rank = trimmed(input.Rank)
+			updates["rank_title"] = employee.Rank
+		}
+		if input.Grade != nil {
+			employee.Grade = trimmed(input.Grade)
+			updates["grade"] = employee.Grade
+		}
+		if input.Status != nil {
+			if !validEmployeeStatus(*input.Status) {
+				return ErrEmployeeStatus
+			}
+			employee.Status = *input.Status
+			updates["status"] = employee.Status
+		}
+		if input.UnitID != nil {
+			unitID := emptyToNil(input.UnitID)
+			if err := checkEmployeeUnit(tx, unitID); err != nil {
+				return err
+			}
+			employee.UnitID = unitID
+			updates["unit_id"] = unitID
+		}
+
+		if len(updates) == 0 {
+			return nil
+		}
+		return tx.Model(&employee).Updates(updates).Error
+	})
+
+	return employee, err
+}
+
+// DeleteEmployee — hanya pegawai tanpa berkas (termasuk yang ada di trash) yang dapat dihapus
+func DeleteEmployee(id string) (models.Employee, error) {
+	employee, err := FindEmployee(id)
+	if err != nil {
+		return employee, err
+	}
+
+	var count int64
+	config.DB.Unscoped().Model(&models.DocumentStaff{}).Where("employee_id = ?", employee.ID).Count(&count)
+	if count > 0 {
+		return employee, ErrEmployeeHasDocuments
+	}
+
+	return employee, config.DB.Delete(&employee).Error
+}
+
+// EmployeeDocumentCounts — jumlah berkas (di luar trash) per pegawai
+func EmployeeDocumentCounts(employeeIDs []string) (map[string]int64, error) {
+	counts := make(map[string]int64, len(employeeIDs))
+	if len(employeeIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		EmployeeID string
+		Total      int64
+	}
+	err := config.DB.Model(&models.DocumentStaff{}).
+		Select("employee_id, COUNT(*) AS total").
+		Where("employee_id IN ?", employeeIDs).
+		Group("employee_id").
+		Scan(&rows).Error
+	for _, row := range rows {
+		counts[row.EmployeeID] = row.Total
+	}
+	return counts, err
+}
@@ -0,0 +1,432 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+	ws "dinsos_kuburaya/websocket"
+)
+
+// Status ExportJob
+const (
+	ExportPending = "pending"
+	ExportRunning = "running"
+	ExportDone    = "done"
+	ExportFailed  = "failed"
+)
+
+var (
+	ErrExportEmpty    = errors.New("tidak ada surat yang dapat diekspor")
+	ErrExportTooLarge = errors.New("jumlah surat melebihi batas ekspor")
+	ErrExportRunning  = errors.New("masih ada ekspor yang sedang diproses")
+	ErrExportNotFound = errors.New("ekspor tidak ditemukan")
+	ErrExportNotReady = errors.New("ekspor belum selesai atau sudah kedaluwarsa")
+)
+
+const exportDateLayout = "2006-01-02"
+
+// ExportSyncLimit — ekspor sampai jumlah ini langsung dialirkan, lebih dari itu
+// dibangun di background (env EXPORT_SYNC_MAX_FILES, default 25)
+func ExportSyncLimit() int {
+	return envInt("EXPORT_SYNC_MAX_FILES", 25)
+}
+
+// ExportMaxFiles — batas jumlah surat dalam satu ekspor (env EXPORT_MAX_FILES, default 2000)
+func ExportMaxFiles() int {
+	return envInt("EXPORT_MAX_FILES", 2000)
+}
+
+// ExportRetention — masa simpan ZIP hasil ekspor background (env EXPORT_RETENTION_HOURS, default 24 jam)
+func ExportRetention() time.Duration {
+	return envDuration("EXPORT_RETENTION_HOURS", time.Hour, 24)
+}
+
+// exportDir — folder ZIP hasil ekspor (env EXPORT_DIR, default <tmp>/dinsos-exports)
+func exportDir() string {
+	if dir := os.Getenv("EXPORT_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "dinsos-exports")
+}
+
+// ExportArchiveName — nama file ZIP ekspor
+func ExportArchiveName(at time.Time) string {
+	return "ekspor-surat-" + at.Format("20060102-150405") + ".zip"
+}
+
+// =========================
+// Penulisan arsip
+// =========================
+
+// LoadExportDocuments — ambil surat beserta relasi untuk manifest, urutan mengikuti ids
+func LoadExportDocuments(ids []string) ([]models.Document, error) {
+	var rows []models.Document
+	if err := config.DB.
+		Preload("User", selectSearchUser).
+		Preload("Unit").
+		Where("id IN ?", ids).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]models.Document, len(rows))
+	for _, d := range rows {
+		byID[d.ID] = d
+	}
+
+	documents := make([]models.Document, 0, len(rows))
+	for _, id := range ids {
+		if d, ok := byID[id]; ok {
+			documents = append(documents, d)
+		}
+	}
+	return documents, nil
+}
+
+// archiveEntryName — nama file di dalam ZIP; nomor urut mencegah nama kembar
+func archiveEntryName(index int, fileName string) string {
+	name := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		if r < 0x20 {
+			return -1
+		}
+		return r
+	}, fileName)
+	if name == "" {
+		name = "file"
+	}
+	return fmt.Sprintf("files/%04d_%s", index+1, name)
+}
+
+// csvSafe — cegah isi sel dieksekusi sebagai formula saat manifest dibuka di spreadsheet
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func formatExportDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(exportDateLayout)
+}
+
+// WriteDocumentArchive — tulis ZIP berisi file surat dan manifest.csv ke w.
+// progress dipanggil setiap satu surat selesai. File yang gagal diambil dari
+// storage tetap tercantum di manifest dengan status error.
+func WriteDocumentArchive(w io.Writer, documents []models.Document, progress func(done, failed int)) (int, error) {
+	archive := zip.NewWriter(w)
+
+	manifest := [][]string{{
+		"no", "file", "nomor_agenda", "nomor_surat", "tanggal_surat", "tanggal_diterima",
+		"jenis", "pengirim", "penerima", "perihal", "klasifikasi", "sifat", "kerahasiaan",
+		"unit", "diunggah_oleh", "diunggah_pada", "status",
+	}}
+
+	failed := 0
+	for i, d := range documents {
+		entry := archiveEntryName(i, d.FileName)
+		status := "ok"
+
+		if err := copyToArchive(archive, entry, d); err != nil {
+			if errors.Is(err, errArchiveWrite) {
+				return failed, err
+			}
+			log.Printf("[Export] ⚠️ file %s gagal diambil: %v", d.ID, err)
+			status = "error: file tidak tersedia"
+			entry = ""
+			failed++
+		}
+
+		unit := ""
+		if d.Unit != nil {
+			unit = d.Unit.Name
+		}
+		manifest = append(manifest, []string{
+			strconv.Itoa(i + 1), csvSafe(entry), csvSafe(d.AgendaNumber), csvSafe(d.LetterNumber),
+			formatExportDate(d.LetterDate), formatExportDate(d.ReceivedDate),
+			d.LetterType, csvSafe(d.Sender), csvSafe(d.Recipient), csvSafe(d.Subject), d.ClassificationCode,
+			d.Urgency, d.Confidentiality, csvSafe(unit), csvSafe(d.User.Name),
+			d.CreatedAt.Format("2006-01-02 15:04:05"), status,
+		})
+
+		if progress != nil {
+			progress(i+1, failed)
+		}
+	}
+
+	out, err := archive.Create("manifest.csv")
+	if err != nil {
+		return failed, err
+	}
+	// BOM agar Excel membaca UTF-8 dengan benar
+	if _, err := out.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return failed, err
+	}
+	if err := csv.NewWriter(out).WriteAll(manifest); err != nil {
+		return failed, err
+	}
+
+	return failed, archive.Close()
+}
+
+// errArchiveWrite — kegagalan menulis ke tujuan (klien terputus / disk penuh), ekspor dihentikan
+var errArchiveWrite = errors.New("gagal menulis arsip")
+
+func copyToArchive(archive *zip.Writer, entry string, d models.Document) error {
+	reader, err := config.FileStorage.Open(d.PublicID, d.ResourceType)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	header := &zip.FileHeader{Name: entry, Method: zip.Deflate, Modified: d.CreatedAt}
+	dst, err := archive.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errArchiveWrite, err)
+	}
+	if _, err := io.Copy(dst, reader); err != nil {
+		return fmt.Errorf("%w: %v", errArchiveWrite, err)
+	}
+	return nil
+}
+
+// RecordExportDownloads — setiap surat di dalam ekspor tercatat sebagai unduhan
+func RecordExportDownloads(user models.User, documents []models.Document, ip, userAgent string) {
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	rows := make([]models.DocumentDownload, 0, len(documents))
+	for _, d := range documents {
+		rows = append(rows, models.DocumentDownload{
+			DocumentType: VersionTypeDocument,
+			DocumentID:   d.ID,
+			FileName:     d.FileName,
+			UserID:       &user.ID,
+			Via:          DownloadViaExport,
+			IPAddress:    ip,
+			UserAgent:    userAgent,
+		})
+	}
+	if len(rows) > 0 {
+		config.DB.CreateInBatches(rows, 200)
+	}
+
+	CreateActivity(user.ID, user.Name, "download", "Mengekspor "+strconv.Itoa(len(documents))+" surat ke ZIP")
+}
+
+// =========================
+// Ekspor di background
+// =========================
+
+// StartDocumentExport — buat job ekspor lalu bangun ZIP-nya di goroutine terpisah.
+// Satu user hanya boleh memiliki satu ekspor yang sedang berjalan.
+func StartDocumentExport(user models.User, ids []string, ip, userAgent string) (models.ExportJob, error) {
+	job := models.ExportJob{UserID: user.ID, Status: ExportPending, Total: len(ids)}
+
+	var running int64
+	config.DB.Model(&models.ExportJob{}).
+		Where("user_id = ? AND status IN ?", user.ID, []string{ExportPending, ExportRunning}).
+		Count(&running)
+	if running > 0 {
+		return job, ErrExportRunning
+	}
+
+	raw, err := json.Marshal(ids)
+	if err != nil {
+		return job, err
+	}
+	job.DocumentIDs = string(raw)
+	job.FileName = ExportArchiveName(time.Now())
+
+	if err := config.DB.Create(&job).Error; err != nil {
+		return job, err
+	}
+
+	go runDocumentExport(job, user, ids, ip, userAgent)
+	return job, nil
+}
+
+func runDocumentExport(job models.ExportJob, user models.User, ids []string, ip, userAgent string) {
+	fail := func(err error) {
+		log.Printf("[Export] ❌ job %s gagal: %v", job.ID, err)
+		job.Status = ExportFailed
+		job.Error = err.Error()
+		if len(job.Error) > 500 {
+			job.Error = job.Error[:500]
+		}
+		config.DB.Model(&job).Updates(map[string]interface{}{"status": job.Status, "error": job.Error})
+		emitExportEvent(job, "export_failed", "Ekspor surat gagal")
+	}
+
+	config.DB.Model(&job).Update("status", ExportRunning)
+	job.Status = ExportRunning
+	emitExportEvent(job, "export_progress", "Ekspor surat dimulai")
+
+	documents, err := LoadExportDocuments(ids)
+	if err != nil {
+		fail(err)
+		return
+	}
+	job.Total = len(documents)
+
+	if err := os.MkdirAll(exportDir(), 0o700); err != nil {
+		fail(err)
+		return
+	}
+	path := filepath.Join(exportDir(), job.ID+".zip")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	// progres dikirim setiap kenaikan 5% agar hub tidak dibanjiri event
+	lastPercent := -1
+	failed, err := WriteDocumentArchive(file, documents, func(done, failed int) {
+		job.Processed, job.Failed = done, failed
+		percent := done * 100 / job.Total
+		if percent/5 == lastPercent/5 && done != job.Total {
+			return
+		}
+		lastPercent = percent
+		config.DB.Model(&job).Updates(map[string]interface{}{"processed": done, "failed": failed, "total": job.Total})
+		emitExportEvent(job, "export_progress", "Ekspor surat "+strconv.Itoa(percent)+"%")
+	})
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		fail(err)
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ExportRetention())
+	job.Status = ExportDone
+	job.Failed = failed
+	job.FilePath = path
+	job.Size = info.Size()
+	job.CompletedAt = &now
+	job.ExpiresAt = &expiresAt
+	job.DownloadURL = "/api/exports/" + job.ID + "/download"
+	config.DB.Model(&job).Updates(map[string]interface{}{
+		"status":       job.Status,
+		"processed":    job.Processed,
+		"failed":       job.Failed,
+		"file_path":    job.FilePath,
+		"size":         job.Size,
+		"completed_at": now,
+		"expires_at":   expiresAt,
+	})
+
+	RecordExportDownloads(user, documents, ip, userAgent)
+	emitExportEvent(job, "export_ready", "Ekspor surat siap diunduh")
+	NotifySpecificUser(user.ID, "Ekspor "+strconv.Itoa(job.Total)+" surat siap diunduh", "/exports/"+job.ID)
+}
+
+func emitExportEvent(job models.ExportJob, eventType, message string) {
+	if ws.HubInstance == nil {
+		return
+	}
+
+	ws.HubInstance.Emit(ws.NotificationEvent{
+		UserID:  job.UserID,
+		Type:    eventType,
+		Message: message,
+		Payload: job,
+	})
+}
+
+// UserExportJobs — daftar ekspor milik user, terbaru lebih dulu
+func UserExportJobs(userID string) ([]models.ExportJob, error) {
+	var jobs []models.ExportJob
+	err := config.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&jobs).Error
+	return jobs, err
+}
+
+// FindExportJob — ekspor hanya bisa dilihat oleh pembuatnya
+func FindExportJob(userID, id string) (models.ExportJob, error) {
+	var job models.ExportJob
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&job).Error; err != nil {
+		return job, ErrExportNotFound
+	}
+	return job, nil
+}
+
+// OpenExportArchive — buka ZIP ekspor yang sudah selesai dan belum kedaluwarsa
+func OpenExportArchive(job models.ExportJob) (*os.File, error) {
+	if job.Status != ExportDone || job.FilePath == "" ||
+		(job.ExpiresAt != nil && time.Now().After(*job.ExpiresAt)) {
+		return nil, ErrExportNotReady
+	}
+	file, err := os.Open(job.FilePath)
+	if err != nil {
+		return nil, ErrExportNotReady
+	}
+	return file, nil
+}
+
+// DeleteExportJob — hapus job beserta file ZIP-nya
+func DeleteExportJob(job models.ExportJob) error {
+	if job.FilePath != "" {
+		if err := os.Remove(job.FilePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return config.DB.Delete(&job).Error
+}
+
+// PrepareExportJobs — job yang terputus karena server berhenti ditandai gagal
+func PrepareExportJobs() error {
+	return config.DB.Model(&models.ExportJob{}).
+		Where("status IN ?", []string{ExportPending, ExportRunning}).
+		Updates(map[string]interface{}{"status": ExportFailed, "error": "ekspor terhenti karena server dimulai ulang"}).Error
+}
+
+// PurgeExpiredExports — hapus ZIP ekspor yang melewati masa simpan
+func PurgeExpiredExports() (int, error) {
+	var jobs []models.ExportJob
+	if err := config.DB.
+		Where("(expires_at IS NOT NULL AND expires_at < ?) OR (status = ? AND created_at < ?)",
+			time.Now(), ExportFailed, time.Now().Add(-ExportRetention())).
+		Find(&jobs).Error; err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, job := range jobs {
+		if err := DeleteExportJob(job); err != nil {
+			log.Printf("[Export] ⚠️ gagal menghapus ekspor %s: %v", job.ID, err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
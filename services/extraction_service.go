@@ -0,0 +1,237 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+
+	"gorm.io/gorm"
+)
+
+// status ekstraksi teks pada Document / DocumentStaff
+const (
+	ExtractionPending     = "pending"
+	ExtractionProcessing  = "processing"
+	ExtractionDone        = "done"
+	ExtractionFailed      = "failed"
+	ExtractionUnsupported = "unsupported"
+)
+
+// batas ukuran file yang diunduh untuk diekstrak
+const maxExtractionFileBytes = 100 << 20
+
+// ExtractionColumns — kolom yang hanya diisi worker ekstraksi; jangan ikut di-Save
+// oleh handler agar hasil worker tidak tertimpa data lama
+var ExtractionColumns = []string{
+	"extracted_text", "extraction_status", "extraction_method", "extraction_error", "extracted_at",
+}
+
+// antrean memakai tabel dokumen itu sendiri (status pending); channel ini hanya
+// membangunkan worker supaya tidak menunggu interval polling
+var extractionWake = make(chan struct{}, 1)
+
+// ExtractionWorkers — jumlah worker ekstraksi (env OCR_WORKERS, default 1)
+func ExtractionWorkers() int {
+	if n, err := strconv.Atoi(os.Getenv("OCR_WORKERS")); err == nil && n > 0 {
+		return n
+	}
+	return 1
+}
+
+func extractionModel(docType string) interface{} {
+	if docType == VersionTypeDocument {
+		return &models.Document{}
+	}
+	return &models.DocumentStaff{}
+}
+
+// QueueExtraction — tandai dokumen untuk diekstrak ulang (setelah upload / ganti file)
+func QueueExtraction(docType, docID string) error {
+	err := config.DB.Model(extractionModel(docType)).
+		Where("id = ?", docID).
+		UpdateColumns(map[string]interface{}{
+			"extracted_text":    "",
+			"extraction_status": ExtractionPending,
+			"extraction_method": "",
+			"extraction_error":  "",
+			"extracted_at":      nil,
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	WakeExtractionWorker()
+	return nil
+}
+
+func WakeExtractionWorker() {
+	select {
+	case extractionWake <- struct{}{}:
+	default:
+	}
+}
+
+// WaitForExtractionWork — blok sampai ada antrean baru atau interval habis
+func WaitForExtractionWork(interval time.Duration) {
+	select {
+	case <-extractionWake:
+	case <-time.After(interval):
+	}
+}
+
+// PrepareExtractionQueue — dipanggil saat start: pekerjaan yang terputus karena
+// restart diulang, dan dokumen lama yang belum pernah diekstrak ikut diantrekan
+func PrepareExtractionQueue() error {
+	for _, docType := range []string{VersionTypeDocument, VersionTypeDocumentStaff} {
+		if err := config.DB.Model(extractionModel(docType)).
+			Where("extraction_status IN ? OR extraction_status IS NULL", []string{"", ExtractionProcessing}).
+			UpdateColumn("extraction_status", ExtractionPending).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type extractionTarget struct {
+	DocType      string
+	ID           string
+	FileName     string
+	PublicID     string
+	ResourceType string
+}
+
+// claimNextExtraction — ambil satu dokumen pending dan tandai processing.
+// Update bersyarat memastikan satu dokumen tidak dikerjakan dua worker.
+func claimNextExtraction() (*extractionTarget, error) {
+	for _, docType := range []string{VersionTypeDocument, VersionTypeDocumentStaff} {
+		for {
+			var candidates []extractionTarget
+			if err := config.DB.Model(extractionModel(docType)).
+				Select("id, file_name, public_id, resource_type").
+				Where("extraction_status = ?", ExtractionPending).
+				Order("created_at DESC").
+				Limit(1).
+				Scan(&candidates).Error; err != nil {
+				return nil, err
+			}
+			if len(candidates) == 0 {
+				break
+			}
+
+			target := candidates[0]
+			target.DocType = docType
+
+			claim := config.DB.Model(extractionModel(docType)).
+				Where("id = ? AND extraction_status = ?", target.ID, ExtractionPending).
+				UpdateColumn("extraction_status", ExtractionProcessing)
+			if claim.Error != nil {
+				return nil, claim.Error
+			}
+			if claim.RowsAffected == 1 {
+				return &target, nil
+			}
+			// sudah diambil worker lain, coba kandidat berikutnya
+		}
+	}
+	return nil, nil
+}
+
+// ProcessNextExtraction — kerjakan satu antrean. Mengembalikan false bila antrean kosong.
+func ProcessNextExtraction() (bool, error) {
+	target, err := claimNextExtraction()
+	if err != nil || target == nil {
+		return false, err
+	}
+
+	text, method, err := extractTarget(*target)
+
+	result := map[string]interface{}{
+		"extracted_at": time.Now(),
+	}
+	switch {
+	case errors.Is(err, ErrUnsupportedFormat):
+		result["extraction_status"] = ExtractionUnsupported
+		result["extraction_error"] = ""
+	case err != nil:
+		msg := err.Error()
+		if len(msg) > 500 {
+			msg = msg[:500]
+		}
+		result["extraction_status"] = ExtractionFailed
+		result["extraction_error"] = msg
+		log.Printf("❌ Ekstraksi teks gagal (%s %s): %v\n", target.DocType, target.FileName, err)
+	default:
+		result["extracted_text"] = NormalizeExtractedText(text)
+		result["extraction_status"] = ExtractionDone
+		result["extraction_method"] = method
+		result["extraction_error"] = ""
+	}
+
+	// hanya tulis bila file tidak diganti selama proses berjalan
+	if err := config.DB.Model(extractionModel(target.DocType)).
+		Where("id = ? AND public_id = ? AND extraction_status = ?", target.ID, target.PublicID, ExtractionProcessing).
+		UpdateColumns(result).Error; err != nil {
+		return true, err
+	}
+
+	return true, nil
+}
+
+func extractTarget(target extractionTarget) (string, string, error) {
+	if target.PublicID == "" {
+		return "", "", errors.New("dokumen tidak memiliki file")
+	}
+
+	file, err := config.FileStorage.Open(target.PublicID, target.ResourceType)
+	if err != nil {
+		return "", "", fmt.Errorf("gagal mengambil file: %v", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxExtractionFileBytes+1))
+	if err != nil {
+		return "", "", fmt.Errorf("gagal membaca file: %v", err)
+	}
+	if len(data) > maxExtractionFileBytes {
+		return "", "", errors.New("file terlalu besar untuk diekstrak")
+	}
+
+	return ExtractText(target.FileName, data)
+}
+
+// ExtractedContent — teks hasil ekstraksi beserta statusnya
+func ExtractedContent(docType, docID string) (map[string]interface{}, error) {
+	var row struct {
+		ExtractedText    string
+		ExtractionStatus string
+		ExtractionMethod string
+		ExtractionError  string
+		ExtractedAt      *time.Time
+	}
+
+	result := config.DB.Model(extractionModel(docType)).
+		Select("extracted_text, extraction_status, extraction_method, extraction_error, extracted_at").
+		Where("id = ?", docID).
+		Scan(&row)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return map[string]interface{}{
+		"text":              row.ExtractedText,
+		"extraction_status": row.ExtractionStatus,
+		"extraction_method": row.ExtractionMethod,
+		"extraction_error":  row.ExtractionError,
+		"extracted_at":      row.ExtractedAt,
+	}, nil
+}
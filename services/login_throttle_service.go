@@ -0,0 +1,213 @@
+package services
+
+import (
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	ThrottleScopeUsername = "username"
+	ThrottleScopeIP       = "ip"
+
+	// batas atas penguncian walau gagal terus-menerus
+	maxLockoutDuration = 24 * time.Hour
+)
+
+// LoginBlock — alasan login ditolak sebelum password diperiksa
+type LoginBlock struct {
+	Scope      string
+	Locked     bool // true = penguncian (batas percobaan tercapai), false = backoff
+	RetryAfter time.Duration
+}
+
+func envInt(key string, fallback int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n > 0 {
+		return n
+	}
+	return fallback
+}
+
+// loginFreeAttempts — jumlah gagal sebelum backoff mulai berlaku (env LOGIN_FREE_ATTEMPTS, default 3)
+func loginFreeAttempts() int {
+	return envInt("LOGIN_FREE_ATTEMPTS", 3)
+}
+
+// loginMaxAttempts — batas gagal sebelum dikunci. Batas per IP lebih longgar karena
+// satu kantor bisa berbagi satu IP publik (env LOGIN_MAX_ATTEMPTS / LOGIN_IP_MAX_ATTEMPTS)
+func loginMaxAttempts(scope string) int {
+	if scope == ThrottleScopeIP {
+		return envInt("LOGIN_IP_MAX_ATTEMPTS", 50)
+	}
+	return envInt("LOGIN_MAX_ATTEMPTS", 10)
+}
+
+// LoginLockoutDuration — lama penguncian pertama (env LOGIN_LOCKOUT_MINUTES, default 15)
+func LoginLockoutDuration() time.Duration {
+	return envDuration("LOGIN_LOCKOUT_MINUTES", time.Minute, 15)
+}
+
+// loginFailureWindow — hitungan gagal direset bila tidak ada kegagalan baru selama ini
+// (env LOGIN_FAILURE_WINDOW_MINUTES, default 60)
+func loginFailureWindow() time.Duration {
+	return envDuration("LOGIN_FAILURE_WINDOW_MINUTES", time.Minute, 60)
+}
+
+func normalizeThrottleKey(scope, key string) string {
+	key = strings.TrimSpace(key)
+	if scope == ThrottleScopeUsername {
+		key = strings.ToLower(key)
+	}
+	if len(key) > 255 {
+		key = key[:255]
+	}
+	return key
+}
+
+// throttleDelay — jeda setelah kegagalan ke-n: 1, 2, 4, 8 ... detik setelah percobaan gratis habis,
+// lalu penguncian yang berlipat dua setiap kali batas kembali terlampaui
+func throttleDelay(scope string, failures int) (time.Duration, bool) {
+	free, max := loginFreeAttempts(), loginMaxAttempts(scope)
+
+	if failures >= max {
+		lockout := time.Duration(float64(LoginLockoutDuration()) * math.Pow(2, float64(failures-max)))
+		if lockout <= 0 || lockout > maxLockoutDuration {
+			lockout = maxLockoutDuration
+		}
+		return lockout, true
+	}
+	if failures <= free {
+		return 0, false
+	}
+	delay := time.Second * time.Duration(math.Pow(2, float64(failures-free-1)))
+	if delay > LoginLockoutDuration() {
+		delay = LoginLockoutDuration()
+	}
+	return delay, false
+}
+
+// CheckLoginThrottle — cek apakah username atau IP sedang dalam backoff / terkunci
+func CheckLoginThrottle(username, ip string) *LoginBlock {
+	var rows []models.LoginThrottle
+	config.DB.Where(
+		"(scope = ? AND `key` = ?) OR (scope = ? AND `key` = ?)",
+		ThrottleScopeUsername, normalizeThrottleKey(ThrottleScopeUsername, username),
+		ThrottleScopeIP, normalizeThrottleKey(ThrottleScopeIP, ip),
+	).Find(&rows)
+
+	var block *LoginBlock
+	now := time.Now()
+	for _, row := range rows {
+		if row.LockedUntil == nil || !row.LockedUntil.After(now) {
+			continue
+		}
+		retry := row.LockedUntil.Sub(now)
+		if block == nil || retry > block.RetryAfter {
+			block = &LoginBlock{
+				Scope:      row.Scope,
+				Locked:     row.Failures >= loginMaxAttempts(row.Scope),
+				RetryAfter: retry,
+			}
+		}
+	}
+	return block
+}
+
+// RecordLoginFailure — tambah hitungan gagal untuk username dan IP.
+// Mengembalikan true bila kegagalan ini membuat username terkunci.
+func RecordLoginFailure(username, ip string) (bool, error) {
+	lockedNow := false
+
+	for _, entry := range []struct{ scope, key string }{
+		{ThrottleScopeUsername, username},
+		{ThrottleScopeIP, ip},
+	} {
+		key := normalizeThrottleKey(entry.scope, entry.key)
+		if key == "" {
+			continue
+		}
+
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			now := time.Now()
+
+			// pastikan baris ada, lalu kunci barisnya agar request paralel tidak saling menimpa
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&models.LoginThrottle{Scope: entry.scope, Key: key, LastFailedAt: now}).Error; err != nil {
+				return err
+			}
+
+			var row models.LoginThrottle
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("scope = ? AND `key` = ?", entry.scope, key).
+				First(&row).Error; err != nil {
+				return err
+			}
+
+			locked := row.LockedUntil != nil && row.LockedUntil.After(now)
+			if !locked && row.Failures > 0 && now.Sub(row.LastFailedAt) > loginFailureWindow() {
+				row.Failures = 0
+			}
+
+			row.Failures++
+			row.LastFailedAt = now
+
+			delay, lockout := throttleDelay(entry.scope, row.Failures)
+			if delay > 0 {
+				until := now.Add(delay)
+				row.LockedUntil = &until
+			}
+			if lockout && entry.scope == ThrottleScopeUsername && row.Failures == loginMaxAttempts(entry.scope) {
+				lockedNow = true
+			}
+
+			return tx.Save(&row).Error
+		})
+		if err != nil {
+			return lockedNow, err
+		}
+	}
+
+	return lockedNow, nil
+}
+
+// RecordLoginSuccess — login berhasil menghapus hitungan gagal username.
+// Hitungan per IP tidak direset agar satu akun valid tidak bisa dipakai menghapus jejak tebakan.
+func RecordLoginSuccess(username string) {
+	config.DB.Where("scope = ? AND `key` = ?", ThrottleScopeUsername, normalizeThrottleKey(ThrottleScopeUsername, username)).
+		Delete(&models.LoginThrottle{})
+}
+
+// ActiveLoginThrottles — daftar username / IP yang masih punya hitungan gagal
+func ActiveLoginThrottles() ([]models.LoginThrottle, error) {
+	var rows []models.LoginThrottle
+	err := config.DB.
+		Where("locked_until > ? OR last_failed_at > ?", time.Now(), time.Now().Add(-loginFailureWindow())).
+		Order("locked_until DESC").
+		Order("last_failed_at DESC").
+		Find(&rows).Error
+	return rows, err
+}
+
+// UnlockLogin — hapus hitungan gagal (tindakan superadmin)
+func UnlockLogin(scope, key string) (int64, error) {
+	result := config.DB.Where("scope = ? AND `key` = ?", scope, normalizeThrottleKey(scope, key)).
+		Delete(&models.LoginThrottle{})
+	return result.RowsAffected, result.Error
+}
+
+// PurgeLoginThrottles — hapus baris yang sudah tidak terkunci dan di luar jendela hitungan
+func PurgeLoginThrottles() (int64, error) {
+	now := time.Now()
+	result := config.DB.
+		Where("(locked_until IS NULL OR locked_until < ?) AND last_failed_at < ?", now, now.Add(-loginFailureWindow())).
+		Delete(&models.LoginThrottle{})
+	return result.RowsAffected, result.Error
+}
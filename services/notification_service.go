@@ -316,12 +316,14 @@ func NotifyAllUsers(message, link string) {
 
 // =========================
 // Notify Admins
+// user dipilih dari izin role-nya (lihat RolesWithPermission), bukan nama role,
+// sehingga role buatan lewat API ikut menerima notifikasi
 // =========================
-func NotifyAdmins(message, link string) {
-	log.Println("[NotifyAdmins] 📢 Sending notification ONLY to admins")
+func NotifyAdmins(permission, message, link string) {
+	log.Printf("[NotifyAdmins] 📢 Sending notification to holders of %s", permission)
 
 	var users []models.User
-	if err := config.DB.Where("role IN ?", []string{"admin", "superadmin"}).Find(&users).Error; err != nil {
+	if err := config.DB.Where("role IN ?", RolesWithPermission(permission)).Find(&users).Error; err != nil {
 		log.Println("[NotifyAdmins] ❌ DB error:", err)
 		return
 	}
@@ -358,7 +360,7 @@ func NotifySpecificUser(userID, message, link string) {
 	log.Printf("[NotifySpecific] 📢 Sending notification to: %s", userID)
 
 	var user models.User
-	if err := config.DB.First(&user, userID).Error; err != nil {
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
 		log.Printf("[NotifySpecific] ❌ User not found: %s", userID)
 		return
 	}
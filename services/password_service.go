@@ -0,0 +1,291 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPasswordReused           = errors.New("password baru tidak boleh sama dengan password yang pernah dipakai sebelumnya")
+	ErrTemporaryPasswordExpired = errors.New("password sementara sudah kedaluwarsa, minta superadmin untuk reset ulang")
+)
+
+// PasswordPolicy — aturan password, dapat diatur lewat env
+type PasswordPolicy struct {
+	MinLength     int  `json:"min_length"`
+	RequireUpper  bool `json:"require_upper"`
+	RequireLower  bool `json:"require_lower"`
+	RequireDigit  bool `json:"require_digit"`
+	RequireSymbol bool `json:"require_symbol"`
+	HistoryCount  int  `json:"history_count"` // jumlah password terakhir yang tidak boleh dipakai ulang
+}
+
+// PasswordPolicyViolation — daftar aturan yang tidak terpenuhi
+type PasswordPolicyViolation struct {
+	Rules []string
+}
+
+func (v *PasswordPolicyViolation) Error() string {
+	return "Password tidak memenuhi kebijakan: " + strings.Join(v.Rules, ", ")
+}
+
+func envBool(key string, fallback bool) bool {
+	if v, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return v
+	}
+	return fallback
+}
+
+// CurrentPasswordPolicy — env PASSWORD_MIN_LENGTH, PASSWORD_REQUIRE_UPPER, PASSWORD_REQUIRE_LOWER,
+// PASSWORD_REQUIRE_DIGIT, PASSWORD_REQUIRE_SYMBOL, PASSWORD_HISTORY
+func CurrentPasswordPolicy() PasswordPolicy {
+	history := 5
+	if n, err := strconv.Atoi(os.Getenv("PASSWORD_HISTORY")); err == nil && n >= 0 {
+		history = n
+	}
+
+	return PasswordPolicy{
+		MinLength:     envInt("PASSWORD_MIN_LENGTH", 8),
+		RequireUpper:  envBool("PASSWORD_REQUIRE_UPPER", true),
+		RequireLower:  envBool("PASSWORD_REQUIRE_LOWER", true),
+		RequireDigit:  envBool("PASSWORD_REQUIRE_DIGIT", true),
+		RequireSymbol: envBool("PASSWORD_REQUIRE_SYMBOL", false),
+		HistoryCount:  history,
+	}
+}
+
+// TemporaryPasswordTTL — masa berlaku password sementara hasil reset (env TEMP_PASSWORD_TTL_HOURS, default 24)
+func TemporaryPasswordTTL() time.Duration {
+	return envDuration("TEMP_PASSWORD_TTL_HOURS", time.Hour, 24)
+}
+
+// ValidatePassword — cek panjang & jenis karakter sesuai kebijakan
+func ValidatePassword(password string) error {
+	policy := CurrentPasswordPolicy()
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	var rules []string
+	if len([]rune(password)) < policy.MinLength {
+		rules = append(rules, "minimal "+strconv.Itoa(policy.MinLength)+" karakter")
+	}
+	if len(password) > 72 {
+		// batas bcrypt
+		rules = append(rules, "maksimal 72 byte")
+	}
+	if policy.RequireUpper && !upper {
+		rules = append(rules, "mengandung huruf besar")
+	}
+	if policy.RequireLower && !lower {
+		rules = append(rules, "mengandung huruf kecil")
+	}
+	if policy.RequireDigit && !digit {
+		rules = append(rules, "mengandung angka")
+	}
+	if policy.RequireSymbol && !symbol {
+		rules = append(rules, "mengandung simbol")
+	}
+
+	if len(rules) > 0 {
+		return &PasswordPolicyViolation{Rules: rules}
+	}
+	return nil
+}
+
+func HashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hashed), err
+}
+
+// passwordReused — cocokkan dengan password saat ini dan riwayat sesuai kebijakan
+func passwordReused(tx *gorm.DB, user models.User, password string) (bool, error) {
+	policy := CurrentPasswordPolicy()
+	if policy.HistoryCount == 0 {
+		return false, nil
+	}
+
+	if user.Password != "" && bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) == nil {
+		return true, nil
+	}
+
+	var history []models.PasswordHistory
+	if err := tx.Where("user_id = ?", user.ID).
+		Order("created_at DESC").
+		Limit(policy.HistoryCount).
+		Find(&history).Error; err != nil {
+		return false, err
+	}
+	for _, h := range history {
+		if bcrypt.CompareHashAndPassword([]byte(h.PasswordHash), []byte(password)) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RecordPasswordHistory — simpan hash password dan pangkas riwayat sesuai kebijakan
+func RecordPasswordHistory(tx *gorm.DB, userID, hash string) error {
+	if err := tx.Create(&models.PasswordHistory{UserID: userID, PasswordHash: hash}).Error; err != nil {
+		return err
+	}
+
+	keep := CurrentPasswordPolicy().HistoryCount
+	if keep < 1 {
+		keep = 1
+	}
+
+	var ids []string
+	if err := tx.Model(&models.PasswordHistory{}).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) <= keep {
+		return nil
+	}
+	return tx.Where("id IN ?", ids[keep:]).Delete(&models.PasswordHistory{}).Error
+}
+
+// ChangePassword — validasi kebijakan & riwayat, simpan hash baru, dan hapus status
+// wajib ganti password. Dipakai untuk semua perubahan password oleh user sendiri.
+func ChangePassword(user models.User, newPassword string) error {
+	if err := ValidatePassword(newPassword); err != nil {
+		return err
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		reused, err := passwordReused(tx, user, newPassword)
+		if err != nil {
+			return err
+		}
+		if reused {
+			return ErrPasswordReused
+		}
+
+		hashed, err := HashPassword(newPassword)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"password":             hashed,
+			"must_change_password": false,
+			"password_expires_at":  nil,
+			"password_changed_at":  time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		return RecordPasswordHistory(tx, user.ID, hashed)
+	})
+}
+
+// ResetPasswordTemporary — buat password sementara acak yang wajib diganti saat login
+// berikutnya. Semua sesi user dicabut. Password hanya dikembalikan sekali ke pemanggil.
+func ResetPasswordTemporary(userID string) (string, time.Time, error) {
+	password, err := GenerateTemporaryPassword()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	hashed, err := HashPassword(password)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiresAt := time.Now().Add(TemporaryPasswordTTL())
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"password":             hashed,
+			"must_change_password": true,
+			"password_expires_at":  expiresAt,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.SecretToken{}).Error
+	})
+
+	return password, expiresAt, err
+}
+
+// TemporaryPasswordExpired — password sementara yang tidak segera diganti tidak bisa dipakai login
+func TemporaryPasswordExpired(user models.User) bool {
+	return user.MustChangePassword && user.PasswordExpiresAt != nil && time.Now().After(*user.PasswordExpiresAt)
+}
+
+// GenerateTemporaryPassword — 14 karakter acak yang selalu memenuhi kebijakan
+// (huruf besar, huruf kecil, angka, simbol), tanpa karakter yang mudah tertukar
+func GenerateTemporaryPassword() (string, error) {
+	classes := []string{
+		"ABCDEFGHJKLMNPQRSTUVWXYZ",
+		"abcdefghijkmnpqrstuvwxyz",
+		"23456789",
+		"!@#$%*?",
+	}
+	all := strings.Join(classes, "")
+
+	length := CurrentPasswordPolicy().MinLength
+	if length < 14 {
+		length = 14
+	}
+
+	pick := func(set string) (byte, error) {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(set))))
+		if err != nil {
+			return 0, err
+		}
+		return set[n.Int64()], nil
+	}
+
+	password := make([]byte, 0, length)
+	for _, set := range classes {
+		ch, err := pick(set)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, ch)
+	}
+	for len(password) < length {
+		ch, err := pick(all)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, ch)
+	}
+
+	// acak posisi agar karakter wajib tidak selalu di depan
+	for i := len(password) - 1; i > 0; i-- {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		j := n.Int64()
+		password[i], password[j] = password[j], password[i]
+	}
+
+	return string(password), nil
+}
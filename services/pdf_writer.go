@@ -0,0 +1,280 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// =========================
+// Penulis PDF minimal tanpa dependensi luar — teks Helvetica (WinAnsi),
+// garis, kotak dan satu gambar JPEG; cukup untuk laporan tabel yang dicetak
+// =========================
+
+// Ukuran kertas A4 dalam poin
+const (
+	pdfA4Width  = 595.28
+	pdfA4Height = 841.89
+)
+
+// Lebar glyph Helvetica / Helvetica-Bold untuk karakter 32..126 (per 1000 unit)
+var (
+	pdfHelveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	pdfHelveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// pdfEncode — UTF-8 ke WinAnsi (Latin-1); karakter di luar jangkauan menjadi '?'
+func pdfEncode(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			out = append(out, ' ')
+		case r == '–' || r == '—':
+			out = append(out, '-')
+		case r == '‘' || r == '’':
+			out = append(out, '\'')
+		case r == '“' || r == '”':
+			out = append(out, '"')
+		case r >= 32 && r < 127, r >= 160 && r < 256:
+			out = append(out, byte(r))
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
+
+// pdfTextWidth — lebar teks dalam poin
+func pdfTextWidth(s string, size float64, bold bool) float64 {
+	widths := &pdfHelveticaWidths
+	if bold {
+		widths = &pdfHelveticaBoldWidths
+	}
+
+	total := 0
+	for _, c := range pdfEncode(s) {
+		if c >= 32 && c < 127 {
+			total += widths[c-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// pdfWrap — pecah teks menjadi baris yang muat dalam width; kata yang terlalu panjang dipotong
+func pdfWrap(s string, width, size float64, bold bool) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if pdfTextWidth(candidate, size, bold) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			for pdfTextWidth(word, size, bold) > width {
+				cut := len([]rune(word))
+				runes := []rune(word)
+				for cut > 1 && pdfTextWidth(string(runes[:cut]), size, bold) > width {
+					cut--
+				}
+				lines = append(lines, string(runes[:cut]))
+				word = string(runes[cut:])
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// pdfString — teks literal PDF (WinAnsi) dengan escape untuk ( ) dan \
+func pdfString(s string) string {
+	var escaped bytes.Buffer
+	for _, c := range pdfEncode(s) {
+		if c == '(' || c == ')' || c == '\\' {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteByte(c)
+	}
+	return "(" + escaped.String() + ")"
+}
+
+func pdfNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// pdfPage — isi content stream satu halaman
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+// Text — tulis teks dengan baseline di (x, y); koordinat PDF dihitung dari kiri bawah
+func (p *pdfPage) Text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s %s Td %s Tj ET\n", font, pdfNumber(size), pdfNumber(x), pdfNumber(y), pdfString(s))
+}
+
+// TextCenter — teks rata tengah terhadap x
+func (p *pdfPage) TextCenter(x, y, size float64, bold bool, s string) {
+	p.Text(x-pdfTextWidth(s, size, bold)/2, y, size, bold, s)
+}
+
+// TextRight — teks rata kanan terhadap x
+func (p *pdfPage) TextRight(x, y, size float64, bold bool, s string) {
+	p.Text(x-pdfTextWidth(s, size, bold), y, size, bold, s)
+}
+
+func (p *pdfPage) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n", pdfNumber(width), pdfNumber(x1), pdfNumber(y1), pdfNumber(x2), pdfNumber(y2))
+}
+
+// Rect — kotak bergaris; fillGray 0..1 untuk isi abu-abu, < 0 tanpa isi
+func (p *pdfPage) Rect(x, y, w, h, fillGray float64) {
+	if fillGray >= 0 {
+		fmt.Fprintf(&p.content, "q 0.5 w %s g %s %s %s %s re B Q\n", pdfNumber(fillGray), pdfNumber(x), pdfNumber(y), pdfNumber(w), pdfNumber(h))
+		return
+	}
+	fmt.Fprintf(&p.content, "0.5 w %s %s %s %s re S\n", pdfNumber(x), pdfNumber(y), pdfNumber(w), pdfNumber(h))
+}
+
+// Image — gambar JPEG dokumen (lihat pdfDocument.SetImage) pada kotak (x, y, w, h)
+func (p *pdfPage) Image(x, y, w, h float64) {
+	fmt.Fprintf(&p.content, "q %s 0 0 %s %s %s cm /Im1 Do Q\n", pdfNumber(w), pdfNumber(h), pdfNumber(x), pdfNumber(y))
+}
+
+// pdfDocument — kumpulan halaman berukuran sama
+type pdfDocument struct {
+	Width, Height float64
+	Title         string
+	pages         []*pdfPage
+	image         []byte
+	imageW        int
+	imageH        int
+	imageGray     bool
+}
+
+func newPDFDocument(width, height float64) *pdfDocument {
+	return &pdfDocument{Width: width, Height: height}
+}
+
+func (d *pdfDocument) AddPage() *pdfPage {
+	page := &pdfPage{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+func (d *pdfDocument) Pages() []*pdfPage {
+	return d.pages
+}
+
+// SetImage — pasang satu gambar JPEG (mis. logo kop) yang bisa dipakai di semua halaman.
+// Mengembalikan rasio lebar/tinggi gambar.
+func (d *pdfDocument) SetImage(data []byte) (float64, error) {
+	config, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	if config.ColorModel == color.CMYKModel {
+		return 0, errors.New("JPEG CMYK tidak didukung, gunakan RGB atau grayscale")
+	}
+	d.image = data
+	d.imageW, d.imageH = config.Width, config.Height
+	d.imageGray = config.ColorModel == color.GrayModel
+	return float64(config.Width) / float64(config.Height), nil
+}
+
+// Write — susun objek PDF beserta tabel xref
+func (d *pdfDocument) Write(w io.Writer) error {
+	var out bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	stream := func(dict string, data []byte) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n<< %s /Length %d >>\nstream\n", len(offsets), dict, len(data))
+		out.Write(data)
+		out.WriteString("\nendstream\nendobj\n")
+	}
+
+	out.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	// 1 katalog, 2 pohon halaman, 3-4 font, 5 info, 6 gambar (opsional), lalu halaman + isinya
+	firstPage := 6
+	if d.image != nil {
+		firstPage = 7
+	}
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = strconv.Itoa(firstPage+i*2) + " 0 R"
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	object(fmt.Sprintf("<< /Title %s /Producer (Dinsos Kubu Raya) >>", pdfString(d.Title)))
+
+	resources := "/Font << /F1 3 0 R /F2 4 0 R >>"
+	if d.image != nil {
+		colorSpace := "/DeviceRGB"
+		if d.imageGray {
+			colorSpace = "/DeviceGray"
+		}
+		stream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace %s /BitsPerComponent 8 /Filter /DCTDecode",
+			d.imageW, d.imageH, colorSpace), d.image)
+		resources += " /XObject << /Im1 6 0 R >>"
+	}
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << %s >> /Contents %d 0 R >>",
+			pdfNumber(d.Width), pdfNumber(d.Height), resources, firstPage+i*2+1))
+		stream("", page.content.Bytes())
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
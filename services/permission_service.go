@@ -0,0 +1,379 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// =========================
+// Katalog izin
+// =========================
+
+const (
+	RoleSuperadmin = "superadmin"
+	RoleAdmin      = "admin"
+	RoleStaff      = "staff"
+)
+
+const (
+	PermDocumentView     = "document.view"
+	PermDocumentViewAll  = "document.view_all_units"
+	PermDocumentCreate   = "document.create"
+	PermDocumentUpdate   = "document.update"
+	PermDocumentDelete   = "document.delete"
+	PermDocumentRestore  = "document.restore"
+	PermDocumentDownload = "document.download"
+	PermDocumentShare    = "document.share"
+	PermDocumentExport   = "document.export"
+
+	PermDispositionCreate = "disposition.create"
+
+	PermStaffDocViewAll   = "staffdoc.view_all_units"
+	PermStaffDocManageAll = "staffdoc.manage_all"
+	PermStaffDocReview    = "staffdoc.review"
+
+	PermSubmissionManage = "submission.manage"
+
+	PermCommentModerate = "comment.moderate"
+
+	PermEmployeeManage = "employee.manage"
+
+	PermUnitManage = "unit.manage"
+
+	PermUserView   = "user.view"
+	PermUserManage = "user.manage"
+	PermRoleManage = "role.manage"
+
+	PermActivityLogView = "activity_log.view"
+)
+
+// PermissionDef — izin beserta role yang mendapatkannya secara default
+type PermissionDef struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	DefaultRoles []string `json:"default_roles"`
+}
+
+// PermissionCatalog — default disusun agar perilaku role lama tetap sama
+var PermissionCatalog = []PermissionDef{
+	{PermDocumentView, "Melihat daftar & detail surat/dokumen", []string{RoleAdmin, RoleStaff}},
+	{PermDocumentViewAll, "Melihat surat milik semua unit", []string{RoleAdmin}},
+	{PermDocumentCreate, "Mengunggah surat/dokumen baru", []string{RoleAdmin}},
+	{PermDocumentUpdate, "Mengubah surat/dokumen, memulihkan versi, ekstraksi ulang", []string{RoleAdmin}},
+	{PermDocumentDelete, "Memindahkan surat/dokumen ke trash", []string{RoleAdmin}},
+	{PermDocumentRestore, "Melihat trash surat/dokumen dan memulihkannya", []string{RoleAdmin}},
+	{PermDocumentDownload, "Mengunduh file surat/dokumen", []string{RoleAdmin}},
+	{PermDocumentShare, "Membagikan surat ke user lain dan membuat tautan publik", []string{RoleAdmin}},
+	{PermDocumentExport, "Mengekspor surat ke arsip ZIP dan mencetak buku agenda", []string{RoleAdmin}},
+	{PermDispositionCreate, "Membuat disposisi surat", []string{RoleAdmin}},
+	{PermStaffDocViewAll, "Melihat dokumen staf milik semua unit", []string{RoleAdmin}},
+	{PermStaffDocManageAll, "Mengubah, menghapus & memulihkan dokumen staf milik user lain", []string{RoleAdmin}},
+	{PermStaffDocReview, "Mereview dokumen staf: menyetujui, menolak atau meminta revisi", []string{RoleAdmin}},
+	{PermSubmissionManage, "Membuat & memantau permintaan pengumpulan dokumen staf", []string{RoleAdmin}},
+	{PermCommentModerate, "Menghapus komentar milik user lain", []string{RoleAdmin}},
+	{PermEmployeeManage, "Mengelola data pegawai (NIP) dan berkas kepegawaiannya", []string{RoleAdmin}},
+	{PermUnitManage, "Mengelola unit organisasi dan anggotanya", nil},
+	{PermUserView, "Melihat daftar user", []string{RoleAdmin}},
+	{PermUserManage, "Membuat, mengubah, menghapus user, reset password, sesi & 2FA", nil},
+	{PermRoleManage, "Mengelola role, izin dan kebijakan keamanan", nil},
+	{PermActivityLogView, "Melihat log aktivitas", []string{RoleAdmin}},
+}
+
+// retiredPermissions — izin lama yang dicabut dari semua role saat start.
+// staffdoc.view_all dulu diberikan ke staff; kini akses dibatasi per unit.
+var retiredPermissions = []string{"staffdoc.view_all"}
+
+var defaultRoles = []models.Role{
+	{Name: RoleSuperadmin, Description: "Akses penuh ke seluruh sistem", IsSystem: true},
+	{Name: RoleAdmin, Description: "Pengelola arsip surat & dokumen", IsSystem: true},
+	{Name: RoleStaff, Description: "Pegawai", IsSystem: true},
+}
+
+var (
+	ErrRoleNotFound      = errors.New("role tidak ditemukan")
+	ErrRoleExists        = errors.New("role sudah ada")
+	ErrRoleSystem        = errors.New("role sistem tidak dapat dihapus")
+	ErrRoleInUse         = errors.New("role masih dipakai oleh user")
+	ErrRoleNameInvalid   = errors.New("nama role hanya boleh huruf kecil, angka dan garis bawah (2-50 karakter)")
+	ErrPermissionUnknown = errors.New("izin tidak dikenal")
+	ErrSuperadminFixed   = errors.New("izin role superadmin tidak dapat diubah")
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+func ValidPermission(name string) bool {
+	for _, p := range PermissionCatalog {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func allPermissionNames() []string {
+	names := make([]string, 0, len(PermissionCatalog))
+	for _, p := range PermissionCatalog {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// SeedRoles — buat role default dan berikan izin yang baru muncul di katalog ke role
+// default-nya. Izin yang sudah pernah dikenal tidak disentuh, sehingga perubahan
+// superadmin lewat API tetap bertahan setelah restart.
+func SeedRoles() error {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, role := range defaultRoles {
+			role := role
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&role).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("permission IN ?", retiredPermissions).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("name IN ?", retiredPermissions).Delete(&models.Permission{}).Error; err != nil {
+			return err
+		}
+
+		var known []string
+		if err := tx.Model(&models.Permission{}).Pluck("name", &known).Error; err != nil {
+			return err
+		}
+		seen := make(map[string]bool, len(known))
+		for _, name := range known {
+			seen[name] = true
+		}
+
+		for _, p := range PermissionCatalog {
+			if seen[p.Name] {
+				continue
+			}
+			if err := tx.Create(&models.Permission{Name: p.Name, Description: p.Description}).Error; err != nil {
+				return err
+			}
+			for _, role := range p.DefaultRoles {
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+					Create(&models.RolePermission{RoleName: role, Permission: p.Name}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+
+	InvalidatePermissionCache()
+	return err
+}
+
+// =========================
+// Cache izin per role
+// =========================
+
+// cache berumur pendek agar perubahan dari instance lain tetap terbaca
+const permissionCacheTTL = time.Minute
+
+type cachedPermissions struct {
+	set      map[string]bool
+	loadedAt time.Time
+}
+
+var (
+	permissionCache   = map[string]cachedPermissions{}
+	permissionCacheMu sync.RWMutex
+)
+
+func InvalidatePermissionCache() {
+	permissionCacheMu.Lock()
+	permissionCache = map[string]cachedPermissions{}
+	permissionCacheMu.Unlock()
+}
+
+// RolePermissions — himpunan izin sebuah role. Superadmin selalu memiliki semua izin.
+func RolePermissions(role string) map[string]bool {
+	if role == RoleSuperadmin {
+		set := make(map[string]bool, len(PermissionCatalog))
+		for _, name := range allPermissionNames() {
+			set[name] = true
+		}
+		return set
+	}
+
+	permissionCacheMu.RLock()
+	cached, ok := permissionCache[role]
+	permissionCacheMu.RUnlock()
+	if ok && time.Since(cached.loadedAt) < permissionCacheTTL {
+		return cached.set
+	}
+
+	var names []string
+	if err := config.DB.Model(&models.RolePermission{}).
+		Where("role_name = ?", role).
+		Pluck("permission", &names).Error; err != nil {
+		return map[string]bool{}
+	}
+
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+
+	permissionCacheMu.Lock()
+	permissionCache[role] = cachedPermissions{set: set, loadedAt: time.Now()}
+	permissionCacheMu.Unlock()
+
+	return set
+}
+
+// RolesWithPermission — nama role yang memiliki izin tertentu, termasuk superadmin
+// dan role buatan lewat API
+func RolesWithPermission(permission string) []string {
+	roles := []string{RoleSuperadmin}
+	var granted []string
+	config.DB.Model(&models.RolePermission{}).Where("permission = ?", permission).Pluck("role_name", &granted)
+	return append(roles, granted...)
+}
+
+// HasPermission — apakah role user memiliki izin tertentu
+func HasPermission(user models.User, permission string) bool {
+	return RolePermissions(user.Role)[permission]
+}
+
+// UserPermissions — daftar izin user (terurut) untuk dikirim ke frontend
+func UserPermissions(user models.User) []string {
+	set := RolePermissions(user.Role)
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// =========================
+// Kelola role
+// =========================
+
+func RoleExists(name string) bool {
+	var count int64
+	config.DB.Model(&models.Role{}).Where("name = ?", name).Count(&count)
+	return count > 0
+}
+
+func validatePermissions(permissions []string) error {
+	for _, p := range permissions {
+		if !ValidPermission(p) {
+			return errors.New(ErrPermissionUnknown.Error() + ": " + p)
+		}
+	}
+	return nil
+}
+
+func replaceRolePermissions(tx *gorm.DB, role string, permissions []string) error {
+	if err := tx.Where("role_name = ?", role).Delete(&models.RolePermission{}).Error; err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, p := range permissions {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		if err := tx.Create(&models.RolePermission{RoleName: role, Permission: p}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func CreateRole(name, description string, permissions []string) (models.Role, error) {
+	role := models.Role{Name: name, Description: description}
+
+	if !roleNamePattern.MatchString(name) {
+		return role, ErrRoleNameInvalid
+	}
+	if err := validatePermissions(permissions); err != nil {
+		return role, err
+	}
+	if RoleExists(name) {
+		return role, ErrRoleExists
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&role).Error; err != nil {
+			return err
+		}
+		return replaceRolePermissions(tx, name, permissions)
+	})
+
+	InvalidatePermissionCache()
+	return role, err
+}
+
+// UpdateRole — ubah deskripsi dan/atau daftar izin. permissions nil = izin tidak diubah.
+func UpdateRole(name string, description *string, permissions []string) (models.Role, error) {
+	var role models.Role
+	if err := config.DB.First(&role, "name = ?", name).Error; err != nil {
+		return role, ErrRoleNotFound
+	}
+	if permissions != nil {
+		if role.Name == RoleSuperadmin {
+			return role, ErrSuperadminFixed
+		}
+		if err := validatePermissions(permissions); err != nil {
+			return role, err
+		}
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if description != nil {
+			role.Description = *description
+			if err := tx.Model(&role).Update("description", role.Description).Error; err != nil {
+				return err
+			}
+		}
+		if permissions != nil {
+			return replaceRolePermissions(tx, role.Name, permissions)
+		}
+		return nil
+	})
+
+	InvalidatePermissionCache()
+	return role, err
+}
+
+func DeleteRole(name string) error {
+	var role models.Role
+	if err := config.DB.First(&role, "name = ?", name).Error; err != nil {
+		return ErrRoleNotFound
+	}
+	if role.IsSystem {
+		return ErrRoleSystem
+	}
+
+	var users int64
+	config.DB.Model(&models.User{}).Where("role = ?", name).Count(&users)
+	if users > 0 {
+		return ErrRoleInUse
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role = ?", name).Delete(&models.TwoFactorPolicy{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&role).Error
+	})
+
+	InvalidatePermissionCache()
+	return err
+}
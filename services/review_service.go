@@ -0,0 +1,173 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+
+	"gorm.io/gorm"
+)
+
+// Status review dokumen staf
+const (
+	ReviewSubmitted         = "submitted"
+	ReviewUnderReview       = "under_review"
+	ReviewRevisionRequested = "revision_requested"
+	ReviewApproved          = "approved"
+	ReviewRejected          = "rejected"
+)
+
+var (
+	ErrReviewStatusInvalid   = errors.New("status review harus under_review, revision_requested, approved atau rejected")
+	ErrReviewTransition      = errors.New("perpindahan status review tidak diizinkan")
+	ErrReviewCommentRequired = errors.New("komentar wajib diisi untuk meminta revisi atau menolak")
+)
+
+// reviewTransitions — status yang boleh dituju peninjau dari setiap status.
+// Dari revision_requested dokumen baru kembali ke review setelah revisi diunggah.
+var reviewTransitions = map[string][]string{
+	ReviewSubmitted:         {ReviewUnderReview, ReviewRevisionRequested, ReviewApproved, ReviewRejected},
+	ReviewUnderReview:       {ReviewRevisionRequested, ReviewApproved, ReviewRejected},
+	ReviewRevisionRequested: {},
+	ReviewApproved:          {ReviewUnderReview},
+	ReviewRejected:          {ReviewUnderReview},
+}
+
+var reviewStatusLabels = map[string]string{
+	ReviewSubmitted:         "menunggu review",
+	ReviewUnderReview:       "sedang direview",
+	ReviewRevisionRequested: "perlu revisi",
+	ReviewApproved:          "disetujui",
+	ReviewRejected:          "ditolak",
+}
+
+// ValidReviewStatus — untuk validasi filter review_status
+func ValidReviewStatus(status string) bool {
+	_, ok := reviewTransitions[status]
+	return ok
+}
+
+func reviewAllowed(from, to string) bool {
+	for _, status := range reviewTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+func documentStaffLink(id string) string {
+	return "/document_staff/" + id
+}
+
+// ReviewDocumentStaff — pindahkan status review, simpan komentar peninjau, lalu kabari pengunggah.
+// Perubahan hanya berlaku bila status belum diubah peninjau lain sejak dokumen dibaca.
+func ReviewDocumentStaff(document models.DocumentStaff, reviewer models.User, status, comment string) (models.DocumentStaffReview, error) {
+	comment = strings.TrimSpace(comment)
+	review := models.DocumentStaffReview{
+		DocumentStaffID: document.ID,
+		FromStatus:      document.ReviewStatus,
+		ToStatus:        status,
+		Comment:         comment,
+		ReviewerID:      &reviewer.ID,
+	}
+
+	if !ValidReviewStatus(status) || status == ReviewSubmitted {
+		return review, ErrReviewStatusInvalid
+	}
+	if !reviewAllowed(document.ReviewStatus, status) {
+		return review, ErrReviewTransition
+	}
+	if comment == "" && (status == ReviewRevisionRequested || status == ReviewRejected) {
+		return review, ErrReviewCommentRequired
+	}
+
+	now := time.Now()
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// UpdateColumns: lewati hook BeforeSave DocumentStaff
+		result := tx.Model(&models.DocumentStaff{}).
+			Where("id = ? AND review_status = ?", document.ID, document.ReviewStatus).
+			UpdateColumns(map[string]interface{}{
+				"review_status":  status,
+				"reviewed_by_id": reviewer.ID,
+				"reviewed_at":    now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrReviewTransition
+		}
+		return tx.Create(&review).Error
+	})
+	if err != nil {
+		return review, err
+	}
+
+	message := "Dokumen " + document.Subject + " " + reviewStatusLabels[status] + " oleh " + reviewer.Name
+	if comment != "" {
+		message += ": " + comment
+	}
+	NotifySpecificUser(document.UserID, message, documentStaffLink(document.ID))
+
+	return review, nil
+}
+
+// ResubmitForReview — file dokumen berganti (revisi diunggah atau versi lama dipulihkan):
+// dokumen yang sudah diputuskan kembali ke under_review. Dipanggil di dalam transaksi
+// perubahan file; mengembalikan true bila status berubah (lihat NotifyRevisionUploaded).
+func ResubmitForReview(tx *gorm.DB, document models.DocumentStaff, actor models.User, note string) (bool, error) {
+	if document.EmployeeID != "" {
+		return false, nil
+	}
+
+	switch document.ReviewStatus {
+	case ReviewRevisionRequested, ReviewApproved, ReviewRejected:
+	default:
+		return false, nil
+	}
+
+	note = strings.TrimSpace(note)
+	if note == "" {
+		note = "Revisi diunggah"
+	}
+
+	result := tx.Model(&models.DocumentStaff{}).
+		Where("id = ? AND review_status = ?", document.ID, document.ReviewStatus).
+		UpdateColumn("review_status", ReviewUnderReview)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	err := tx.Create(&models.DocumentStaffReview{
+		DocumentStaffID: document.ID,
+		FromStatus:      document.ReviewStatus,
+		ToStatus:        ReviewUnderReview,
+		Comment:         note,
+		ReviewerID:      &actor.ID,
+	}).Error
+	return err == nil, err
+}
+
+// NotifyRevisionUploaded — kabari peninjau terakhir bahwa dokumen kembali menunggu review
+func NotifyRevisionUploaded(document models.DocumentStaff, actor models.User) {
+	message := "Revisi dokumen " + document.Subject + " diunggah oleh " + actor.Name
+	if document.ReviewedByID == nil || *document.ReviewedByID == actor.ID {
+		NotifyAdmins(PermStaffDocReview, message, documentStaffLink(document.ID))
+		return
+	}
+	NotifySpecificUser(*document.ReviewedByID, message, documentStaffLink(document.ID))
+}
+
+// DocumentStaffReviews — riwayat review satu dokumen, terlama lebih dulu
+func DocumentStaffReviews(documentID string) ([]models.DocumentStaffReview, error) {
+	var reviews []models.DocumentStaffReview
+	err := config.DB.Preload("Reviewer", selectSearchUser).
+		Where("document_staff_id = ?", documentID).
+		Order("created_at ASC, id ASC").
+		Find(&reviews).Error
+	return reviews, err
+}
@@ -0,0 +1,412 @@
+package services
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+
+	"gorm.io/gorm"
+)
+
+// panjang token minimum default InnoDB FULLTEXT (innodb_ft_min_token_size)
+const minFullTextTokenLength = 3
+
+// kolom MATCH harus sama dengan definisi indeks di config.FullTextIndexes
+const (
+	documentMatchColumns      = "documents.subject, documents.sender, documents.file_name, documents.letter_number, documents.recipient, documents.extracted_text"
+	documentStaffMatchColumns = "document_staffs.subject, document_staffs.file_name, document_staffs.extracted_text"
+)
+
+var (
+	phrasePattern    = regexp.MustCompile(`"([^"]+)"`)
+	booleanOperators = strings.NewReplacer("+", " ", "-", " ", ">", " ", "<", " ", "(", " ", ")", " ", "~", " ", "*", " ", "\"", " ", "@", " ")
+)
+
+// SearchQuery — hasil parsing kata kunci pencarian
+type SearchQuery struct {
+	Terms   []string // kata biasa, dicocokkan sebagai prefix (kata*)
+	Phrases []string // frasa dalam tanda kutip
+	Short   []string // kata terlalu pendek untuk FULLTEXT, dicocokkan dengan LIKE
+	Exclude []string // kata dengan awalan '-', tidak boleh muncul
+}
+
+// SearchParams — filter pencarian
+type SearchParams struct {
+	Query              SearchQuery
+	LetterType         string
+	UploaderID         string
+	ClassificationCode string
+	DateFrom           *time.Time
+	DateTo             *time.Time
+	Limit              int
+	Offset             int
+
+	// cakupan unit user yang mencari (lihat DocumentAccess / DocumentStaffAccess)
+	DocumentAccess      UnitAccess
+	DocumentStaffAccess UnitAccess
+}
+
+// SearchHit — satu hasil pencarian beserta skor relevansi dan cuplikan
+type SearchHit struct {
+	Type       string            `json:"type"`
+	ID         string            `json:"id"`
+	Relevance  float64           `json:"relevance"`
+	Highlights map[string]string `json:"highlights"`
+	Document   interface{}       `json:"document"`
+}
+
+// ParseSearchQuery — pecah input menjadi frasa ("...") dan kata-kata terpisah
+func ParseSearchQuery(raw string) SearchQuery {
+	var q SearchQuery
+
+	for _, m := range phrasePattern.FindAllStringSubmatch(raw, -1) {
+		phrase := strings.Join(strings.Fields(booleanOperators.Replace(m[1])), " ")
+		if phrase != "" {
+			q.Phrases = append(q.Phrases, phrase)
+		}
+	}
+
+	rest := phrasePattern.ReplaceAllString(raw, " ")
+	seen := map[string]bool{}
+	for _, token := range strings.Fields(rest) {
+		exclude := strings.HasPrefix(token, "-")
+
+		for _, word := range strings.Fields(booleanOperators.Replace(token)) {
+			word = strings.ToLower(word)
+			if seen[word] {
+				continue
+			}
+			seen[word] = true
+
+			switch {
+			case exclude && utf8.RuneCountInString(word) >= minFullTextTokenLength:
+				q.Exclude = append(q.Exclude, word)
+			case exclude:
+				// kata pendek tidak bisa dikecualikan lewat FULLTEXT, abaikan
+			case utf8.RuneCountInString(word) < minFullTextTokenLength:
+				q.Short = append(q.Short, word)
+			default:
+				q.Terms = append(q.Terms, word)
+			}
+		}
+	}
+
+	return q
+}
+
+func (q SearchQuery) IsEmpty() bool {
+	return len(q.Terms) == 0 && len(q.Phrases) == 0 && len(q.Short) == 0
+}
+
+// BooleanExpr — ekspresi BOOLEAN MODE: semua kata & frasa wajib ada (urutan bebas),
+// kata berawalan '-' tidak boleh ada
+func (q SearchQuery) BooleanExpr() string {
+	parts := make([]string, 0, len(q.Terms)+len(q.Phrases))
+	for _, t := range q.Terms {
+		parts = append(parts, "+"+t+"*")
+	}
+	for _, p := range q.Phrases {
+		parts = append(parts, `+"`+p+`"`)
+	}
+	for _, e := range q.Exclude {
+		parts = append(parts, "-"+e)
+	}
+	return strings.Join(parts, " ")
+}
+
+// NaturalExpr — ekspresi NATURAL LANGUAGE MODE untuk skor relevansi
+func (q SearchQuery) NaturalExpr() string {
+	return strings.Join(append(append([]string{}, q.Phrases...), q.Terms...), " ")
+}
+
+func (q SearchQuery) hasFullText() bool {
+	return len(q.Terms) > 0 || len(q.Phrases) > 0
+}
+
+// applyFullText — filter WHERE MATCH (BOOLEAN MODE) dan LIKE untuk kata pendek
+func applyFullText(query *gorm.DB, matchColumns string, likeColumns []string, q SearchQuery) *gorm.DB {
+	if q.hasFullText() {
+		query = query.Where("MATCH("+matchColumns+") AGAINST(? IN BOOLEAN MODE)", q.BooleanExpr())
+	}
+
+	// kata pendek tidak terindeks FULLTEXT, cocokkan di salah satu kolom
+	for _, word := range q.Short {
+		like := "%" + word + "%"
+		conditions := make([]string, len(likeColumns))
+		args := make([]interface{}, len(likeColumns))
+		for i, col := range likeColumns {
+			conditions[i] = col + " LIKE ?"
+			args[i] = like
+		}
+		query = query.Where(strings.Join(conditions, " OR "), args...)
+	}
+
+	return query
+}
+
+type rankedID struct {
+	ID        string
+	Relevance float64
+}
+
+// rankIDs — ambil ID + skor relevansi (NATURAL LANGUAGE MODE) untuk satu halaman hasil
+func rankIDs(query *gorm.DB, table, matchColumns string, q SearchQuery, limit, offset int) ([]rankedID, error) {
+	if q.hasFullText() {
+		query = query.Select(table+".id AS id, MATCH("+matchColumns+") AGAINST(? IN NATURAL LANGUAGE MODE) AS relevance", q.NaturalExpr())
+	} else {
+		query = query.Select(table + ".id AS id, 0 AS relevance")
+	}
+
+	var ranked []rankedID
+	err := query.
+		Order("relevance DESC").
+		Order(table + ".created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&ranked).Error
+	return ranked, err
+}
+
+func selectSearchUser(db *gorm.DB) *gorm.DB {
+	return db.Select("id", "name", "username", "role", "photo_url")
+}
+
+// SearchDocuments — cari surat masuk/keluar
+func SearchDocuments(p SearchParams) ([]SearchHit, int64, error) {
+	query := config.DB.Model(&models.Document{})
+	query = applyFullText(query, documentMatchColumns,
+		[]string{"documents.subject", "documents.sender", "documents.file_name", "documents.letter_number", "documents.recipient", "documents.extracted_text"},
+		p.Query)
+
+	query = p.DocumentAccess.ScopeDocuments(query)
+
+	if p.LetterType != "" && p.LetterType != "all" {
+		query = query.Where("documents.letter_type = ?", p.LetterType)
+	}
+	if p.UploaderID != "" {
+		query = query.Where("documents.user_id = ?", p.UploaderID)
+	}
+	if p.ClassificationCode != "" {
+		query = query.Where("documents.classification_code = ? OR documents.classification_code LIKE ?", p.ClassificationCode, p.ClassificationCode+".%")
+	}
+	if p.DateFrom != nil {
+		query = query.Where("documents.created_at >= ?", *p.DateFrom)
+	}
+	if p.DateTo != nil {
+		query = query.Where("documents.created_at < ?", p.DateTo.AddDate(0, 0, 1))
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	ranked, err := rankIDs(query.Session(&gorm.Session{}), "documents", documentMatchColumns, p.Query, p.Limit, p.Offset)
+	if err != nil || len(ranked) == 0 {
+		return []SearchHit{}, total, err
+	}
+
+	ids := make([]string, len(ranked))
+	for i, r := range ranked {
+		ids[i] = r.ID
+	}
+
+	var documents []models.Document
+	if err := config.DB.Preload("User", selectSearchUser).Where("id IN ?", ids).Find(&documents).Error; err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[string]models.Document, len(documents))
+	for _, d := range documents {
+		byID[d.ID] = d
+	}
+
+	hits := make([]SearchHit, 0, len(ranked))
+	for _, r := range ranked {
+		d, ok := byID[r.ID]
+		if !ok {
+			continue
+		}
+		hits = append(hits, SearchHit{
+			Type:      "document",
+			ID:        d.ID,
+			Relevance: r.Relevance,
+			Highlights: buildHighlights(p.Query, map[string]string{
+				"subject":       d.Subject,
+				"sender":        d.Sender,
+				"file_name":     d.FileName,
+				"letter_number": d.LetterNumber,
+				"recipient":     d.Recipient,
+				"content":       d.ExtractedText,
+			}),
+			Document: d,
+		})
+	}
+
+	return hits, total, nil
+}
+
+// SearchDocumentStaffs — cari dokumen staff (filter jenis surat & klasifikasi tidak berlaku)
+func SearchDocumentStaffs(p SearchParams) ([]SearchHit, int64, error) {
+	query := config.DB.Model(&models.DocumentStaff{})
+	query = applyFullText(query, documentStaffMatchColumns,
+		[]string{"document_staffs.subject", "document_staffs.file_name", "document_staffs.extracted_text"},
+		p.Query)
+
+	query = p.DocumentStaffAccess.ScopeDocumentStaffs(query)
+
+	if p.UploaderID != "" {
+		query = query.Where("document_staffs.user_id = ?", p.UploaderID)
+	}
+	if p.DateFrom != nil {
+		query = query.Where("document_staffs.created_at >= ?", *p.DateFrom)
+	}
+	if p.DateTo != nil {
+		query = query.Where("document_staffs.created_at < ?", p.DateTo.AddDate(0, 0, 1))
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	ranked, err := rankIDs(query.Session(&gorm.Session{}), "document_staffs", documentStaffMatchColumns, p.Query, p.Limit, p.Offset)
+	if err != nil || len(ranked) == 0 {
+		return []SearchHit{}, total, err
+	}
+
+	ids := make([]string, len(ranked))
+	for i, r := range ranked {
+		ids[i] = r.ID
+	}
+
+	var documents []models.DocumentStaff
+	if err := config.DB.Preload("User", selectSearchUser).Where("id IN ?", ids).Find(&documents).Error; err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[string]models.DocumentStaff, len(documents))
+	for _, d := range documents {
+		byID[d.ID] = d
+	}
+
+	hits := make([]SearchHit, 0, len(ranked))
+	for _, r := range ranked {
+		d, ok := byID[r.ID]
+		if !ok {
+			continue
+		}
+		hits = append(hits, SearchHit{
+			Type:      "document_staff",
+			ID:        d.ID,
+			Relevance: r.Relevance,
+			Highlights: buildHighlights(p.Query, map[string]string{
+				"subject":   d.Subject,
+				"file_name": d.FileName,
+				"content":   d.ExtractedText,
+			}),
+			Document: d,
+		})
+	}
+
+	return hits, total, nil
+}
+
+// MergeSearchHits — gabungkan hasil dari beberapa sumber berdasarkan relevansi
+func MergeSearchHits(lists ...[]SearchHit) []SearchHit {
+	var merged []SearchHit
+	for _, l := range lists {
+		merged = append(merged, l...)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Relevance > merged[j].Relevance
+	})
+	return merged
+}
+
+// =========================
+// Highlight / snippet
+// =========================
+
+const snippetRadius = 60
+
+func (q SearchQuery) highlightPattern() *regexp.Regexp {
+	words := make([]string, 0, len(q.Terms)+len(q.Phrases)+len(q.Short))
+	for _, p := range q.Phrases {
+		words = append(words, regexp.QuoteMeta(p))
+	}
+	for _, t := range q.Terms {
+		words = append(words, regexp.QuoteMeta(t))
+	}
+	for _, s := range q.Short {
+		words = append(words, regexp.QuoteMeta(s))
+	}
+	if len(words) == 0 {
+		return nil
+	}
+	return regexp.MustCompile(`(?i)` + strings.Join(words, "|"))
+}
+
+// buildHighlights — cuplikan HTML-escaped dengan <mark> untuk field yang cocok
+func buildHighlights(q SearchQuery, fields map[string]string) map[string]string {
+	highlights := map[string]string{}
+
+	pattern := q.highlightPattern()
+	if pattern == nil {
+		return highlights
+	}
+
+	for name, text := range fields {
+		if snippet, ok := Highlight(text, pattern, snippetRadius); ok {
+			highlights[name] = snippet
+		}
+	}
+	return highlights
+}
+
+// Highlight — potong teks di sekitar kecocokan pertama lalu tandai semua kecocokan
+func Highlight(text string, pattern *regexp.Regexp, radius int) (string, bool) {
+	first := pattern.FindStringIndex(text)
+	if first == nil {
+		return "", false
+	}
+
+	start := first[0] - radius
+	end := first[1] + radius
+	prefix, suffix := "…", "…"
+	if start <= 0 {
+		start, prefix = 0, ""
+	}
+	if end >= len(text) {
+		end, suffix = len(text), ""
+	}
+
+	// jangan memotong di tengah karakter multi-byte
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	window := text[start:end]
+
+	var b strings.Builder
+	b.WriteString(prefix)
+	last := 0
+	for _, m := range pattern.FindAllStringIndex(window, -1) {
+		b.WriteString(html.EscapeString(window[last:m[0]]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(window[m[0]:m[1]]))
+		b.WriteString("</mark>")
+		last = m[1]
+	}
+	b.WriteString(html.EscapeString(window[last:]))
+	b.WriteString(suffix)
+
+	return b.String(), true
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token tidak valid atau sudah kedaluwarsa")
+	ErrRefreshTokenReused  = errors.New("refresh token sudah pernah dipakai, sesi dicabut demi keamanan")
+)
+
+// TokenPair — access token (JWT berumur pendek) + refresh token untuk memperpanjangnya
+type TokenPair struct {
+	AccessToken      string    `json:"token"`
+	ExpiresIn        int       `json:"expires_in"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	SessionID        string    `json:"token_id"`
+}
+
+// SessionInfo — informasi perangkat saat login / refresh
+type SessionInfo struct {
+	Device    string
+	IPAddress string
+	UserAgent string
+}
+
+func envDuration(key string, unit time.Duration, fallback int) time.Duration {
+	n, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || n <= 0 {
+		n = fallback
+	}
+	return time.Duration(n) * unit
+}
+
+// AccessTokenTTL — umur access token (env ACCESS_TOKEN_TTL_MINUTES, default 15 menit)
+func AccessTokenTTL() time.Duration {
+	return envDuration("ACCESS_TOKEN_TTL_MINUTES", time.Minute, 15)
+}
+
+// RefreshTokenTTL — umur refresh token sejak terakhir dipakai (env REFRESH_TOKEN_TTL_DAYS, default 14 hari)
+func RefreshTokenTTL() time.Duration {
+	return envDuration("REFRESH_TOKEN_TTL_DAYS", 24*time.Hour, 14)
+}
+
+// MaxSessionsPerUser — jumlah perangkat yang boleh login bersamaan (env MAX_SESSIONS_PER_USER, default 2)
+func MaxSessionsPerUser() int {
+	if n, err := strconv.Atoi(os.Getenv("MAX_SESSIONS_PER_USER")); err == nil && n > 0 {
+		return n
+	}
+	return 2
+}
+
+func jwtSecret() []byte {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		secret = "default_secret"
+	}
+	return []byte(secret)
+}
+
+// HashToken — token disimpan di database dalam bentuk hash SHA-256
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func signAccessToken(user models.User, sessionID string, expiresAt time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id": user.ID,
+		"sid":     sessionID,
+		"exp":     expiresAt.Unix(),
+		"role":    user.Role,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret())
+}
+
+// issueTokens — buat access token + refresh token baru untuk sesi, lalu simpan hash-nya
+func issueTokens(tx *gorm.DB, user models.User, session *models.SecretToken) (TokenPair, error) {
+	now := time.Now()
+	accessExpires := now.Add(AccessTokenTTL())
+	refreshExpires := now.Add(RefreshTokenTTL())
+
+	access, err := signAccessToken(user, session.ID, accessExpires)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	refresh, err := randomToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	if err := tx.Model(session).Updates(map[string]interface{}{
+		"jwt_token":    HashToken(access),
+		"expires_at":   refreshExpires,
+		"last_seen_at": now,
+		"ip_address":   session.IPAddress,
+		"user_agent":   session.UserAgent,
+	}).Error; err != nil {
+		return TokenPair{}, err
+	}
+
+	if err := tx.Create(&models.RefreshToken{
+		SessionID: session.ID,
+		TokenHash: HashToken(refresh),
+		ExpiresAt: refreshExpires,
+	}).Error; err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		AccessToken:      access,
+		ExpiresIn:        int(AccessTokenTTL().Seconds()),
+		RefreshToken:     refresh,
+		RefreshExpiresAt: refreshExpires,
+		SessionID:        session.ID,
+	}, nil
+}
+
+// CreateSession — sesi baru saat login. Sesi lama di perangkat yang sama diganti (hanya bila
+// klien mengirim identitas perangkat), dan bila jumlah perangkat melebihi batas, sesi yang paling lama tidak aktif dicabut.
+func CreateSession(user models.User, info SessionInfo) (TokenPair, error) {
+	var pair TokenPair
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		if err := tx.Where("expires_at < ?", now).Delete(&models.SecretToken{}).Error; err != nil {
+			return err
+		}
+		if info.Device != "" {
+			if err := tx.Where("user_id = ? AND device = ?", user.ID, info.Device).Delete(&models.SecretToken{}).Error; err != nil {
+				return err
+			}
+		}
+
+		var sessions []models.SecretToken
+		if err := tx.Where("user_id = ?", user.ID).
+			Order("COALESCE(last_seen_at, created_at) DESC").
+			Find(&sessions).Error; err != nil {
+			return err
+		}
+		for i := MaxSessionsPerUser() - 1; i < len(sessions); i++ {
+			if err := tx.Delete(&sessions[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		// jwt_token unik, jadi diisi hash acak sampai access token (yang memuat ID sesi) dibuat
+		placeholder, err := randomToken()
+		if err != nil {
+			return err
+		}
+
+		session := models.SecretToken{
+			JwtToken:  HashToken(placeholder),
+			UserID:    user.ID,
+			Device:    info.Device,
+			ExpiresAt: now.Add(RefreshTokenTTL()),
+			IPAddress: info.IPAddress,
+			UserAgent: info.UserAgent,
+		}
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+
+		pair, err = issueTokens(tx, user, &session)
+		return err
+	})
+
+	return pair, err
+}
+
+// RefreshSession — tukar refresh token dengan pasangan token baru (rotasi).
+// Refresh token yang sudah pernah dipakai menandakan token dicuri: seluruh sesi dicabut.
+func RefreshSession(refreshToken string, info SessionInfo) (TokenPair, models.User, error) {
+	var pair TokenPair
+	var user models.User
+
+	var stored models.RefreshToken
+	if err := config.DB.Preload("Session.User").
+		Where("token_hash = ?", HashToken(refreshToken)).
+		First(&stored).Error; err != nil {
+		return pair, user, ErrRefreshTokenInvalid
+	}
+	user = stored.Session.User
+
+	if stored.UsedAt != nil {
+		RevokeSession(stored.SessionID)
+		return pair, user, ErrRefreshTokenReused
+	}
+	if time.Now().After(stored.ExpiresAt) || time.Now().After(stored.Session.ExpiresAt) {
+		RevokeSession(stored.SessionID)
+		return pair, user, ErrRefreshTokenInvalid
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// update bersyarat: dua request bersamaan dengan token yang sama, hanya satu yang menang
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", stored.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+
+		session := stored.Session
+		if info.IPAddress != "" {
+			session.IPAddress = info.IPAddress
+		}
+		if info.UserAgent != "" {
+			session.UserAgent = info.UserAgent
+		}
+
+		var err error
+		pair, err = issueTokens(tx, user, &session)
+		return err
+	})
+	if errors.Is(err, ErrRefreshTokenReused) {
+		RevokeSession(stored.SessionID)
+	}
+
+	return pair, user, err
+}
+
+// TouchSession — perbarui last_seen_at, paling sering sekali per menit per sesi
+func TouchSession(session models.SecretToken) {
+	if session.LastSeenAt != nil && time.Since(*session.LastSeenAt) < time.Minute {
+		return
+	}
+	config.DB.Model(&models.SecretToken{}).
+		Where("id = ?", session.ID).
+		UpdateColumn("last_seen_at", time.Now())
+}
+
+// RevokeSession — hapus satu sesi beserta refresh token-nya (cascade)
+func RevokeSession(sessionID string) error {
+	return config.DB.Where("id = ?", sessionID).Delete(&models.SecretToken{}).Error
+}
+
+// RevokeOtherSessions — cabut semua sesi user kecuali sesi yang sedang dipakai (mis. setelah ganti password)
+func RevokeOtherSessions(userID, keepSessionID string) (int64, error) {
+	result := config.DB.Where("user_id = ? AND id <> ?", userID, keepSessionID).Delete(&models.SecretToken{})
+	return result.RowsAffected, result.Error
+}
+
+// RevokeUserSessions — cabut semua sesi milik user, mengembalikan jumlah sesi yang dicabut
+func RevokeUserSessions(userID string) (int64, error) {
+	result := config.DB.Where("user_id = ?", userID).Delete(&models.SecretToken{})
+	return result.RowsAffected, result.Error
+}
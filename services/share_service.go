@@ -0,0 +1,312 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Hasil akses tautan publik yang dicatat di share_link_accesses
+const (
+	ShareAccessView          = "view"
+	ShareAccessDownload      = "download"
+	ShareAccessWrongPassword = "wrong_password"
+	ShareAccessExpired       = "expired"
+	ShareAccessExhausted     = "exhausted"
+	ShareAccessLocked        = "locked"
+)
+
+var (
+	ErrSharePermissionInvalid = errors.New("izin share harus view atau download")
+	ErrShareUserNotFound      = errors.New("user penerima tidak ditemukan")
+	ErrShareSelf              = errors.New("tidak perlu membagikan dokumen ke diri sendiri")
+	ErrShareExpiryInvalid     = errors.New("waktu kedaluwarsa harus di masa depan")
+	ErrShareNotFound          = errors.New("share tidak ditemukan")
+
+	ErrShareLinkNotFound      = errors.New("tautan tidak ditemukan atau sudah dicabut")
+	ErrShareLinkExpired       = errors.New("tautan sudah kedaluwarsa")
+	ErrShareLinkExhausted     = errors.New("batas unduhan tautan sudah habis")
+	ErrShareLinkPassword      = errors.New("password tautan salah")
+	ErrShareLinkLocked        = errors.New("terlalu banyak percobaan password, coba lagi nanti")
+	ErrShareLinkMaxDownloads  = errors.New("max_downloads minimal 1")
+	ErrShareLinkPasswordShort = errors.New("password tautan minimal 6 karakter")
+)
+
+// shareLinkPasswordAttempts — batas password salah per IP dalam jendela shareLinkLockWindow
+const (
+	shareLinkPasswordAttempts = 5
+	shareLinkLockWindow       = 15 * time.Minute
+)
+
+// =========================
+// Share ke user tertentu
+// =========================
+
+func validShareExpiry(expiresAt *time.Time) error {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return ErrShareExpiryInvalid
+	}
+	return nil
+}
+
+// ShareDocument — bagikan dokumen ke user. Share yang sudah ada diperbarui izin & kedaluwarsanya.
+func ShareDocument(ref DocumentRef, userID, permission string, expiresAt *time.Time, sharedByID string) (models.DocumentShare, error) {
+	share := models.DocumentShare{
+		DocumentType: ref.Type,
+		DocumentID:   ref.ID,
+		UserID:       userID,
+		Permission:   permission,
+		ExpiresAt:    expiresAt,
+		SharedByID:   &sharedByID,
+	}
+
+	if permission != SharePermissionView && permission != SharePermissionDownload {
+		return share, ErrSharePermissionInvalid
+	}
+	if userID == sharedByID {
+		return share, ErrShareSelf
+	}
+	if err := validShareExpiry(expiresAt); err != nil {
+		return share, err
+	}
+
+	var count int64
+	config.DB.Model(&models.User{}).Where("id = ?", userID).Count(&count)
+	if count == 0 {
+		return share, ErrShareUserNotFound
+	}
+
+	err := config.DB.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"permission", "expires_at", "shared_by_id", "updated_at"}),
+	}).Create(&share).Error
+	if err != nil {
+		return share, err
+	}
+
+	// ID baris lama tetap dipakai bila terjadi upsert
+	err = config.DB.Preload("User", selectSearchUser).
+		Where("document_type = ? AND document_id = ? AND user_id = ?", ref.Type, ref.ID, userID).
+		First(&share).Error
+	return share, err
+}
+
+// DocumentShares — daftar share sebuah dokumen (termasuk yang sudah kedaluwarsa)
+func DocumentShares(ref DocumentRef) ([]models.DocumentShare, error) {
+	var shares []models.DocumentShare
+	err := config.DB.Preload("User", selectSearchUser).
+		Where("document_type = ? AND document_id = ?", ref.Type, ref.ID).
+		Order("created_at DESC").
+		Find(&shares).Error
+	return shares, err
+}
+
+func RevokeDocumentShare(ref DocumentRef, shareID string) (models.DocumentShare, error) {
+	var share models.DocumentShare
+	if err := config.DB.
+		Where("id = ? AND document_type = ? AND document_id = ?", shareID, ref.Type, ref.ID).
+		First(&share).Error; err != nil {
+		return share, ErrShareNotFound
+	}
+	return share, config.DB.Delete(&share).Error
+}
+
+// ReceivedShares — share aktif yang diterima user, terbaru lebih dulu
+func ReceivedShares(userID string) ([]models.DocumentShare, error) {
+	var shares []models.DocumentShare
+	err := config.DB.
+		Where("user_id = ?", userID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Order("created_at DESC").
+		Find(&shares).Error
+	return shares, err
+}
+
+// =========================
+// Tautan publik
+// =========================
+
+// ShareLinkInput — password dan batas unduhan bersifat opsional
+type ShareLinkInput struct {
+	Password     string
+	ExpiresAt    *time.Time
+	MaxDownloads *int
+}
+
+// CreateShareLink — buat tautan publik. Token asli hanya dikembalikan sekali.
+func CreateShareLink(ref DocumentRef, input ShareLinkInput, createdByID string) (models.ShareLink, string, error) {
+	link := models.ShareLink{
+		DocumentType: ref.Type,
+		DocumentID:   ref.ID,
+		ExpiresAt:    input.ExpiresAt,
+		MaxDownloads: input.MaxDownloads,
+		CreatedByID:  createdByID,
+	}
+
+	if err := validShareExpiry(input.ExpiresAt); err != nil {
+		return link, "", err
+	}
+	if input.MaxDownloads != nil && *input.MaxDownloads < 1 {
+		return link, "", ErrShareLinkMaxDownloads
+	}
+	if input.Password != "" {
+		if len(input.Password) < 6 {
+			return link, "", ErrShareLinkPasswordShort
+		}
+		hashed, err := HashPassword(input.Password)
+		if err != nil {
+			return link, "", err
+		}
+		link.PasswordHash = hashed
+		link.HasPassword = true
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return link, "", err
+	}
+	link.TokenHash = HashToken(token)
+
+	if err := config.DB.Create(&link).Error; err != nil {
+		return link, "", err
+	}
+	return link, token, nil
+}
+
+func DocumentShareLinks(ref DocumentRef) ([]models.ShareLink, error) {
+	var links []models.ShareLink
+	err := config.DB.Preload("CreatedBy", selectSearchUser).
+		Where("document_type = ? AND document_id = ?", ref.Type, ref.ID).
+		Order("created_at DESC").
+		Find(&links).Error
+	return links, err
+}
+
+func RevokeShareLink(ref DocumentRef, linkID string) (models.ShareLink, error) {
+	var link models.ShareLink
+	if err := config.DB.
+		Where("id = ? AND document_type = ? AND document_id = ? AND revoked_at IS NULL", linkID, ref.Type, ref.ID).
+		First(&link).Error; err != nil {
+		return link, ErrShareLinkNotFound
+	}
+
+	now := time.Now()
+	link.RevokedAt = &now
+	return link, config.DB.Model(&link).Update("revoked_at", now).Error
+}
+
+// FindShareLink — cari tautan aktif berdasarkan token asli
+func FindShareLink(token string) (models.ShareLink, error) {
+	var link models.ShareLink
+	if token == "" {
+		return link, ErrShareLinkNotFound
+	}
+	if err := config.DB.Where("token_hash = ? AND revoked_at IS NULL", HashToken(token)).First(&link).Error; err != nil {
+		return link, ErrShareLinkNotFound
+	}
+	return link, nil
+}
+
+// RecordShareLinkAccess — catat akses (berhasil maupun ditolak) ke tautan publik
+func RecordShareLinkAccess(link models.ShareLink, outcome, ip, userAgent string) {
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	config.DB.Create(&models.ShareLinkAccess{
+		ShareLinkID: link.ID,
+		Outcome:     outcome,
+		IPAddress:   ip,
+		UserAgent:   userAgent,
+	})
+	config.DB.Model(&models.ShareLink{}).Where("id = ?", link.ID).Update("last_accessed_at", time.Now())
+}
+
+// ShareLinkAvailable — periksa masa berlaku dan sisa unduhan tautan. Sisa unduhan
+// hanya diperiksa bila request akan dihitung sebagai unduhan baru (bukan lanjutan Range).
+func ShareLinkAvailable(link models.ShareLink, counted bool) error {
+	if link.ExpiresAt != nil && time.Now().After(*link.ExpiresAt) {
+		return ErrShareLinkExpired
+	}
+	if counted && link.MaxDownloads != nil && link.DownloadCount >= *link.MaxDownloads {
+		return ErrShareLinkExhausted
+	}
+	return nil
+}
+
+// VerifyShareLinkPassword — cocokkan password tautan (bila ada).
+// Password salah berulang dari IP yang sama dikunci sementara.
+func VerifyShareLinkPassword(link models.ShareLink, password, ip string) error {
+	if link.PasswordHash == "" {
+		return nil
+	}
+
+	var failures int64
+	config.DB.Model(&models.ShareLinkAccess{}).
+		Where("share_link_id = ? AND ip_address = ? AND outcome = ? AND created_at > ?",
+			link.ID, ip, ShareAccessWrongPassword, time.Now().Add(-shareLinkLockWindow)).
+		Count(&failures)
+	if failures >= shareLinkPasswordAttempts {
+		return ErrShareLinkLocked
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)) != nil {
+		return ErrShareLinkPassword
+	}
+	return nil
+}
+
+// CheckShareLink — periksa masa berlaku, sisa unduhan dan password tautan
+func CheckShareLink(link models.ShareLink, password, ip string, counted bool) error {
+	if err := ShareLinkAvailable(link, counted); err != nil {
+		return err
+	}
+	return VerifyShareLinkPassword(link, password, ip)
+}
+
+// ConsumeShareLinkDownload — tambah penghitung unduhan secara atomik agar batas
+// max_downloads tidak terlampaui oleh request paralel
+func ConsumeShareLinkDownload(link models.ShareLink) error {
+	result := config.DB.Model(&models.ShareLink{}).
+		Where("id = ? AND (max_downloads IS NULL OR download_count < max_downloads)", link.ID).
+		Update("download_count", gorm.Expr("download_count + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrShareLinkExhausted
+	}
+	return nil
+}
+
+// ShareAccessOutcome — pemetaan error CheckShareLink ke outcome log akses
+func ShareAccessOutcome(err error) string {
+	switch {
+	case errors.Is(err, ErrShareLinkExpired):
+		return ShareAccessExpired
+	case errors.Is(err, ErrShareLinkExhausted):
+		return ShareAccessExhausted
+	case errors.Is(err, ErrShareLinkPassword):
+		return ShareAccessWrongPassword
+	case errors.Is(err, ErrShareLinkLocked):
+		return ShareAccessLocked
+	default:
+		return "error"
+	}
+}
+
+// DeleteDocumentShares — hapus permanen share, tautan publik beserta log aksesnya
+// (saat dokumen di-purge dari trash)
+func DeleteDocumentShares(docType, docID string) {
+	links := config.DB.Model(&models.ShareLink{}).
+		Select("id").
+		Where("document_type = ? AND document_id = ?", docType, docID)
+	config.DB.Where("share_link_id IN (?)", links).Delete(&models.ShareLinkAccess{})
+
+	config.DB.Where("document_type = ? AND document_id = ?", docType, docID).Delete(&models.ShareLink{})
+	config.DB.Where("document_type = ? AND document_id = ?", docType, docID).Delete(&models.DocumentShare{})
+}
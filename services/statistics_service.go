@@ -0,0 +1,443 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+
+	"gorm.io/gorm"
+)
+
+// Granularitas deret waktu statistik
+const (
+	StatsDay     = "day"
+	StatsWeek    = "week" // minggu ISO (Senin–Minggu)
+	StatsMonth   = "month"
+	StatsQuarter = "quarter"
+	StatsYear    = "year"
+)
+
+// Dimensi pengelompokan statistik
+const (
+	StatsByLetterType     = "letter_type"
+	StatsBySender         = "sender"
+	StatsByUploader       = "uploader"
+	StatsByClassification = "classification"
+	StatsByUnit           = "unit"
+)
+
+var (
+	ErrStatsGranularity = errors.New("granularity harus day, week, month, quarter atau year")
+	ErrStatsGroupBy     = errors.New("group_by harus letter_type, sender, uploader, classification atau unit")
+	ErrStatsPeriod      = errors.New("periode tidak valid")
+	ErrStatsTooLong     = errors.New("periode terlalu panjang untuk granularitas ini, perbesar granularitas atau persempit periode")
+)
+
+const (
+	statsMaxBuckets = 400
+	statsMaxGroups  = 50
+	statsGroupLimit = 10 // default jumlah kelompok teratas, sisanya digabung ke "Lainnya"
+	statsOtherKey   = "_lainnya"
+)
+
+// ekspresi kunci periode (bilangan bulat) — harus sama dengan statsBucketKey di sisi Go
+var statsBucketExpr = map[string]string{
+	StatsDay:     "YEAR(documents.created_at) * 10000 + MONTH(documents.created_at) * 100 + DAY(documents.created_at)",
+	StatsWeek:    "YEARWEEK(documents.created_at, 3)",
+	StatsMonth:   "YEAR(documents.created_at) * 100 + MONTH(documents.created_at)",
+	StatsQuarter: "YEAR(documents.created_at) * 10 + QUARTER(documents.created_at)",
+	StatsYear:    "YEAR(documents.created_at)",
+}
+
+var statsGroupExpr = map[string]string{
+	StatsByLetterType:     "documents.letter_type",
+	StatsBySender:         "documents.sender",
+	StatsByUploader:       "documents.user_id",
+	StatsByClassification: "documents.classification_code",
+	StatsByUnit:           "documents.unit_id",
+}
+
+// StatsParams — parameter statistik; From/To inklusif per tanggal
+type StatsParams struct {
+	Granularity string
+	GroupBy     string
+	From        time.Time
+	To          time.Time
+	Limit       int
+	Compare     bool // bandingkan dengan periode yang sama tahun sebelumnya
+}
+
+type StatsGroup struct {
+	Key           string `json:"key"`
+	Label         string `json:"label"`
+	Total         int64  `json:"total"`
+	PreviousTotal *int64 `json:"previous_total,omitempty"`
+}
+
+type StatsBucket struct {
+	Period   string           `json:"period"`
+	Start    string           `json:"start"`
+	End      string           `json:"end"`
+	Total    int64            `json:"total"`
+	Groups   map[string]int64 `json:"groups,omitempty"`
+	Previous *StatsBucket     `json:"previous,omitempty"`
+}
+
+type StatsComparison struct {
+	DateFrom      string   `json:"date_from"`
+	DateTo        string   `json:"date_to"`
+	Total         int64    `json:"total"`
+	Change        int64    `json:"change"`
+	ChangePercent *float64 `json:"change_percent"` // nil bila periode pembanding kosong
+}
+
+type DocumentStats struct {
+	Granularity string           `json:"granularity"`
+	GroupBy     string           `json:"group_by,omitempty"`
+	DateFrom    string           `json:"date_from"`
+	DateTo      string           `json:"date_to"`
+	Total       int64            `json:"total"`
+	Groups      []StatsGroup     `json:"groups,omitempty"`
+	Series      []StatsBucket    `json:"series"`
+	Comparison  *StatsComparison `json:"comparison,omitempty"`
+}
+
+// statsBucketStart — awal periode yang memuat t
+func statsBucketStart(t time.Time, granularity string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch granularity {
+	case StatsWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case StatsMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	case StatsQuarter:
+		return time.Date(t.Year(), t.Month()-(t.Month()-1)%3, 1, 0, 0, 0, 0, t.Location())
+	case StatsYear:
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+	}
+	return day
+}
+
+func statsNextBucket(start time.Time, granularity string) time.Time {
+	switch granularity {
+	case StatsWeek:
+		return start.AddDate(0, 0, 7)
+	case StatsMonth:
+		return start.AddDate(0, 1, 0)
+	case StatsQuarter:
+		return start.AddDate(0, 3, 0)
+	case StatsYear:
+		return start.AddDate(1, 0, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// statsBucketKey — padanan statsBucketExpr untuk awal periode
+func statsBucketKey(t time.Time, granularity string) (int, string) {
+	switch granularity {
+	case StatsWeek:
+		year, week := t.ISOWeek()
+		return year*100 + week, fmt.Sprintf("%d-W%02d", year, week)
+	case StatsMonth:
+		return t.Year()*100 + int(t.Month()), t.Format("2006-01")
+	case StatsQuarter:
+		quarter := (int(t.Month())-1)/3 + 1
+		return t.Year()*10 + quarter, fmt.Sprintf("%d-Q%d", t.Year(), quarter)
+	case StatsYear:
+		return t.Year(), t.Format("2006")
+	}
+	return t.Year()*10000 + int(t.Month())*100 + t.Day(), t.Format("2006-01-02")
+}
+
+// statsDayNumber — nomor hari sejak epoch, bebas dari pergeseran DST zona lokal
+func statsDayNumber(t time.Time) int64 {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+}
+
+// statsBucketCount — jumlah periode dari from sampai to, dihitung tanpa membangun deretnya
+// agar periode yang sangat panjang ditolak sebelum ada alokasi
+func statsBucketCount(from, to time.Time, granularity string) int64 {
+	quarter := func(t time.Time) int64 { return int64(t.Month()-1) / 3 }
+	years := int64(to.Year() - from.Year())
+
+	switch granularity {
+	case StatsWeek:
+		return (statsDayNumber(statsBucketStart(to, granularity))-statsDayNumber(statsBucketStart(from, granularity)))/7 + 1
+	case StatsMonth:
+		return years*12 + int64(to.Month()) - int64(from.Month()) + 1
+	case StatsQuarter:
+		return years*4 + quarter(to) - quarter(from) + 1
+	case StatsYear:
+		return years + 1
+	}
+	return statsDayNumber(to) - statsDayNumber(from) + 1
+}
+
+// statsSeries — deret periode kosong dari from sampai to; index memetakan kunci → posisi
+func statsSeries(from, to time.Time, granularity string) ([]StatsBucket, map[int]int) {
+	var series []StatsBucket
+	index := map[int]int{}
+
+	for start := statsBucketStart(from, granularity); !start.After(to); start = statsNextBucket(start, granularity) {
+		key, period := statsBucketKey(start, granularity)
+		end := statsNextBucket(start, granularity).AddDate(0, 0, -1)
+
+		bucketFrom, bucketTo := start, end
+		if bucketFrom.Before(from) {
+			bucketFrom = from
+		}
+		if bucketTo.After(to) {
+			bucketTo = to
+		}
+
+		index[key] = len(series)
+		series = append(series, StatsBucket{
+			Period: period,
+			Start:  bucketFrom.Format("2006-01-02"),
+			End:    bucketTo.Format("2006-01-02"),
+		})
+	}
+	return series, index
+}
+
+type statsRow struct {
+	Bucket   int
+	GroupKey string
+	Total    int64
+}
+
+// countDocuments — satu kueri berkelompok per periode (dan dimensi bila ada)
+func countDocuments(scope func(*gorm.DB) *gorm.DB, params StatsParams, from, to time.Time) ([]statsRow, error) {
+	query := scope(config.DB.Model(&models.Document{})).
+		Where("documents.created_at >= ? AND documents.created_at < ?", from, to.AddDate(0, 0, 1))
+
+	var rows []statsRow
+	if params.GroupBy == "" {
+		err := query.
+			Select(statsBucketExpr[params.Granularity] + " AS bucket, COUNT(*) AS total").
+			Group("bucket").
+			Scan(&rows).Error
+		return rows, err
+	}
+
+	err := query.
+		Select(statsBucketExpr[params.Granularity] + " AS bucket, COALESCE(" + statsGroupExpr[params.GroupBy] + ", '') AS group_key, COUNT(*) AS total").
+		Group("bucket, group_key").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// statsGroupLabels — nama tampilan untuk kunci kelompok
+func statsGroupLabels(groupBy string, keys []string) map[string]string {
+	labels := map[string]string{statsOtherKey: "Lainnya"}
+
+	var ids []string
+	for _, key := range keys {
+		if key == statsOtherKey {
+			continue
+		}
+		if key == "" {
+			labels[key] = "(tidak diisi)"
+			continue
+		}
+		labels[key] = key
+		ids = append(ids, key)
+	}
+
+	switch groupBy {
+	case StatsByLetterType:
+		labels["masuk"] = "Surat Masuk"
+		labels["keluar"] = "Surat Keluar"
+	case StatsByUploader:
+		labels[""] = "(user dihapus)"
+		if len(ids) > 0 {
+			var users []models.User
+			config.DB.Select("id", "name").Where("id IN ?", ids).Find(&users)
+			for _, u := range users {
+				labels[u.ID] = u.Name
+			}
+		}
+	case StatsByUnit:
+		labels[""] = "Seluruh kantor"
+		if len(ids) > 0 {
+			var units []models.OrgUnit
+			config.DB.Select("id", "name").Where("id IN ?", ids).Find(&units)
+			for _, u := range units {
+				labels[u.ID] = u.Name
+			}
+		}
+	}
+	return labels
+}
+
+// fillSeries — masukkan hasil kueri ke deret; kelompok di luar keep digabung ke "Lainnya"
+func fillSeries(series []StatsBucket, index map[int]int, rows []statsRow, grouped bool, keep map[string]bool) int64 {
+	var total int64
+	for _, row := range rows {
+		i, ok := index[row.Bucket]
+		if !ok {
+			continue
+		}
+
+		bucket := &series[i]
+		bucket.Total += row.Total
+		total += row.Total
+
+		if grouped {
+			key := row.GroupKey
+			if !keep[key] {
+				key = statsOtherKey
+			}
+			if bucket.Groups == nil {
+				bucket.Groups = map[string]int64{}
+			}
+			bucket.Groups[key] += row.Total
+		}
+	}
+	return total
+}
+
+// DocumentStatistics — statistik surat per periode, opsional per dimensi dan
+// dibandingkan dengan periode yang sama tahun sebelumnya. scope membatasi cakupan
+// surat yang boleh dilihat (lihat UnitAccess.ScopeDocuments).
+func DocumentStatistics(scope func(*gorm.DB) *gorm.DB, params StatsParams) (DocumentStats, error) {
+	if _, ok := statsBucketExpr[params.Granularity]; !ok {
+		return DocumentStats{}, ErrStatsGranularity
+	}
+	if _, ok := statsGroupExpr[params.GroupBy]; params.GroupBy != "" && !ok {
+		return DocumentStats{}, ErrStatsGroupBy
+	}
+	if params.To.Before(params.From) {
+		return DocumentStats{}, ErrStatsPeriod
+	}
+	if params.Limit <= 0 {
+		params.Limit = statsGroupLimit
+	}
+	params.Limit = min(params.Limit, statsMaxGroups)
+
+	if statsBucketCount(params.From, params.To, params.Granularity) > statsMaxBuckets {
+		return DocumentStats{}, ErrStatsTooLong
+	}
+	series, index := statsSeries(params.From, params.To, params.Granularity)
+
+	stats := DocumentStats{
+		Granularity: params.Granularity,
+		GroupBy:     params.GroupBy,
+		DateFrom:    params.From.Format("2006-01-02"),
+		DateTo:      params.To.Format("2006-01-02"),
+	}
+
+	rows, err := countDocuments(scope, params, params.From, params.To)
+	if err != nil {
+		return stats, err
+	}
+
+	grouped := params.GroupBy != ""
+	keep := map[string]bool{}
+	if grouped {
+		// kelompok teratas berdasarkan total periode berjalan
+		totals := map[string]int64{}
+		for _, row := range rows {
+			totals[row.GroupKey] += row.Total
+		}
+		keys := make([]string, 0, len(totals))
+		for key := range totals {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if totals[keys[i]] != totals[keys[j]] {
+				return totals[keys[i]] > totals[keys[j]]
+			}
+			return keys[i] < keys[j]
+		})
+		if len(keys) > params.Limit {
+			keys = keys[:params.Limit]
+		}
+		for _, key := range keys {
+			keep[key] = true
+		}
+	}
+
+	stats.Total = fillSeries(series, index, rows, grouped, keep)
+	stats.Series = series
+
+	var previousGroups map[string]int64
+	if params.Compare {
+		previousFrom, previousTo := params.From.AddDate(-1, 0, 0), params.To.AddDate(-1, 0, 0)
+		previousRows, err := countDocuments(scope, params, previousFrom, previousTo)
+		if err != nil {
+			return stats, err
+		}
+
+		previous, previousIndex := statsSeries(previousFrom, previousTo, params.Granularity)
+		previousTotal := fillSeries(previous, previousIndex, previousRows, grouped, keep)
+
+		// periode dipasangkan menurut urutan (minggu ke-n, bulan ke-n, ...)
+		for i := range stats.Series {
+			if i < len(previous) {
+				stats.Series[i].Previous = &previous[i]
+			}
+		}
+
+		comparison := &StatsComparison{
+			DateFrom: previousFrom.Format("2006-01-02"),
+			DateTo:   previousTo.Format("2006-01-02"),
+			Total:    previousTotal,
+			Change:   stats.Total - previousTotal,
+		}
+		if previousTotal > 0 {
+			percent := float64(comparison.Change) * 100 / float64(previousTotal)
+			comparison.ChangePercent = &percent
+		}
+		stats.Comparison = comparison
+
+		previousGroups = map[string]int64{}
+		for _, bucket := range previous {
+			for key, count := range bucket.Groups {
+				previousGroups[key] += count
+			}
+		}
+	}
+
+	if grouped {
+		totals := map[string]int64{}
+		for _, bucket := range stats.Series {
+			for key, count := range bucket.Groups {
+				totals[key] += count
+			}
+		}
+
+		keys := make([]string, 0, len(keep)+1)
+		for key := range keep {
+			keys = append(keys, key)
+		}
+		if totals[statsOtherKey] > 0 || previousGroups[statsOtherKey] > 0 {
+			keys = append(keys, statsOtherKey)
+		}
+		labels := statsGroupLabels(params.GroupBy, keys)
+
+		for _, key := range keys {
+			group := StatsGroup{Key: key, Label: labels[key], Total: totals[key]}
+			if params.Compare {
+				previous := previousGroups[key]
+				group.PreviousTotal = &previous
+			}
+			stats.Groups = append(stats.Groups, group)
+		}
+		sort.SliceStable(stats.Groups, func(i, j int) bool {
+			if (stats.Groups[i].Key == statsOtherKey) != (stats.Groups[j].Key == statsOtherKey) {
+				return stats.Groups[j].Key == statsOtherKey
+			}
+			if stats.Groups[i].Total != stats.Groups[j].Total {
+				return stats.Groups[i].Total > stats.Groups[j].Total
+			}
+			return stats.Groups[i].Key < stats.Groups[j].Key
+		})
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"log"
+
+	"dinsos_kuburaya/config"
+)
+
+// tabel yang menyimpan file dokumen; file_url di ketiganya menunjuk ke aset yang sama
+// ketika sebuah versi lama dipulihkan
+var storedFileTables = []string{"documents", "document_staffs", "document_versions"}
+
+// RestrictLegacyAssets — migrasi satu kali: file dokumen yang dulu diunggah ke Cloudinary
+// sebagai aset publik dipindah ke tipe authenticated. file_url yang masih berupa URL publik
+// menjadi penanda; setelah aset dipindah URL-nya diganti sehingga migrasi tidak berulang.
+// Mengembalikan jumlah aset yang dipindahkan.
+func RestrictLegacyAssets() (int, error) {
+	restrictor, ok := config.FileStorage.(config.LegacyAssetRestrictor)
+	if !ok {
+		return 0, nil
+	}
+
+	type asset struct {
+		PublicID     string
+		ResourceType string
+	}
+
+	restricted := 0
+	for _, table := range storedFileTables {
+		var assets []asset
+		if err := config.DB.Table(table).
+			Distinct("public_id", "resource_type").
+			Where("public_id <> '' AND public_id NOT LIKE ?", config.PublicStorageFolder+"/%").
+			Where("file_url LIKE ?", "%res.cloudinary.com/%/upload/%").
+			Scan(&assets).Error; err != nil {
+			return restricted, err
+		}
+
+		for _, a := range assets {
+			fileURL, err := restrictor.RestrictLegacyAsset(a.PublicID, a.ResourceType)
+			if err != nil {
+				log.Printf("❌ Gagal memindahkan file %s ke penyimpanan privat: %v\n", a.PublicID, err)
+				continue
+			}
+
+			for _, t := range storedFileTables {
+				if err := config.DB.Table(t).
+					Where("public_id = ? AND resource_type = ?", a.PublicID, a.ResourceType).
+					UpdateColumn("file_url", fileURL).Error; err != nil {
+					return restricted, err
+				}
+			}
+			restricted++
+		}
+	}
+
+	return restricted, nil
+}
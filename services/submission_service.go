@@ -0,0 +1,727 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Status permintaan pengumpulan
+const (
+	SubmissionOpen   = "open"
+	SubmissionClosed = "closed"
+)
+
+// Status pengumpulan per user
+const (
+	SubmissionSubmitted = "submitted" // dikumpulkan sebelum batas waktu
+	SubmissionLate      = "late"      // dikumpulkan setelah batas waktu
+	SubmissionMissing   = "missing"   // belum dikumpulkan, batas waktu lewat
+	SubmissionPending   = "pending"   // belum dikumpulkan, batas waktu belum lewat
+)
+
+var (
+	ErrSubmissionNotFound     = errors.New("permintaan pengumpulan tidak ditemukan")
+	ErrSubmissionTitle        = errors.New("judul permintaan wajib diisi")
+	ErrSubmissionDeadline     = errors.New("batas waktu harus di masa depan")
+	ErrSubmissionNoTargets    = errors.New("minimal satu user atau unit tujuan yang memiliki anggota")
+	ErrSubmissionUserNotFound = errors.New("sebagian user tujuan tidak ditemukan")
+	ErrSubmissionStatus       = errors.New("status harus open atau closed")
+	ErrSubmissionClosed       = errors.New("permintaan pengumpulan sudah ditutup")
+	ErrSubmissionLate         = errors.New("batas waktu pengumpulan sudah lewat")
+	ErrSubmissionNotTarget    = errors.New("anda tidak termasuk tujuan permintaan pengumpulan ini")
+)
+
+// submissionRemindBefore — pengingat dikirim sekali sebelum batas waktu (env SUBMISSION_REMIND_BEFORE_HOURS, default 24)
+func submissionRemindBefore() time.Duration {
+	return envDuration("SUBMISSION_REMIND_BEFORE_HOURS", time.Hour, 24)
+}
+
+// submissionOverdueInterval — jeda pengingat setelah batas waktu (env SUBMISSION_OVERDUE_INTERVAL_HOURS, default 24)
+func submissionOverdueInterval() time.Duration {
+	return envDuration("SUBMISSION_OVERDUE_INTERVAL_HOURS", time.Hour, 24)
+}
+
+// submissionOverdueMax — jumlah maksimal pengingat setelah batas waktu (env SUBMISSION_OVERDUE_MAX_REMINDERS, default 3)
+func submissionOverdueMax() int {
+	return envInt("SUBMISSION_OVERDUE_MAX_REMINDERS", 3)
+}
+
+func SubmissionLink(id string) string {
+	return "/submissions/" + id
+}
+
+func formatDeadline(t time.Time) string {
+	return FormatIndonesianDate(t) + " pukul " + t.Format("15:04")
+}
+
+// =========================
+// Kelola permintaan
+// =========================
+
+// SubmissionTargetInput — tujuan permintaan: user langsung dan/atau anggota unit
+type SubmissionTargetInput struct {
+	UserIDs         []string
+	UnitIDs         []string
+	IncludeSubunits bool
+}
+
+func (t SubmissionTargetInput) empty() bool {
+	return len(t.UserIDs) == 0 && len(t.UnitIDs) == 0
+}
+
+type SubmissionInput struct {
+	Title       string
+	Description string
+	Deadline    time.Time
+	AllowLate   *bool
+	Targets     SubmissionTargetInput
+}
+
+// SubmissionUpdate — field nil tidak diubah; Targets menambah tujuan baru
+type SubmissionUpdate struct {
+	Title       *string
+	Description *string
+	Deadline    *time.Time
+	AllowLate   *bool
+	Status      *string
+	Targets     SubmissionTargetInput
+}
+
+// resolveSubmissionTargets — uraikan user & unit tujuan menjadi daftar target unik
+func resolveSubmissionTargets(tx *gorm.DB, requestID string, input SubmissionTargetInput, exceptUserID string) ([]models.SubmissionTarget, error) {
+	seen := map[string]bool{exceptUserID: true}
+	var targets []models.SubmissionTarget
+
+	var userIDs []string
+	for _, id := range input.UserIDs {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			userIDs = append(userIDs, id)
+		}
+	}
+	if len(userIDs) > 0 {
+		var users []models.User
+		if err := tx.Select("id", "unit_id").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+			return nil, err
+		}
+		if len(users) != len(userIDs) {
+			return nil, ErrSubmissionUserNotFound
+		}
+		for _, u := range users {
+			targets = append(targets, models.SubmissionTarget{RequestID: requestID, UserID: u.ID, UnitID: u.UnitID})
+		}
+	}
+
+	if len(input.UnitIDs) > 0 {
+		for _, id := range input.UnitIDs {
+			if _, err := FindUnit(id); err != nil {
+				return nil, err
+			}
+		}
+		unitIDs := input.UnitIDs
+		if input.IncludeSubunits {
+			unitIDs = SubtreeUnitIDs(unitIDs...)
+		}
+
+		var members []models.User
+		if err := tx.Select("id", "unit_id").Where("unit_id IN ?", unitIDs).Find(&members).Error; err != nil {
+			return nil, err
+		}
+		for _, u := range members {
+			if seen[u.ID] {
+				continue
+			}
+			seen[u.ID] = true
+			targets = append(targets, models.SubmissionTarget{RequestID: requestID, UserID: u.ID, UnitID: u.UnitID})
+		}
+	}
+
+	return targets, nil
+}
+
+// addSubmissionTargets — simpan target; user yang sudah menjadi target dilewati.
+// Mengembalikan target yang benar-benar baru.
+func addSubmissionTargets(tx *gorm.DB, targets []models.SubmissionTarget) ([]models.SubmissionTarget, error) {
+	added := make([]models.SubmissionTarget, 0, len(targets))
+	for _, target := range targets {
+		target := target
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&target)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			added = append(added, target)
+		}
+	}
+	return added, nil
+}
+
+func notifySubmissionTargets(request models.SubmissionRequest, targets []models.SubmissionTarget) {
+	for _, target := range targets {
+		NotifySpecificUser(
+			target.UserID,
+			"Permintaan dokumen: "+request.Title+" — batas "+formatDeadline(request.Deadline),
+			SubmissionLink(request.ID),
+		)
+	}
+}
+
+// CreateSubmissionRequest — buat permintaan beserta targetnya lalu beri tahu setiap target
+func CreateSubmissionRequest(input SubmissionInput, creator models.User) (models.SubmissionRequest, int, error) {
+	request := models.SubmissionRequest{
+		Title:       strings.TrimSpace(input.Title),
+		Description: strings.TrimSpace(input.Description),
+		Deadline:    input.Deadline,
+		AllowLate:   true,
+		Status:      SubmissionOpen,
+		CreatedByID: &creator.ID,
+	}
+	if input.AllowLate != nil {
+		request.AllowLate = *input.AllowLate
+	}
+
+	if request.Title == "" {
+		return request, 0, ErrSubmissionTitle
+	}
+	if !request.Deadline.After(time.Now()) {
+		return request, 0, ErrSubmissionDeadline
+	}
+	if input.Targets.empty() {
+		return request, 0, ErrSubmissionNoTargets
+	}
+
+	var added []models.SubmissionTarget
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&request).Error; err != nil {
+			return err
+		}
+
+		targets, err := resolveSubmissionTargets(tx, request.ID, input.Targets, creator.ID)
+		if err != nil {
+			return err
+		}
+		if len(targets) == 0 {
+			return ErrSubmissionNoTargets
+		}
+
+		added, err = addSubmissionTargets(tx, targets)
+		return err
+	})
+	if err != nil {
+		return request, 0, err
+	}
+
+	notifySubmissionTargets(request, added)
+	return request, len(added), nil
+}
+
+func FindSubmissionRequest(id string) (models.SubmissionRequest, error) {
+	var request models.SubmissionRequest
+	err := config.DB.Preload("CreatedBy", selectSearchUser).First(&request, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return request, ErrSubmissionNotFound
+	}
+	return request, err
+}
+
+// UpdateSubmissionRequest — ubah permintaan. Batas waktu yang berubah mengulang siklus pengingat.
+func UpdateSubmissionRequest(id string, input SubmissionUpdate) (models.SubmissionRequest, int, error) {
+	request, err := FindSubmissionRequest(id)
+	if err != nil {
+		return request, 0, err
+	}
+
+	updates := map[string]interface{}{}
+	deadlineChanged := false
+
+	if input.Title != nil {
+		title := strings.TrimSpace(*input.Title)
+		if title == "" {
+			return request, 0, ErrSubmissionTitle
+		}
+		updates["title"] = title
+	}
+	if input.Description != nil {
+		updates["description"] = strings.TrimSpace(*input.Description)
+	}
+	if input.Deadline != nil && !input.Deadline.Equal(request.Deadline) {
+		if !input.Deadline.After(time.Now()) {
+			return request, 0, ErrSubmissionDeadline
+		}
+		updates["deadline"] = *input.Deadline
+		updates["deadline_notified_at"] = nil
+		deadlineChanged = true
+	}
+	if input.AllowLate != nil {
+		updates["allow_late"] = *input.AllowLate
+	}
+	if input.Status != nil && *input.Status != request.Status {
+		switch *input.Status {
+		case SubmissionClosed:
+			updates["status"] = SubmissionClosed
+			updates["closed_at"] = time.Now()
+		case SubmissionOpen:
+			updates["status"] = SubmissionOpen
+			updates["closed_at"] = nil
+		default:
+			return request, 0, ErrSubmissionStatus
+		}
+	}
+
+	var added []models.SubmissionTarget
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(&request).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if deadlineChanged {
+			if err := tx.Model(&models.SubmissionTarget{}).
+				Where("request_id = ?", request.ID).
+				Updates(map[string]interface{}{
+					"reminded_before_at": nil,
+					"overdue_reminders":  0,
+					"last_reminded_at":   nil,
+				}).Error; err != nil {
+				return err
+			}
+		}
+
+		if input.Targets.empty() {
+			return nil
+		}
+		exceptUserID := ""
+		if request.CreatedByID != nil {
+			exceptUserID = *request.CreatedByID
+		}
+		targets, err := resolveSubmissionTargets(tx, request.ID, input.Targets, exceptUserID)
+		if err != nil {
+			return err
+		}
+		added, err = addSubmissionTargets(tx, targets)
+		return err
+	})
+	if err != nil {
+		return request, 0, err
+	}
+
+	request, err = FindSubmissionRequest(id)
+	if err != nil {
+		return request, 0, err
+	}
+
+	if request.Status == SubmissionOpen {
+		notifySubmissionTargets(request, added)
+	}
+	return request, len(added), nil
+}
+
+// DeleteSubmissionRequest — hapus permintaan; dokumen yang sudah dikumpulkan tetap ada
+func DeleteSubmissionRequest(request models.SubmissionRequest) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		// UpdateColumn: lewati hook BeforeSave DocumentStaff
+		if err := tx.Model(&models.DocumentStaff{}).Unscoped().
+			Where("submission_request_id = ?", request.ID).
+			UpdateColumn("submission_request_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("request_id = ?", request.ID).Delete(&models.SubmissionTarget{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&request).Error
+	})
+}
+
+// =========================
+// Status pengumpulan
+// =========================
+
+// SubmissionSummary — rekap kepatuhan satu permintaan
+type SubmissionSummary struct {
+	Total     int `json:"total"`
+	Submitted int `json:"submitted"`
+	Late      int `json:"late"`
+	Missing   int `json:"missing"`
+	Pending   int `json:"pending"`
+}
+
+func (s *SubmissionSummary) add(status string) {
+	s.Total++
+	switch status {
+	case SubmissionSubmitted:
+		s.Submitted++
+	case SubmissionLate:
+		s.Late++
+	case SubmissionMissing:
+		s.Missing++
+	default:
+		s.Pending++
+	}
+}
+
+// SubmissionEntry — status pengumpulan satu target
+type SubmissionEntry struct {
+	User           models.User `json:"user"`
+	UnitID         *string     `json:"unit_id"`
+	Status         string      `json:"status"`
+	SubmittedAt    *time.Time  `json:"submitted_at"`
+	Documents      int         `json:"documents"`
+	LastRemindedAt *time.Time  `json:"last_reminded_at"`
+}
+
+// SubmissionRequestView — permintaan beserta rekap (untuk pengelola) atau status user sendiri
+type SubmissionRequestView struct {
+	models.SubmissionRequest
+	Summary       *SubmissionSummary `json:"summary,omitempty"`
+	MyStatus      string             `json:"my_status,omitempty"`
+	MySubmittedAt *time.Time         `json:"my_submitted_at,omitempty"`
+}
+
+// submissionRecord — dokumen yang sudah dikumpulkan satu user untuk satu permintaan
+type submissionRecord struct {
+	SubmissionRequestID string
+	UserID              string
+	FirstAt             time.Time
+	Total               int
+}
+
+// submissionRecords — satu kueri berkelompok: request → user → pengumpulan pertama.
+// Dokumen yang sudah di-trash tidak dihitung.
+func submissionRecords(requestIDs []string, userID string) (map[string]map[string]submissionRecord, error) {
+	records := map[string]map[string]submissionRecord{}
+	if len(requestIDs) == 0 {
+		return records, nil
+	}
+
+	query := config.DB.Model(&models.DocumentStaff{}).
+		Select("submission_request_id, user_id, MIN(created_at) AS first_at, COUNT(*) AS total").
+		Where("submission_request_id IN ?", requestIDs)
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var rows []submissionRecord
+	if err := query.Group("submission_request_id, user_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if records[row.SubmissionRequestID] == nil {
+			records[row.SubmissionRequestID] = map[string]submissionRecord{}
+		}
+		records[row.SubmissionRequestID][row.UserID] = row
+	}
+	return records, nil
+}
+
+func submissionStatus(request models.SubmissionRequest, record submissionRecord, submitted bool, now time.Time) string {
+	switch {
+	case submitted && record.FirstAt.After(request.Deadline):
+		return SubmissionLate
+	case submitted:
+		return SubmissionSubmitted
+	case now.After(request.Deadline):
+		return SubmissionMissing
+	}
+	return SubmissionPending
+}
+
+// SubmissionSummaries — rekap untuk banyak permintaan sekaligus
+func SubmissionSummaries(requests []models.SubmissionRequest) ([]SubmissionRequestView, error) {
+	ids := make([]string, len(requests))
+	for i, r := range requests {
+		ids[i] = r.ID
+	}
+
+	records, err := submissionRecords(ids, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []models.SubmissionTarget
+	if len(ids) > 0 {
+		if err := config.DB.Select("request_id", "user_id").Where("request_id IN ?", ids).Find(&targets).Error; err != nil {
+			return nil, err
+		}
+	}
+	byRequest := map[string][]string{}
+	for _, t := range targets {
+		byRequest[t.RequestID] = append(byRequest[t.RequestID], t.UserID)
+	}
+
+	now := time.Now()
+	views := make([]SubmissionRequestView, len(requests))
+	for i, request := range requests {
+		summary := &SubmissionSummary{}
+		for _, userID := range byRequest[request.ID] {
+			record, submitted := records[request.ID][userID]
+			summary.add(submissionStatus(request, record, submitted, now))
+		}
+		views[i] = SubmissionRequestView{SubmissionRequest: request, Summary: summary}
+	}
+	return views, nil
+}
+
+// MySubmissionViews — permintaan yang ditujukan ke user beserta status pengumpulannya
+func MySubmissionViews(requests []models.SubmissionRequest, userID string) ([]SubmissionRequestView, error) {
+	ids := make([]string, len(requests))
+	for i, r := range requests {
+		ids[i] = r.ID
+	}
+
+	records, err := submissionRecords(ids, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	views := make([]SubmissionRequestView, len(requests))
+	for i, request := range requests {
+		record, submitted := records[request.ID][userID]
+		views[i] = SubmissionRequestView{
+			SubmissionRequest: request,
+			MyStatus:          submissionStatus(request, record, submitted, now),
+		}
+		if submitted {
+			firstAt := record.FirstAt
+			views[i].MySubmittedAt = &firstAt
+		}
+	}
+	return views, nil
+}
+
+// SubmissionReport — status setiap target beserta rekapnya; status kosong = semua
+func SubmissionReport(request models.SubmissionRequest, status string) ([]SubmissionEntry, SubmissionSummary, error) {
+	var summary SubmissionSummary
+
+	var targets []models.SubmissionTarget
+	if err := config.DB.Preload("User", selectSearchUser).
+		Where("request_id = ?", request.ID).
+		Order("id ASC").
+		Find(&targets).Error; err != nil {
+		return nil, summary, err
+	}
+
+	records, err := submissionRecords([]string{request.ID}, "")
+	if err != nil {
+		return nil, summary, err
+	}
+
+	now := time.Now()
+	entries := make([]SubmissionEntry, 0, len(targets))
+	for _, target := range targets {
+		record, submitted := records[request.ID][target.UserID]
+		entry := SubmissionEntry{
+			User:           target.User,
+			UnitID:         target.UnitID,
+			Status:         submissionStatus(request, record, submitted, now),
+			Documents:      record.Total,
+			LastRemindedAt: target.LastRemindedAt,
+		}
+		if submitted {
+			firstAt := record.FirstAt
+			entry.SubmittedAt = &firstAt
+		}
+
+		summary.add(entry.Status)
+		if status == "" || entry.Status == status {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, summary, nil
+}
+
+// IsSubmissionTarget — apakah user termasuk tujuan permintaan
+func IsSubmissionTarget(requestID, userID string) bool {
+	var count int64
+	config.DB.Model(&models.SubmissionTarget{}).
+		Where("request_id = ? AND user_id = ?", requestID, userID).
+		Count(&count)
+	return count > 0
+}
+
+// =========================
+// Pengumpulan dokumen
+// =========================
+
+// CheckSubmissionUpload — pastikan user boleh mengumpulkan dokumen untuk permintaan ini sekarang
+func CheckSubmissionUpload(requestID string, user models.User) (models.SubmissionRequest, error) {
+	request, err := FindSubmissionRequest(requestID)
+	if err != nil {
+		return request, err
+	}
+	if !IsSubmissionTarget(request.ID, user.ID) {
+		return request, ErrSubmissionNotTarget
+	}
+	if request.Status != SubmissionOpen {
+		return request, ErrSubmissionClosed
+	}
+	if !request.AllowLate && time.Now().After(request.Deadline) {
+		return request, ErrSubmissionLate
+	}
+	return request, nil
+}
+
+// NotifySubmissionReceived — kabari pembuat permintaan bahwa dokumen sudah dikumpulkan
+func NotifySubmissionReceived(request models.SubmissionRequest, user models.User) {
+	message := user.Name + " mengumpulkan dokumen untuk " + request.Title
+	if time.Now().After(request.Deadline) {
+		message += " (terlambat)"
+	}
+
+	if request.CreatedByID == nil {
+		NotifyAdmins(PermSubmissionManage, message, SubmissionLink(request.ID))
+		return
+	}
+	NotifySpecificUser(*request.CreatedByID, message, SubmissionLink(request.ID))
+}
+
+// =========================
+// Pengingat
+// =========================
+
+// pendingSubmissionTargets — target yang belum mengumpulkan
+func pendingSubmissionTargets(request models.SubmissionRequest) *gorm.DB {
+	submitted := config.DB.Model(&models.DocumentStaff{}).
+		Select("user_id").
+		Where("submission_request_id = ?", request.ID)
+
+	return config.DB.Model(&models.SubmissionTarget{}).
+		Where("request_id = ? AND user_id NOT IN (?)", request.ID, submitted)
+}
+
+// claimReminder — tandai pengingat terkirim; hanya satu instance yang berhasil
+// mengklaim target yang sama, sehingga pengingat tidak terkirim ganda
+func claimReminder(targetID uint, condition *gorm.DB, updates map[string]interface{}) bool {
+	result := condition.Model(&models.SubmissionTarget{}).Where("id = ?", targetID).Updates(updates)
+	return result.Error == nil && result.RowsAffected > 0
+}
+
+// RemindSubmissionTargets — kirim pengingat manual ke semua target yang belum mengumpulkan
+func RemindSubmissionTargets(request models.SubmissionRequest) (int, error) {
+	if request.Status != SubmissionOpen {
+		return 0, ErrSubmissionClosed
+	}
+
+	var targets []models.SubmissionTarget
+	if err := pendingSubmissionTargets(request).Find(&targets).Error; err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	for _, target := range targets {
+		config.DB.Model(&target).Update("last_reminded_at", now)
+		NotifySpecificUser(target.UserID, reminderMessage(request, now), SubmissionLink(request.ID))
+	}
+	return len(targets), nil
+}
+
+func reminderMessage(request models.SubmissionRequest, now time.Time) string {
+	if now.After(request.Deadline) {
+		return "Terlambat: " + request.Title + " belum dikumpulkan (batas " + formatDeadline(request.Deadline) + ")"
+	}
+	return "Pengingat: kumpulkan " + request.Title + " paling lambat " + formatDeadline(request.Deadline)
+}
+
+// SendSubmissionReminders — pengingat otomatis: sekali menjelang batas waktu, beberapa kali
+// setelah batas waktu (bila masih boleh terlambat), dan rekap ke pembuat saat batas waktu lewat.
+// Mengembalikan jumlah notifikasi yang dikirim.
+func SendSubmissionReminders(now time.Time) (int, error) {
+	sent := 0
+
+	// menjelang batas waktu
+	var upcoming []models.SubmissionRequest
+	if err := config.DB.
+		Where("status = ? AND deadline > ? AND deadline <= ?", SubmissionOpen, now, now.Add(submissionRemindBefore())).
+		Find(&upcoming).Error; err != nil {
+		return sent, err
+	}
+	for _, request := range upcoming {
+		var targets []models.SubmissionTarget
+		if err := pendingSubmissionTargets(request).Where("reminded_before_at IS NULL").Find(&targets).Error; err != nil {
+			return sent, err
+		}
+		for _, target := range targets {
+			if !claimReminder(target.ID, config.DB.Where("reminded_before_at IS NULL"), map[string]interface{}{
+				"reminded_before_at": now,
+				"last_reminded_at":   now,
+			}) {
+				continue
+			}
+			NotifySpecificUser(target.UserID, reminderMessage(request, now), SubmissionLink(request.ID))
+			sent++
+		}
+	}
+
+	// setelah batas waktu
+	var overdue []models.SubmissionRequest
+	if err := config.DB.
+		Where("status = ? AND deadline <= ?", SubmissionOpen, now).
+		Find(&overdue).Error; err != nil {
+		return sent, err
+	}
+	for _, request := range overdue {
+		if request.DeadlineNotifiedAt == nil {
+			sent += notifySubmissionDeadline(request, now)
+		}
+		if !request.AllowLate {
+			continue
+		}
+
+		due := now.Add(-submissionOverdueInterval())
+		var targets []models.SubmissionTarget
+		if err := pendingSubmissionTargets(request).
+			Where("overdue_reminders < ?", submissionOverdueMax()).
+			Where("overdue_reminders = 0 OR last_reminded_at IS NULL OR last_reminded_at <= ?", due).
+			Find(&targets).Error; err != nil {
+			return sent, err
+		}
+		for _, target := range targets {
+			if !claimReminder(target.ID, config.DB.Where("overdue_reminders = ?", target.OverdueReminders), map[string]interface{}{
+				"overdue_reminders": target.OverdueReminders + 1,
+				"last_reminded_at":  now,
+			}) {
+				continue
+			}
+			NotifySpecificUser(target.UserID, reminderMessage(request, now), SubmissionLink(request.ID))
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// notifySubmissionDeadline — rekap sekali ke pembuat saat batas waktu lewat
+func notifySubmissionDeadline(request models.SubmissionRequest, now time.Time) int {
+	result := config.DB.Model(&models.SubmissionRequest{}).
+		Where("id = ? AND deadline_notified_at IS NULL", request.ID).
+		Update("deadline_notified_at", now)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return 0
+	}
+
+	_, summary, err := SubmissionReport(request, "")
+	if err != nil {
+		return 0
+	}
+
+	message := "Batas waktu " + request.Title + " lewat: " +
+		strconv.Itoa(summary.Submitted) + " tepat waktu, " +
+		strconv.Itoa(summary.Late) + " terlambat, " +
+		strconv.Itoa(summary.Missing) + " belum mengumpulkan dari " +
+		strconv.Itoa(summary.Total) + " user"
+
+	if request.CreatedByID == nil {
+		NotifyAdmins(PermSubmissionManage, message, SubmissionLink(request.ID))
+	} else {
+		NotifySpecificUser(*request.CreatedByID, message, SubmissionLink(request.ID))
+	}
+	return 1
+}
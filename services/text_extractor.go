@@ -0,0 +1,367 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// metode ekstraksi yang dicatat pada dokumen
+const (
+	ExtractionMethodText = "text" // teks tertanam (PDF digital, Office, txt)
+	ExtractionMethodOCR  = "ocr"  // hasil OCR Tesseract
+)
+
+const (
+	// PDF dengan teks tertanam kurang dari ini dianggap hasil scan dan di-OCR
+	minEmbeddedTextRunes = 50
+	// batas ukuran teks yang disimpan ke database
+	maxExtractedTextBytes = 1 << 20
+	// batas ukuran satu berkas di dalam arsip Office (mencegah zip bomb)
+	maxOfficeMemberBytes = 50 << 20
+	// batas waktu satu perintah eksternal (pdftotext, pdftoppm, tesseract)
+	extractorCommandTimeout = 5 * time.Minute
+)
+
+// ErrUnsupportedFormat — format file tidak bisa diekstrak (mis. .doc lama)
+var ErrUnsupportedFormat = errors.New("format file tidak didukung untuk ekstraksi teks")
+
+// ocrLanguages — bahasa Tesseract, default Indonesia + Inggris
+func ocrLanguages() string {
+	if langs := os.Getenv("OCR_LANGUAGES"); langs != "" {
+		return langs
+	}
+	return "ind+eng"
+}
+
+// ocrMaxPages — jumlah halaman PDF scan maksimum yang di-OCR
+func ocrMaxPages() int {
+	if n, err := strconv.Atoi(os.Getenv("OCR_MAX_PAGES")); err == nil && n > 0 {
+		return n
+	}
+	return 30
+}
+
+// ExtractText — ambil isi teks dari file berdasarkan ekstensinya
+func ExtractText(fileName string, data []byte) (text, method string, err error) {
+	ext := strings.ToLower(filepath.Ext(fileName))
+
+	// ekstensi tidak dikenal: tebak dari isi file
+	if ext == "" {
+		switch contentType := http.DetectContentType(data); {
+		case contentType == "application/pdf":
+			ext = ".pdf"
+		case strings.HasPrefix(contentType, "image/"):
+			ext = ".png"
+		}
+	}
+
+	switch ext {
+	case ".pdf":
+		return extractPDF(data)
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp", ".bmp", ".tif", ".tiff":
+		text, err = ocrImage(data, ext)
+		return text, ExtractionMethodOCR, err
+	case ".docx":
+		text, err = extractOOXML(data, []string{"word/document.xml", "word/header*.xml", "word/footer*.xml", "word/footnotes.xml"})
+	case ".xlsx":
+		text, err = extractOOXML(data, []string{"xl/sharedStrings.xml", "xl/worksheets/sheet*.xml"})
+	case ".pptx":
+		text, err = extractOOXML(data, []string{"ppt/slides/slide*.xml", "ppt/notesSlides/notesSlide*.xml"})
+	case ".odt", ".ods", ".odp":
+		text, err = extractODF(data)
+	case ".txt", ".csv":
+		text = string(data)
+	default:
+		return "", "", ErrUnsupportedFormat
+	}
+
+	return text, ExtractionMethodText, err
+}
+
+// =========================
+// PDF & OCR (perintah eksternal)
+// =========================
+
+// runTool — jalankan perintah eksternal dengan batas waktu, kembalikan stdout
+func runTool(name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("%s tidak terpasang di server", name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), extractorCommandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("%s gagal: %s", name, msg)
+	}
+	return stdout.Bytes(), nil
+}
+
+// writeTempInput — simpan data ke direktori sementara untuk diproses perintah eksternal
+func writeTempInput(data []byte, ext string) (dir, path string, err error) {
+	dir, err = os.MkdirTemp("", "extract-*")
+	if err != nil {
+		return "", "", err
+	}
+	path = filepath.Join(dir, "input"+ext)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		os.RemoveAll(dir)
+		return "", "", err
+	}
+	return dir, path, nil
+}
+
+// extractPDF — pakai teks tertanam, jatuh ke OCR bila PDF hanya berisi gambar scan
+func extractPDF(data []byte) (string, string, error) {
+	dir, input, err := writeTempInput(data, ".pdf")
+	if err != nil {
+		return "", "", err
+	}
+	defer os.RemoveAll(dir)
+
+	out, err := runTool("pdftotext", "-layout", "-enc", "UTF-8", input, "-")
+	if err != nil {
+		return "", "", err
+	}
+
+	text := string(out)
+	if countLetters(text) >= minEmbeddedTextRunes {
+		return text, ExtractionMethodText, nil
+	}
+
+	// render tiap halaman menjadi PNG lalu OCR satu per satu
+	if _, err := runTool("pdftoppm", "-r", "300", "-gray", "-png",
+		"-f", "1", "-l", strconv.Itoa(ocrMaxPages()),
+		input, filepath.Join(dir, "page")); err != nil {
+		return "", "", err
+	}
+
+	pages, _ := filepath.Glob(filepath.Join(dir, "page-*.png"))
+	sort.Slice(pages, func(i, j int) bool { return pageNumber(pages[i]) < pageNumber(pages[j]) })
+
+	var b strings.Builder
+	for _, page := range pages {
+		out, err := runTool("tesseract", page, "stdout", "-l", ocrLanguages())
+		if err != nil {
+			return "", "", err
+		}
+		b.Write(out)
+		b.WriteString("\n")
+	}
+
+	return b.String(), ExtractionMethodOCR, nil
+}
+
+// ocrImage — OCR langsung pada file gambar
+func ocrImage(data []byte, ext string) (string, error) {
+	dir, input, err := writeTempInput(data, ext)
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	out, err := runTool("tesseract", input, "stdout", "-l", ocrLanguages())
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+var trailingNumber = regexp.MustCompile(`(\d+)\D*$`)
+
+// pageNumber — nomor urut dari nama file seperti page-07.png atau slide12.xml
+func pageNumber(name string) int {
+	m := trailingNumber.FindStringSubmatch(filepath.Base(name))
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+func countLetters(s string) int {
+	n := 0
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// =========================
+// Office (OOXML & OpenDocument)
+// =========================
+
+// extractOOXML — baca teks dari berkas XML di dalam arsip .docx/.xlsx/.pptx.
+// Pola diproses berurutan; berkas yang cocok satu pola diurutkan berdasarkan nomornya.
+func extractOOXML(data []byte, patterns []string) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("file Office tidak valid: %v", err)
+	}
+
+	var b strings.Builder
+	for _, pattern := range patterns {
+		var matched []*zip.File
+		for _, f := range archive.File {
+			if ok, _ := filepath.Match(pattern, f.Name); ok {
+				matched = append(matched, f)
+			}
+		}
+		sort.Slice(matched, func(i, j int) bool { return pageNumber(matched[i].Name) < pageNumber(matched[j].Name) })
+
+		for _, f := range matched {
+			text, err := readZipXML(f, false)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(text)
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String(), nil
+}
+
+// extractODF — OpenDocument menyimpan seluruh isi di content.xml
+func extractODF(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("file OpenDocument tidak valid: %v", err)
+	}
+
+	for _, f := range archive.File {
+		if f.Name == "content.xml" {
+			return readZipXML(f, true)
+		}
+	}
+	return "", errors.New("content.xml tidak ditemukan")
+}
+
+func readZipXML(f *zip.File, allText bool) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	return xmlText(io.LimitReader(rc, maxOfficeMemberBytes), allText)
+}
+
+// xmlText — kumpulkan teks dari dokumen XML Office.
+// OOXML: hanya isi elemen <t> (w:t, a:t, t pada sel). OpenDocument (allText): semua teks.
+// Paragraf, baris tabel, dan sel dipisahkan agar kata tidak menempel.
+func xmlText(r io.Reader, allText bool) (string, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+
+	var b strings.Builder
+	inText := 0
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("XML dokumen rusak: %v", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText++
+			case "tab":
+				b.WriteString("\t")
+			case "br", "line-break":
+				b.WriteString("\n")
+			case "s":
+				// <text:s/> OpenDocument = spasi
+				if allText {
+					b.WriteString(" ")
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				if inText > 0 {
+					inText--
+				}
+			case "p", "h", "si", "tr", "row", "table-row":
+				b.WriteString("\n")
+			case "c", "tc", "table-cell":
+				b.WriteString("\t")
+			}
+		case xml.CharData:
+			if allText || inText > 0 {
+				b.Write(t)
+			}
+		}
+	}
+
+	return b.String(), nil
+}
+
+// =========================
+// Normalisasi
+// =========================
+
+var (
+	horizontalSpace = regexp.MustCompile(`[ \t\f\v\x{00A0}]+`)
+	blankLines      = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// NormalizeExtractedText — rapikan spasi, buang karakter kontrol, dan batasi ukurannya
+func NormalizeExtractedText(text string) string {
+	text = strings.ToValidUTF8(text, "")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' || !unicode.IsControl(r) {
+			return r
+		}
+		return ' '
+	}, text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(horizontalSpace.ReplaceAllString(line, " "))
+	}
+	text = strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+
+	if len(text) > maxExtractedTextBytes {
+		cut := maxExtractedTextBytes
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut]
+	}
+
+	return text
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// parameter TOTP standar (RFC 6238) yang didukung Google Authenticator dkk.
+const (
+	totpPeriod = 30
+	totpDigits = 6
+	// toleransi selisih jam perangkat: satu langkah sebelum & sesudah
+	totpSkew = 1
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPIssuer — nama aplikasi yang tampil di aplikasi authenticator (env TOTP_ISSUER)
+func TOTPIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "Dinsos Kubu Raya"
+}
+
+// GenerateTOTPSecret — secret acak 160 bit dalam base32
+func GenerateTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPProvisioningURI — URI otpauth:// untuk dijadikan QR code oleh frontend
+func TOTPProvisioningURI(secret, accountName string) string {
+	issuer := TOTPIssuer()
+	label := url.PathEscape(issuer + ":" + accountName)
+
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+func totpCode(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%uint32(math.Pow10(totpDigits)))
+}
+
+// VerifyTOTP — cocokkan kode dengan secret. Mengembalikan langkah waktu yang cocok
+// agar pemanggil bisa menolak kode yang sama dipakai dua kali (lastStep).
+func VerifyTOTP(secret, code string, at time.Time, lastStep int64) (int64, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+
+	current := at.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// =========================
+// Enkripsi secret TOTP di database (AES-GCM)
+// =========================
+
+// kunci dari env TOTP_ENCRYPTION_KEY, jatuh ke JWT_SECRET bila tidak diisi
+func totpEncryptionKey() []byte {
+	key := os.Getenv("TOTP_ENCRYPTION_KEY")
+	if key == "" {
+		key = string(jwtSecret())
+	}
+	sum := sha256.Sum256([]byte("totp:" + key))
+	return sum[:]
+}
+
+func EncryptTOTPSecret(secret string) (string, error) {
+	block, err := aes.NewCipher(totpEncryptionKey())
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func DecryptTOTPSecret(encrypted string) (string, error) {
+	raw, err := base64.RawStdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(totpEncryptionKey())
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < gcm.NonceSize() {
+		return "", errors.New("secret 2FA rusak")
+	}
+
+	plain, err := gcm.Open(nil, raw[:gcm.NonceSize()], raw[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("secret 2FA tidak dapat dibaca")
+	}
+	return string(plain), nil
+}
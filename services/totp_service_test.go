@@ -0,0 +1,103 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+// kunci uji SHA-1 RFC 6238 (lampiran B); kode 6 digit adalah 6 digit terakhir kode 8 digit di RFC
+var rfc6238Key = []byte("12345678901234567890")
+
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	for _, v := range rfc6238Vectors {
+		if got := totpCode(rfc6238Key, v.unix/totpPeriod); got != v.code {
+			t.Errorf("totpCode(T=%d) = %s, want %s", v.unix, got, v.code)
+		}
+	}
+}
+
+func TestVerifyTOTPRFC6238Vectors(t *testing.T) {
+	secret := totpEncoding.EncodeToString(rfc6238Key)
+
+	for _, v := range rfc6238Vectors {
+		step, ok := VerifyTOTP(secret, v.code, time.Unix(v.unix, 0), 0)
+		if !ok {
+			t.Errorf("VerifyTOTP(T=%d, %s) ditolak", v.unix, v.code)
+			continue
+		}
+		if want := v.unix / totpPeriod; step != want {
+			t.Errorf("VerifyTOTP(T=%d) step = %d, want %d", v.unix, step, want)
+		}
+	}
+}
+
+func TestVerifyTOTPWindow(t *testing.T) {
+	secret := totpEncoding.EncodeToString(rfc6238Key)
+	at := time.Unix(1234567890, 0)
+	current := at.Unix() / totpPeriod
+
+	tests := []struct {
+		name   string
+		offset int64
+		ok     bool
+	}{
+		{"dua langkah sebelum", -2, false},
+		{"satu langkah sebelum", -1, true},
+		{"langkah sekarang", 0, true},
+		{"satu langkah sesudah", 1, true},
+		{"dua langkah sesudah", 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := totpCode(rfc6238Key, current+tt.offset)
+			step, ok := VerifyTOTP(secret, code, at, 0)
+			if ok != tt.ok {
+				t.Fatalf("VerifyTOTP(offset %d) = %v, want %v", tt.offset, ok, tt.ok)
+			}
+			if ok && step != current+tt.offset {
+				t.Fatalf("step = %d, want %d", step, current+tt.offset)
+			}
+		})
+	}
+}
+
+func TestVerifyTOTPRejectsReplayedStep(t *testing.T) {
+	secret := totpEncoding.EncodeToString(rfc6238Key)
+	at := time.Unix(1234567890, 0)
+	code := totpCode(rfc6238Key, at.Unix()/totpPeriod)
+
+	step, ok := VerifyTOTP(secret, code, at, 0)
+	if !ok {
+		t.Fatal("kode pertama ditolak")
+	}
+	if _, ok := VerifyTOTP(secret, code, at, step); ok {
+		t.Fatal("kode yang sama diterima dua kali")
+	}
+}
+
+func TestVerifyTOTPRejectsMalformedCode(t *testing.T) {
+	secret := totpEncoding.EncodeToString(rfc6238Key)
+	at := time.Unix(59, 0)
+
+	for _, code := range []string{"", "28708", "2870820", "abcdef"} {
+		if _, ok := VerifyTOTP(secret, code, at, 0); ok {
+			t.Errorf("VerifyTOTP(%q) diterima", code)
+		}
+	}
+	if _, ok := VerifyTOTP(secret, " 287 082 ", at, 0); !ok {
+		t.Error("kode dengan spasi ditolak")
+	}
+}
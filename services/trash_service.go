@@ -0,0 +1,80 @@
+package services
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+)
+
+// TrashRetention — lama dokumen disimpan di trash sebelum dihapus permanen
+// (env TRASH_RETENTION_DAYS, default 30 hari)
+func TrashRetention() time.Duration {
+	days := 30
+	if v, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS")); err == nil && v > 0 {
+		days = v
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// PurgeExpiredTrash — hapus permanen dokumen di trash yang melewati masa retensi,
+// termasuk file fisik dan seluruh riwayat versinya
+func PurgeExpiredTrash() (int, error) {
+	cutoff := time.Now().Add(-TrashRetention())
+	purged := 0
+
+	var documents []models.Document
+	if err := config.DB.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at <= ?", cutoff).
+		Find(&documents).Error; err != nil {
+		return purged, err
+	}
+
+	for _, d := range documents {
+		if d.PublicID != "" {
+			if err := config.FileStorage.Delete(d.PublicID, d.ResourceType); err != nil {
+				log.Println("❌ Gagal menghapus file dokumen:", err)
+				continue
+			}
+		}
+		DeleteVersionHistory(VersionTypeDocument, d.ID, d.PublicID)
+		DeleteDocumentComments(VersionTypeDocument, d.ID)
+		DeleteDocumentShares(VersionTypeDocument, d.ID)
+
+		if err := config.DB.Unscoped().Delete(&d).Error; err != nil {
+			log.Println("❌ Gagal purge dokumen:", err)
+			continue
+		}
+		purged++
+	}
+
+	var staffDocuments []models.DocumentStaff
+	if err := config.DB.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at <= ?", cutoff).
+		Find(&staffDocuments).Error; err != nil {
+		return purged, err
+	}
+
+	for _, d := range staffDocuments {
+		if d.PublicID != "" {
+			if err := config.FileStorage.Delete(d.PublicID, d.ResourceType); err != nil {
+				log.Println("❌ Gagal menghapus file dokumen staff:", err)
+				continue
+			}
+		}
+		DeleteVersionHistory(VersionTypeDocumentStaff, d.ID, d.PublicID)
+		DeleteDocumentComments(VersionTypeDocumentStaff, d.ID)
+		DeleteDocumentShares(VersionTypeDocumentStaff, d.ID)
+
+		if err := config.DB.Unscoped().Delete(&d).Error; err != nil {
+			log.Println("❌ Gagal purge dokumen staff:", err)
+			continue
+		}
+		purged++
+	}
+
+	return purged, nil
+}
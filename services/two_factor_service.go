@@ -0,0 +1,359 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ChallengeModeVerify = "verify"
+	ChallengeModeEnroll = "enroll"
+
+	twoFactorChallengeTTL   = 5 * time.Minute
+	maxChallengeAttempts    = 5
+	recoveryCodeCount       = 10
+	recoveryCodeGroupLength = 5
+)
+
+var (
+	ErrChallengeInvalid        = errors.New("sesi verifikasi 2FA tidak valid atau sudah kedaluwarsa, silakan login ulang")
+	ErrChallengeTooManyAttempt = errors.New("terlalu banyak percobaan kode 2FA, silakan login ulang")
+	ErrTwoFactorCodeInvalid    = errors.New("kode 2FA salah")
+	ErrTwoFactorAlreadyEnabled = errors.New("2FA sudah aktif untuk akun ini")
+	ErrTwoFactorNotStarted     = errors.New("pendaftaran 2FA belum dimulai")
+	ErrTwoFactorNotEnabled     = errors.New("2FA belum aktif untuk akun ini")
+)
+
+// TwoFactorChallengeTTL — umur tantangan login tahap kedua
+func TwoFactorChallengeTTL() time.Duration {
+	return twoFactorChallengeTTL
+}
+
+// TwoFactorRequired — apakah role diwajibkan memakai 2FA oleh superadmin
+func TwoFactorRequired(role string) bool {
+	var policy models.TwoFactorPolicy
+	if err := config.DB.First(&policy, "role = ?", role).Error; err != nil {
+		return false
+	}
+	return policy.Required
+}
+
+func TwoFactorPolicies() ([]models.TwoFactorPolicy, error) {
+	var policies []models.TwoFactorPolicy
+	err := config.DB.Order("role").Find(&policies).Error
+	return policies, err
+}
+
+func SetTwoFactorPolicy(role string, required bool, updatedByID string) (models.TwoFactorPolicy, error) {
+	policy := models.TwoFactorPolicy{
+		Role:        role,
+		Required:    required,
+		UpdatedByID: &updatedByID,
+	}
+	err := config.DB.Save(&policy).Error
+	return policy, err
+}
+
+// =========================
+// Tantangan login tahap kedua
+// =========================
+
+// CreateTwoFactorChallenge — dibuat setelah password benar, token dikirim ke klien
+// sebagai pengganti access token
+func CreateTwoFactorChallenge(user models.User, mode string) (string, models.TwoFactorChallenge, error) {
+	var challenge models.TwoFactorChallenge
+
+	token, err := randomToken()
+	if err != nil {
+		return "", challenge, err
+	}
+
+	challenge = models.TwoFactorChallenge{
+		TokenHash: HashToken(token),
+		UserID:    user.ID,
+		Mode:      mode,
+		ExpiresAt: time.Now().Add(twoFactorChallengeTTL),
+	}
+
+	if mode == ChallengeModeEnroll {
+		secret, err := GenerateTOTPSecret()
+		if err != nil {
+			return "", challenge, err
+		}
+		if challenge.PendingSecret, err = EncryptTOTPSecret(secret); err != nil {
+			return "", challenge, err
+		}
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ? OR user_id = ?", time.Now(), user.ID).
+			Delete(&models.TwoFactorChallenge{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&challenge).Error
+	})
+
+	return token, challenge, err
+}
+
+// FindTwoFactorChallenge — ambil tantangan yang masih berlaku beserta user-nya
+func FindTwoFactorChallenge(token string) (models.TwoFactorChallenge, error) {
+	var challenge models.TwoFactorChallenge
+	if err := config.DB.Preload("User").
+		Where("token_hash = ?", HashToken(token)).
+		First(&challenge).Error; err != nil {
+		return challenge, ErrChallengeInvalid
+	}
+
+	if time.Now().After(challenge.ExpiresAt) || challenge.User.ID == "" {
+		config.DB.Delete(&challenge)
+		return challenge, ErrChallengeInvalid
+	}
+
+	return challenge, nil
+}
+
+// FailTwoFactorChallenge — catat percobaan gagal, tantangan dihapus setelah batas tercapai
+func FailTwoFactorChallenge(challenge models.TwoFactorChallenge) error {
+	if challenge.Attempts+1 >= maxChallengeAttempts {
+		config.DB.Delete(&challenge)
+		return ErrChallengeTooManyAttempt
+	}
+
+	config.DB.Model(&challenge).UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+	return ErrTwoFactorCodeInvalid
+}
+
+func DeleteTwoFactorChallenge(challenge models.TwoFactorChallenge) {
+	config.DB.Delete(&challenge)
+}
+
+// ChallengeProvisioning — secret & URI untuk tantangan mode enroll
+func ChallengeProvisioning(challenge models.TwoFactorChallenge) (string, string, error) {
+	if challenge.Mode != ChallengeModeEnroll || challenge.PendingSecret == "" {
+		return "", "", ErrTwoFactorNotStarted
+	}
+	secret, err := DecryptTOTPSecret(challenge.PendingSecret)
+	if err != nil {
+		return "", "", err
+	}
+	return secret, TOTPProvisioningURI(secret, challenge.User.Username), nil
+}
+
+// =========================
+// Verifikasi kode
+// =========================
+
+// VerifyUserTOTP — cek kode TOTP user yang sudah aktif 2FA, menolak kode yang sudah dipakai
+func VerifyUserTOTP(user models.User, code string) error {
+	if !user.TOTPEnabled || user.TOTPSecret == "" {
+		return ErrTwoFactorNotEnabled
+	}
+
+	secret, err := DecryptTOTPSecret(user.TOTPSecret)
+	if err != nil {
+		return err
+	}
+
+	step, ok := VerifyTOTP(secret, code, time.Now(), user.TOTPLastStep)
+	if !ok {
+		return ErrTwoFactorCodeInvalid
+	}
+
+	// update bersyarat agar kode yang sama tidak bisa dipakai dua request bersamaan
+	result := config.DB.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		UpdateColumn("totp_last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTwoFactorCodeInvalid
+	}
+	return nil
+}
+
+// UseRecoveryCode — tandai recovery code terpakai (sekali pakai)
+func UseRecoveryCode(user models.User, code string) error {
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return ErrTwoFactorCodeInvalid
+	}
+
+	result := config.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, HashToken(normalized)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTwoFactorCodeInvalid
+	}
+	return nil
+}
+
+// VerifySecondFactor — terima kode TOTP atau recovery code
+func VerifySecondFactor(user models.User, code, recoveryCode string) (usedRecovery bool, err error) {
+	if strings.TrimSpace(recoveryCode) != "" {
+		return true, UseRecoveryCode(user, recoveryCode)
+	}
+	return false, VerifyUserTOTP(user, code)
+}
+
+func RemainingRecoveryCodes(userID string) int64 {
+	var count int64
+	config.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count)
+	return count
+}
+
+// =========================
+// Pendaftaran & reset
+// =========================
+
+// BeginTwoFactorEnrollment — simpan secret baru (belum aktif) untuk user yang login
+func BeginTwoFactorEnrollment(user models.User) (string, string, error) {
+	if user.TOTPEnabled {
+		return "", "", ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+	encrypted, err := EncryptTOTPSecret(secret)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := config.DB.Model(&models.User{}).
+		Where("id = ?", user.ID).
+		UpdateColumn("totp_secret", encrypted).Error; err != nil {
+		return "", "", err
+	}
+
+	return secret, TOTPProvisioningURI(secret, user.Username), nil
+}
+
+// CompleteTwoFactorEnrollment — aktifkan 2FA setelah kode pertama dari authenticator cocok.
+// encryptedSecret kosong = pakai secret yang disimpan BeginTwoFactorEnrollment.
+func CompleteTwoFactorEnrollment(user models.User, encryptedSecret, code string) ([]string, error) {
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if encryptedSecret == "" {
+		encryptedSecret = user.TOTPSecret
+	}
+	if encryptedSecret == "" {
+		return nil, ErrTwoFactorNotStarted
+	}
+
+	secret, err := DecryptTOTPSecret(encryptedSecret)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := VerifyTOTP(secret, code, time.Now(), 0)
+	if !ok {
+		return nil, ErrTwoFactorCodeInvalid
+	}
+
+	var codes []string
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).UpdateColumns(map[string]interface{}{
+			"totp_secret":     encryptedSecret,
+			"totp_enabled":    true,
+			"totp_enabled_at": now,
+			"totp_last_step":  step,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+
+	return codes, err
+}
+
+// RegenerateRecoveryCodes — ganti seluruh recovery code, yang lama tidak berlaku lagi
+func RegenerateRecoveryCodes(user models.User) ([]string, error) {
+	if !user.TOTPEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	var codes []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	return codes, err
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID string) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := randomRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Create(&models.RecoveryCode{
+			UserID:   userID,
+			CodeHash: HashToken(normalizeRecoveryCode(code)),
+		}).Error; err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// ResetTwoFactor — nonaktifkan 2FA user (tindakan superadmin), hapus secret & recovery code
+func ResetTwoFactor(userID string) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).UpdateColumns(map[string]interface{}{
+			"totp_secret":     "",
+			"totp_enabled":    false,
+			"totp_enabled_at": nil,
+			"totp_last_step":  0,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.TwoFactorChallenge{}).Error
+	})
+}
+
+// recovery code berformat xxxxx-xxxxx (huruf kecil & angka tanpa karakter mirip)
+const recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+func randomRecoveryCode() (string, error) {
+	b := make([]byte, recoveryCodeGroupLength*2)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = recoveryAlphabet[int(b[i])%len(recoveryAlphabet)]
+	}
+	return string(b[:recoveryCodeGroupLength]) + "-" + string(b[recoveryCodeGroupLength:]), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+}
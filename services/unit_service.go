@@ -0,0 +1,438 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnitNotFound       = errors.New("unit tidak ditemukan")
+	ErrUnitNameRequired   = errors.New("nama unit wajib diisi")
+	ErrUnitCodeExists     = errors.New("kode unit sudah dipakai")
+	ErrUnitParentNotFound = errors.New("unit induk tidak ditemukan")
+	ErrUnitParentInvalid  = errors.New("unit induk tidak valid: unit tidak boleh menjadi turunan dirinya sendiri")
+	ErrUnitInUse          = errors.New("unit masih memiliki sub-unit, anggota, pegawai atau dokumen")
+	ErrUnitHeadNotFound   = errors.New("kepala unit tidak ditemukan")
+	ErrUnitOutOfScope     = errors.New("unit di luar cakupan akses Anda")
+)
+
+// =========================
+// Hierarki unit
+// =========================
+
+// SubtreeUnitIDs — ID unit beserta seluruh turunannya
+func SubtreeUnitIDs(unitIDs ...string) []string {
+	if len(unitIDs) == 0 {
+		return nil
+	}
+
+	var paths []string
+	config.DB.Model(&models.OrgUnit{}).Where("id IN ?", unitIDs).Pluck("path", &paths)
+	if len(paths) == 0 {
+		return nil
+	}
+
+	query := config.DB.Model(&models.OrgUnit{})
+	for i, path := range paths {
+		if i == 0 {
+			query = query.Where("path LIKE ?", path+"%")
+		} else {
+			query = query.Or("path LIKE ?", path+"%")
+		}
+	}
+
+	var ids []string
+	query.Pluck("id", &ids)
+	return ids
+}
+
+// HeadedUnitIDs — unit yang dikepalai user (tanpa turunan)
+func HeadedUnitIDs(userID string) []string {
+	var ids []string
+	config.DB.Model(&models.OrgUnit{}).Where("head_user_id = ?", userID).Pluck("id", &ids)
+	return ids
+}
+
+// UnitAccess — cakupan unit seorang user: staf melihat unitnya sendiri,
+// kepala unit melihat unit yang dipimpinnya beserta seluruh turunannya
+type UnitAccess struct {
+	All     bool     // izin lintas unit (mis. admin)
+	UserID  string   // dokumen milik sendiri selalu terlihat
+	Visible []string // unit yang materinya boleh dilihat
+
+	// dokumen kepegawaian terlihat (hanya dokumen staf, izin employee.manage)
+	Personnel bool
+}
+
+func unitAccessFor(user models.User, allPermission string) UnitAccess {
+	access := UnitAccess{UserID: user.ID}
+	if HasPermission(user, allPermission) {
+		access.All = true
+		return access
+	}
+
+	access.Visible = SubtreeUnitIDs(HeadedUnitIDs(user.ID)...)
+	if user.UnitID != nil && !containsString(access.Visible, *user.UnitID) {
+		access.Visible = append(access.Visible, *user.UnitID)
+	}
+	return access
+}
+
+// DocumentAccess — cakupan surat (izin lintas unit: document.view_all_units)
+func DocumentAccess(user models.User) UnitAccess {
+	return unitAccessFor(user, PermDocumentViewAll)
+}
+
+// DocumentStaffAccess — cakupan dokumen staf (izin lintas unit: staffdoc.view_all_units)
+func DocumentStaffAccess(user models.User) UnitAccess {
+	access := unitAccessFor(user, PermStaffDocViewAll)
+	access.Personnel = HasPermission(user, PermEmployeeManage)
+	return access
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (a UnitAccess) hasUnit(units []string, unitID *string) bool {
+	return unitID != nil && containsString(units, *unitID)
+}
+
+// CanUseUnit — unit boleh dipilih sebagai pemilik dokumen
+func (a UnitAccess) CanUseUnit(unitID *string) bool {
+	return a.All || unitID == nil || a.hasUnit(a.Visible, unitID)
+}
+
+// ScopeDocuments — batasi kueri surat. Surat tanpa unit berlaku untuk seluruh kantor;
+// surat yang didisposisikan atau dibagikan ke user tetap terlihat walau milik unit lain.
+func (a UnitAccess) ScopeDocuments(query *gorm.DB) *gorm.DB {
+	if a.All {
+		return query
+	}
+
+	dispositions := config.DB.Model(&models.Disposition{}).Select("document_id").Where("to_user_id = ?", a.UserID)
+	shared := sharedDocumentIDs(VersionTypeDocument, a.UserID)
+	if len(a.Visible) == 0 {
+		return query.Where(
+			"documents.unit_id IS NULL OR documents.user_id = ? OR documents.id IN (?) OR documents.id IN (?)",
+			a.UserID, dispositions, shared,
+		)
+	}
+	return query.Where(
+		"documents.unit_id IS NULL OR documents.unit_id IN ? OR documents.user_id = ? OR documents.id IN (?) OR documents.id IN (?)",
+		a.Visible, a.UserID, dispositions, shared,
+	)
+}
+
+// ScopeDocumentStaffs — batasi kueri dokumen staf ke milik sendiri, unit yang terlihat
+// dan dokumen yang dibagikan ke user
+func (a UnitAccess) ScopeDocumentStaffs(query *gorm.DB) *gorm.DB {
+	// dokumen kepegawaian hanya lewat izin employee.manage, tidak lewat cakupan unit
+	if !a.Personnel {
+		query = query.Where("document_staffs.employee_id IS NULL")
+	}
+	if a.All {
+		return query
+	}
+
+	conditions := "document_staffs.user_id = ? OR document_staffs.id IN (?)"
+	args := []interface{}{a.UserID, sharedDocumentIDs(VersionTypeDocumentStaff, a.UserID)}
+	if len(a.Visible) > 0 {
+		conditions += " OR document_staffs.unit_id IN ?"
+		args = append(args, a.Visible)
+	}
+	if a.Personnel {
+		conditions += " OR document_staffs.employee_id IS NOT NULL"
+	}
+	return query.Where(conditions, args...)
+}
+
+// CanViewDocument — cek akses satu surat (lihat ScopeDocuments)
+func (a UnitAccess) CanViewDocument(document models.Document) bool {
+	if a.All || document.UnitID == nil || a.hasUnit(a.Visible, document.UnitID) {
+		return true
+	}
+	if document.UserID != nil && *document.UserID == a.UserID {
+		return true
+	}
+
+	var count int64
+	config.DB.Model(&models.Disposition{}).
+		Where("document_id = ? AND to_user_id = ?", document.ID, a.UserID).
+		Count(&count)
+	return count > 0
+}
+
+// CanViewDocumentStaff — cek akses satu dokumen staf (lihat ScopeDocumentStaffs)
+func (a UnitAccess) CanViewDocumentStaff(document models.DocumentStaff) bool {
+	if document.EmployeeID != "" {
+		return a.Personnel
+	}
+	return a.All || document.UserID == a.UserID || a.hasUnit(a.Visible, document.UnitID)
+}
+
+// ScopeManagedDocumentStaffs — batasi kueri ke dokumen staf yang boleh dikelola user
+func ScopeManagedDocumentStaffs(user models.User, query *gorm.DB) *gorm.DB {
+	personnel := HasPermission(user, PermEmployeeManage)
+	if !personnel {
+		query = query.Where("document_staffs.employee_id IS NULL")
+	}
+	if HasPermission(user, PermStaffDocManageAll) {
+		return query
+	}
+
+	conditions := "document_staffs.user_id = ?"
+	args := []interface{}{user.ID}
+	if managed := SubtreeUnitIDs(HeadedUnitIDs(user.ID)...); len(managed) > 0 {
+		conditions += " OR document_staffs.unit_id IN ?"
+		args = append(args, managed)
+	}
+	if personnel {
+		conditions += " OR document_staffs.employee_id IS NOT NULL"
+	}
+	return query.Where(conditions, args...)
+}
+
+// =========================
+// Kelola unit
+// =========================
+
+// UnitInput — field yang nil tidak diubah; string kosong mengosongkan field opsional
+type UnitInput struct {
+	Name       *string
+	Code       *string
+	ParentID   *string
+	HeadUserID *string
+}
+
+func FindUnit(id string) (models.OrgUnit, error) {
+	var unit models.OrgUnit
+	if err := config.DB.First(&unit, "id = ?", id).Error; err != nil {
+		return unit, ErrUnitNotFound
+	}
+	return unit, nil
+}
+
+func emptyToNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+func checkUnitCode(tx *gorm.DB, code *string, exceptID string) error {
+	if code == nil {
+		return nil
+	}
+	var count int64
+	tx.Model(&models.OrgUnit{}).Where("code = ? AND id <> ?", *code, exceptID).Count(&count)
+	if count > 0 {
+		return ErrUnitCodeExists
+	}
+	return nil
+}
+
+func checkUnitHead(tx *gorm.DB, headUserID *string) error {
+	if headUserID == nil {
+		return nil
+	}
+	var count int64
+	tx.Model(&models.User{}).Where("id = ?", *headUserID).Count(&count)
+	if count == 0 {
+		return ErrUnitHeadNotFound
+	}
+	return nil
+}
+
+// unitPath — path unit baru di bawah parent (nil = unit akar)
+func unitPath(tx *gorm.DB, parentID *string, id string) (string, error) {
+	if parentID == nil {
+		return "/" + id + "/", nil
+	}
+	var parent models.OrgUnit
+	if err := tx.First(&parent, "id = ?", *parentID).Error; err != nil {
+		return "", ErrUnitParentNotFound
+	}
+	return parent.Path + id + "/", nil
+}
+
+func CreateUnit(input UnitInput) (models.OrgUnit, error) {
+	var unit models.OrgUnit
+	if input.Name == nil || strings.TrimSpace(*input.Name) == "" {
+		return unit, ErrUnitNameRequired
+	}
+
+	unit.Name = strings.TrimSpace(*input.Name)
+	unit.Code = emptyToNil(input.Code)
+	unit.ParentID = emptyToNil(input.ParentID)
+	unit.HeadUserID = emptyToNil(input.HeadUserID)
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkUnitCode(tx, unit.Code, ""); err != nil {
+			return err
+		}
+		if err := checkUnitHead(tx, unit.HeadUserID); err != nil {
+			return err
+		}
+		if unit.ParentID != nil {
+			if _, err := unitPath(tx, unit.ParentID, ""); err != nil {
+				return err
+			}
+		}
+
+		// ID baru ada setelah Create, path diisi sesudahnya
+		if err := tx.Create(&unit).Error; err != nil {
+			return err
+		}
+		path, err := unitPath(tx, unit.ParentID, unit.ID)
+		if err != nil {
+			return err
+		}
+		unit.Path = path
+		return tx.Model(&unit).Update("path", path).Error
+	})
+
+	return unit, err
+}
+
+// UpdateUnit — ubah data unit. Memindahkan unit ikut memperbarui path seluruh turunannya.
+func UpdateUnit(id string, input UnitInput) (models.OrgUnit, error) {
+	unit, err := FindUnit(id)
+	if err != nil {
+		return unit, err
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{}
+
+		if input.Name != nil {
+			name := strings.TrimSpace(*input.Name)
+			if name == "" {
+				return ErrUnitNameRequired
+			}
+			unit.Name = name
+			updates["name"] = name
+		}
+		if input.Code != nil {
+			code := emptyToNil(input.Code)
+			if err := checkUnitCode(tx, code, unit.ID); err != nil {
+				return err
+			}
+			unit.Code = code
+			updates["code"] = code
+		}
+		if input.HeadUserID != nil {
+			head := emptyToNil(input.HeadUserID)
+			if err := checkUnitHead(tx, head); err != nil {
+				return err
+			}
+			unit.HeadUserID = head
+			updates["head_user_id"] = head
+		}
+
+		if input.ParentID != nil {
+			parentID := emptyToNil(input.ParentID)
+			newPath, err := unitPath(tx, parentID, unit.ID)
+			if err != nil {
+				return err
+			}
+			// induk baru tidak boleh berada di dalam subtree unit ini
+			parentPath := strings.TrimSuffix(newPath, unit.ID+"/")
+			if parentID != nil && strings.HasPrefix(parentPath, unit.Path) {
+				return ErrUnitParentInvalid
+			}
+
+			if newPath != unit.Path {
+				if err := tx.Model(&models.OrgUnit{}).
+					Where("path LIKE ?", unit.Path+"%").
+					Update("path", gorm.Expr("CONCAT(?, SUBSTRING(path, ?))", newPath, len(unit.Path)+1)).Error; err != nil {
+					return err
+				}
+			}
+			unit.ParentID = parentID
+			unit.Path = newPath
+			updates["parent_id"] = parentID
+		}
+
+		if len(updates) == 0 {
+			return nil
+		}
+		return tx.Model(&unit).Updates(updates).Error
+	})
+
+	return unit, err
+}
+
+// DeleteUnit — hanya unit kosong yang boleh dihapus agar dokumen tidak tiba-tiba
+// berubah menjadi milik seluruh kantor
+func DeleteUnit(id string) error {
+	unit, err := FindUnit(id)
+	if err != nil {
+		return err
+	}
+
+	for _, model := range []interface{}{&models.User{}, &models.Employee{}, &models.Document{}, &models.DocumentStaff{}} {
+		var count int64
+		config.DB.Unscoped().Model(model).Where("unit_id = ?", unit.ID).Count(&count)
+		if count > 0 {
+			return ErrUnitInUse
+		}
+	}
+
+	var children int64
+	config.DB.Model(&models.OrgUnit{}).Where("parent_id = ?", unit.ID).Count(&children)
+	if children > 0 {
+		return ErrUnitInUse
+	}
+
+	return config.DB.Delete(&unit).Error
+}
+
+// AssignUsersToUnit — pindahkan user ke unit (unitID kosong = keluarkan dari unit).
+// Dokumen yang sudah diunggah tetap milik unit lamanya.
+func AssignUsersToUnit(unitID string, userIDs []string) (int64, error) {
+	var target interface{}
+	if unitID != "" {
+		if _, err := FindUnit(unitID); err != nil {
+			return 0, err
+		}
+		target = unitID
+	}
+
+	result := config.DB.Model(&models.User{}).Where("id IN ?", userIDs).Update("unit_id", target)
+	return result.RowsAffected, result.Error
+}
+
+// UnitAudience — user yang dapat melihat materi sebuah unit: anggota unit, kepala unit
+// itu dan unit di atasnya, serta role yang memiliki izin lintas unit
+func UnitAudience(unitID, allPermission string) []string {
+	unit, err := FindUnit(unitID)
+	if err != nil {
+		return nil
+	}
+
+	ancestors := strings.Split(strings.Trim(unit.Path, "/"), "/")
+
+	roles := RolesWithPermission(allPermission)
+
+	var ids []string
+	config.DB.Model(&models.User{}).
+		Where("unit_id = ? OR role IN ? OR id IN (?)", unit.ID, roles,
+			config.DB.Model(&models.OrgUnit{}).Select("head_user_id").Where("id IN ? AND head_user_id IS NOT NULL", ancestors)).
+		Pluck("id", &ids)
+	return ids
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"log"
+	"strconv"
+
+	"dinsos_kuburaya/config"
+	"dinsos_kuburaya/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	VersionTypeDocument      = "document"
+	VersionTypeDocumentStaff = "document_staff"
+)
+
+// VersionFile — data file yang disimpan per versi
+type VersionFile struct {
+	FileName     string
+	FileURL      string
+	PublicID     string
+	ResourceType string
+}
+
+// lockVersionedDocument — kunci baris dokumen induk (SELECT ... FOR UPDATE) sampai transaksi
+// selesai, agar dua perubahan bersamaan tidak menghitung nomor versi yang sama
+func lockVersionedDocument(tx *gorm.DB, docType, docID string) error {
+	table := "documents"
+	if docType == VersionTypeDocumentStaff {
+		table = "document_staffs"
+	}
+
+	var id string
+	return tx.Table(table).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", docID).
+		Scan(&id).Error
+}
+
+func nextVersionNumber(tx *gorm.DB, docType, docID string) (int, error) {
+	var last int
+	err := tx.Model(&models.DocumentVersion{}).
+		Where("document_type = ? AND document_id = ?", docType, docID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&last).Error
+	return last + 1, err
+}
+
+func createVersion(tx *gorm.DB, docType, docID string, file VersionFile, uploaderID *string, note string) (models.DocumentVersion, error) {
+	number, err := nextVersionNumber(tx, docType, docID)
+	if err != nil {
+		return models.DocumentVersion{}, err
+	}
+
+	version := models.DocumentVersion{
+		DocumentType: docType,
+		DocumentID:   docID,
+		Version:      number,
+		FileName:     file.FileName,
+		FileURL:      file.FileURL,
+		PublicID:     file.PublicID,
+		ResourceType: file.ResourceType,
+		UploadedByID: uploaderID,
+		ChangeNote:   note,
+	}
+
+	err = tx.Create(&version).Error
+	return version, err
+}
+
+// RecordNewVersion — catat file baru sebagai versi terbaru. Dokumen lama yang belum
+// punya riwayat otomatis mendapat versi 1 berisi file yang sedang aktif.
+func RecordNewVersion(tx *gorm.DB, docType, docID string, current VersionFile, currentUploaderID *string, next VersionFile, uploaderID string, note string) (models.DocumentVersion, error) {
+	if err := lockVersionedDocument(tx, docType, docID); err != nil {
+		return models.DocumentVersion{}, err
+	}
+
+	var count int64
+	if err := tx.Model(&models.DocumentVersion{}).
+		Where("document_type = ? AND document_id = ?", docType, docID).
+		Count(&count).Error; err != nil {
+		return models.DocumentVersion{}, err
+	}
+
+	if count == 0 && current.PublicID != "" {
+		if _, err := createVersion(tx, docType, docID, current, currentUploaderID, "Versi awal"); err != nil {
+			return models.DocumentVersion{}, err
+		}
+	}
+
+	return createVersion(tx, docType, docID, next, &uploaderID, note)
+}
+
+// RestoreVersion — jadikan file versi lama sebagai versi terbaru (riwayat tetap linear)
+func RestoreVersion(tx *gorm.DB, old models.DocumentVersion, uploaderID string) (models.DocumentVersion, error) {
+	if err := lockVersionedDocument(tx, old.DocumentType, old.DocumentID); err != nil {
+		return models.DocumentVersion{}, err
+	}
+
+	file := VersionFile{
+		FileName:     old.FileName,
+		FileURL:      old.FileURL,
+		PublicID:     old.PublicID,
+		ResourceType: old.ResourceType,
+	}
+	return createVersion(tx, old.DocumentType, old.DocumentID, file, &uploaderID, "Dipulihkan dari versi "+strconv.Itoa(old.Version))
+}
+
+// DeleteVersionHistory — hapus seluruh riwayat versi beserta file fisiknya.
+// File yang sedang aktif (currentPublicID) ikut dihapus oleh pemanggil.
+func DeleteVersionHistory(docType, docID, currentPublicID string) {
+	var versions []models.DocumentVersion
+	config.DB.Where("document_type = ? AND document_id = ?", docType, docID).Find(&versions)
+
+	deleted := map[string]bool{currentPublicID: true}
+	for _, v := range versions {
+		if v.PublicID == "" || deleted[v.PublicID] {
+			continue
+		}
+		deleted[v.PublicID] = true
+		if err := config.FileStorage.Delete(v.PublicID, v.ResourceType); err != nil {
+			log.Println("❌ Gagal menghapus file versi lama:", err)
+		}
+	}
+
+	config.DB.Where("document_type = ? AND document_id = ?", docType, docID).Delete(&models.DocumentVersion{})
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// =========================
+// Penulis XLSX minimal (SpreadsheetML) tanpa dependensi luar —
+// cukup untuk laporan tabel satu sheet dengan gaya sederhana
+// =========================
+
+// Gaya sel, indeks ke cellXfs di styles.xml
+const (
+	xlsxStyleDefault = iota
+	xlsxStyleTitle
+	xlsxStyleSubtitle
+	xlsxStyleHeader
+	xlsxStyleBody
+	xlsxStyleBodyCenter
+)
+
+type xlsxCell struct {
+	Value string
+	Style int
+}
+
+type xlsxSheet struct {
+	Name        string
+	ColumnWidth []float64
+	Rows        [][]xlsxCell
+	RowHeights  map[int]float64 // indeks baris (0-based) → tinggi poin
+	Merges      []string        // mis. "A1:H1"
+	PrintTitle  int             // baris (1-based) yang diulang di setiap halaman cetak, 0 = tidak ada
+	Footer      string          // kode header/footer Excel, mis. "Halaman &P dari &N"
+	Landscape   bool
+}
+
+// xlsxColumn — 0 → A, 25 → Z, 26 → AA
+func xlsxColumn(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+func xlsxEscape(s string) string {
+	var b strings.Builder
+	// karakter kontrol tidak valid di XML 1.0
+	xml.EscapeText(&b, []byte(strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		return r
+	}, s)))
+	return b.String()
+}
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+// urutan cellXfs mengikuti konstanta xlsxStyle*
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<fonts count="3">
+<font><sz val="10"/><name val="Arial"/></font>
+<font><b/><sz val="10"/><name val="Arial"/></font>
+<font><b/><sz val="13"/><name val="Arial"/></font>
+</fonts>
+<fills count="3">
+<fill><patternFill patternType="none"/></fill>
+<fill><patternFill patternType="gray125"/></fill>
+<fill><patternFill patternType="solid"><fgColor rgb="FFD9D9D9"/><bgColor indexed="64"/></patternFill></fill>
+</fills>
+<borders count="2">
+<border><left/><right/><top/><bottom/><diagonal/></border>
+<border><left style="thin"/><right style="thin"/><top style="thin"/><bottom style="thin"/><diagonal/></border>
+</borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="6">
+<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>
+<xf numFmtId="0" fontId="2" fillId="0" borderId="0" xfId="0" applyFont="1" applyAlignment="1"><alignment horizontal="center" vertical="center"/></xf>
+<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1" applyAlignment="1"><alignment horizontal="center" vertical="center"/></xf>
+<xf numFmtId="0" fontId="1" fillId="2" borderId="1" xfId="0" applyFont="1" applyFill="1" applyBorder="1" applyAlignment="1"><alignment horizontal="center" vertical="center" wrapText="1"/></xf>
+<xf numFmtId="0" fontId="0" fillId="0" borderId="1" xfId="0" applyBorder="1" applyAlignment="1"><alignment vertical="top" wrapText="1"/></xf>
+<xf numFmtId="0" fontId="0" fillId="0" borderId="1" xfId="0" applyBorder="1" applyAlignment="1"><alignment horizontal="center" vertical="top" wrapText="1"/></xf>
+</cellXfs>
+<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>
+</styleSheet>`
+
+func (s xlsxSheet) workbookXML() string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="`)
+	b.WriteString(xlsxEscape(s.Name))
+	b.WriteString(`" sheetId="1" r:id="rId1"/></sheets>`)
+	if s.PrintTitle > 0 {
+		fmt.Fprintf(&b, `<definedNames><definedName name="_xlnm.Print_Titles" localSheetId="0">'%s'!$%d:$%d</definedName></definedNames>`,
+			xlsxEscape(strings.ReplaceAll(s.Name, "'", "''")), s.PrintTitle, s.PrintTitle)
+	}
+	b.WriteString(`</workbook>`)
+	return b.String()
+}
+
+func (s xlsxSheet) sheetXML() string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheetPr><pageSetUpPr fitToPage="1"/></sheetPr>`)
+
+	if len(s.ColumnWidth) > 0 {
+		b.WriteString(`<cols>`)
+		for i, width := range s.ColumnWidth {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%s" customWidth="1"/>`, i+1, i+1, strconv.FormatFloat(width, 'f', 2, 64))
+		}
+		b.WriteString(`</cols>`)
+	}
+
+	b.WriteString(`<sheetData>`)
+	for r, row := range s.Rows {
+		if height, ok := s.RowHeights[r]; ok {
+			fmt.Fprintf(&b, `<row r="%d" ht="%s" customHeight="1">`, r+1, strconv.FormatFloat(height, 'f', 1, 64))
+		} else {
+			fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		}
+		for c, cell := range row {
+			ref := xlsxColumn(c) + strconv.Itoa(r+1)
+			if cell.Value == "" {
+				fmt.Fprintf(&b, `<c r="%s" s="%d"/>`, ref, cell.Style)
+				continue
+			}
+			fmt.Fprintf(&b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, cell.Style, xlsxEscape(cell.Value))
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData>`)
+
+	if len(s.Merges) > 0 {
+		fmt.Fprintf(&b, `<mergeCells count="%d">`, len(s.Merges))
+		for _, ref := range s.Merges {
+			fmt.Fprintf(&b, `<mergeCell ref="%s"/>`, ref)
+		}
+		b.WriteString(`</mergeCells>`)
+	}
+
+	b.WriteString(`<pageMargins left="0.5" right="0.5" top="0.6" bottom="0.7" header="0.3" footer="0.3"/>`)
+	orientation := "portrait"
+	if s.Landscape {
+		orientation = "landscape"
+	}
+	fmt.Fprintf(&b, `<pageSetup paperSize="9" orientation="%s" fitToWidth="1" fitToHeight="0"/>`, orientation)
+	if s.Footer != "" {
+		fmt.Fprintf(&b, `<headerFooter><oddFooter>%s</oddFooter></headerFooter>`, xlsxEscape(s.Footer))
+	}
+	b.WriteString(`</worksheet>`)
+	return b.String()
+}
+
+// writeXLSX — tulis workbook satu sheet ke w
+func writeXLSX(w io.Writer, sheet xlsxSheet) error {
+	archive := zip.NewWriter(w)
+
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", sheet.workbookXML()},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+		{"xl/worksheets/sheet1.xml", sheet.sheetXML()},
+	}
+	for _, part := range parts {
+		out, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(out, part.body); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
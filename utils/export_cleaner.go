@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"dinsos_kuburaya/services"
+	"log"
+	"time"
+)
+
+// StartExportCleaner — tandai ekspor yang terputus saat restart, lalu hapus ZIP yang melewati masa simpan
+func StartExportCleaner() {
+	if err := services.PrepareExportJobs(); err != nil {
+		log.Println("❌ Gagal menyiapkan job ekspor:", err)
+	}
+
+	go func() {
+		for {
+			time.Sleep(time.Hour)
+
+			purged, err := services.PurgeExpiredExports()
+			if err != nil {
+				log.Println("❌ Gagal membersihkan file ekspor:", err)
+			} else if purged > 0 {
+				log.Printf("🧹 %d file ekspor kedaluwarsa dihapus\n", purged)
+			}
+		}
+	}()
+}
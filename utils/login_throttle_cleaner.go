@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"dinsos_kuburaya/services"
+	"log"
+	"time"
+)
+
+func StartLoginThrottleCleaner() {
+	go func() {
+		for {
+			time.Sleep(time.Hour)
+
+			purged, err := services.PurgeLoginThrottles()
+			if err != nil {
+				log.Println("❌ Gagal membersihkan hitungan login gagal:", err)
+			} else if purged > 0 {
+				log.Printf("🧹 %d hitungan login gagal yang kedaluwarsa dibersihkan\n", purged)
+			}
+		}
+	}()
+}
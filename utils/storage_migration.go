@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"dinsos_kuburaya/services"
+	"log"
+)
+
+// StartLegacyAssetRestriction — jalankan sekali di latar belakang agar startup tidak
+// tertahan ketika jumlah file lama banyak
+func StartLegacyAssetRestriction() {
+	go func() {
+		restricted, err := services.RestrictLegacyAssets()
+		if err != nil {
+			log.Println("❌ Gagal memindahkan file lama ke penyimpanan privat:", err)
+		}
+		if restricted > 0 {
+			log.Printf("🔒 %d file dokumen lama dipindahkan ke penyimpanan privat\n", restricted)
+		}
+	}()
+}
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"dinsos_kuburaya/services"
+	"log"
+	"time"
+)
+
+// StartSubmissionReminder — cek pengingat permintaan pengumpulan setiap 15 menit
+func StartSubmissionReminder() {
+	go func() {
+		for {
+			sent, err := services.SendSubmissionReminders(time.Now())
+			if err != nil {
+				log.Println("❌ Gagal mengirim pengingat pengumpulan:", err)
+			} else if sent > 0 {
+				log.Printf("⏰ %d pengingat pengumpulan dokumen terkirim\n", sent)
+			}
+
+			time.Sleep(15 * time.Minute)
+		}
+	}()
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"dinsos_kuburaya/services"
+	"log"
+	"time"
+)
+
+// StartTextExtractor — worker latar belakang untuk ekstraksi teks & OCR file yang diunggah
+func StartTextExtractor() {
+	if err := services.PrepareExtractionQueue(); err != nil {
+		log.Println("❌ Gagal menyiapkan antrean ekstraksi teks:", err)
+	}
+
+	for i := 0; i < services.ExtractionWorkers(); i++ {
+		go func() {
+			for {
+				processed, err := services.ProcessNextExtraction()
+				if err != nil {
+					log.Println("❌ Worker ekstraksi teks:", err)
+				}
+				if !processed || err != nil {
+					services.WaitForExtractionWork(time.Minute)
+				}
+			}
+		}()
+	}
+}
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"dinsos_kuburaya/services"
+	"log"
+	"time"
+)
+
+func StartTrashPurger() {
+	go func() {
+		for {
+			time.Sleep(24 * time.Hour)
+
+			purged, err := services.PurgeExpiredTrash()
+			if err != nil {
+				log.Println("❌ Gagal purge trash dokumen:", err)
+			} else {
+				log.Printf("🧹 %d dokumen di trash (>%d hari) dihapus permanen\n", purged, int(services.TrashRetention().Hours()/24))
+			}
+		}
+	}()
+}